@@ -0,0 +1,447 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChangeKind is the kind of a SchemaChange
+type ChangeKind string
+
+const (
+	ChangeCreateTable  ChangeKind = "create_table"  // New table with its indexes
+	ChangeAddColumn    ChangeKind = "add_column"    // New column (destructive when NOT NULL without default)
+	ChangeCreateIndex  ChangeKind = "create_index"  // New index
+	ChangeAlterColumn  ChangeKind = "alter_column"  // Type or nullability change (destructive)
+	ChangeDropColumn   ChangeKind = "drop_column"   // Column not in the model anymore (destructive)
+	ChangeRebuildTable ChangeKind = "rebuild_table" // SQLite table rebuild for changes ALTER TABLE cannot do (destructive)
+)
+
+// AutoMigrateOptions controls AutoMigrateContext
+type AutoMigrateOptions struct {
+	DryRun           bool // Only plan: nothing is executed, the SQL is in the returned plan
+	AllowDestructive bool // Also apply destructive changes, which are otherwise only reported
+}
+
+// SchemaChange is one step of a MigrationPlan
+type SchemaChange struct {
+	Kind        ChangeKind `json:"kind"`
+	Table       string     `json:"table"`
+	Name        string     `json:"name,omitempty"`   // Column or index name
+	Detail      string     `json:"detail,omitempty"` // What differs, e.g. "type TEXT -> BIGINT"
+	Destructive bool       `json:"destructive,omitempty"`
+	Skipped     bool       `json:"skipped,omitempty"` // Destructive change left out because AllowDestructive is false
+	SQL         []string   `json:"sql"`
+}
+
+// MigrationPlan is the ordered list of changes bringing the database to the models
+type MigrationPlan struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// SQL returns the statements of the changes that are applied (or would be, in a dry run), in order
+func (p MigrationPlan) SQL() []string {
+	var sqls []string
+	for _, change := range p.Changes {
+		if !change.Skipped {
+			sqls = append(sqls, change.SQL...)
+		}
+	}
+	return sqls
+}
+
+// Skipped returns the destructive changes that were only reported
+func (p MigrationPlan) Skipped() []SchemaChange {
+	var skipped []SchemaChange
+	for _, change := range p.Changes {
+		if change.Skipped {
+			skipped = append(skipped, change)
+		}
+	}
+	return skipped
+}
+
+// AutoMigrate brings the tables of models up to date with their struct definitions,
+// applying additive changes only. See AutoMigrateContext.
+func AutoMigrate(db Database, models ...TableStruct) (MigrationPlan, error) {
+	return AutoMigrateContext(context.Background(), db, AutoMigrateOptions{}, models...)
+}
+
+// AutoMigrateContext compares every model (see CreateTableSQL for the tag options) with the
+// table DescribeTable reports and plans the changes, in model order, so list referenced
+// tables before the tables referencing them.
+//
+// Additive changes are always applied: new tables, new columns and new indexes.
+// Destructive ones are reported with Skipped set, and applied only with AllowDestructive:
+// column type and nullability changes, columns missing from the model and new NOT NULL
+// columns without a default, which the database cannot fill for existing rows (adding
+// them fails unless the table is empty), along with the indexes using them.
+// SQLite column types are compared by type affinity, so BIGINT and INTEGER or
+// TIMESTAMP and DATETIME are the same type there.
+// Defaults, primary keys and constraints of existing tables are not compared, and
+// indexes missing from the model are left alone.
+//
+// SQLite's ALTER TABLE can only add plain columns, so on rqlite every other change
+// (including a new NOT NULL column without default, or a new UNIQUE column) rebuilds
+// the table: create the new definition under a temporary name, copy the common columns,
+// drop the old table, rename and recreate the model indexes. A rebuild is destructive:
+// triggers and indexes that are not in the model are lost, and with foreign key
+// enforcement on, dropping the old table fires ON DELETE actions of referencing tables.
+// The copy leaves new columns out, so a rebuild adding a NOT NULL column without
+// default (flagged in its Detail) fails, and is rolled back, unless the table is empty.
+//
+// The statements run in a single ExecManySQL call, which is atomic on both backends.
+//
+// Example usage:
+//
+//	plan, err := orm.AutoMigrateContext(ctx, db, orm.AutoMigrateOptions{DryRun: true}, &User{}, &Order{})
+//	for _, sql := range plan.SQL() { fmt.Println(sql) }
+//	for _, change := range plan.Skipped() { log.Printf("not applied: %s %s.%s %s", change.Kind, change.Table, change.Name, change.Detail) }
+func AutoMigrateContext(ctx context.Context, db Database, opts AutoMigrateOptions, models ...TableStruct) (MigrationPlan, error) {
+	var plan MigrationPlan
+	d := db.Dialect()
+	for _, obj := range models {
+		model, err := buildTableModel(d, obj)
+		if err != nil {
+			return plan, err
+		}
+
+		var live TableInfo
+		if dbc, ok := db.(DatabaseContext); ok {
+			live, err = dbc.DescribeTableContext(ctx, model.Info.Name)
+		} else {
+			live, err = db.DescribeTable(model.Info.Name)
+		}
+		if errors.Is(err, ErrTableNotFound) {
+			sqls := []string{model.createTableSQL(d)}
+			for _, index := range model.Info.Indexes {
+				sqls = append(sqls, createIndexSQL(d, model.Info.Name, index))
+			}
+			plan.Changes = append(plan.Changes, SchemaChange{Kind: ChangeCreateTable, Table: model.Info.Name, SQL: sqls})
+			continue
+		}
+		if err != nil {
+			return plan, fmt.Errorf("failed to describe table %s: %w", model.Info.Name, err)
+		}
+
+		changes := diffTable(d, model, live)
+		for i := range changes {
+			changes[i].Skipped = changes[i].Destructive && !opts.AllowDestructive
+		}
+		if opts.AllowDestructive {
+			// a rebuild creates the whole model table, including the additive changes
+			for _, change := range changes {
+				if change.Kind == ChangeRebuildTable {
+					changes = []SchemaChange{change}
+					break
+				}
+			}
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+
+	sqls := plan.SQL()
+	if opts.DryRun || len(sqls) == 0 {
+		return plan, nil
+	}
+	var results []BasicSQLResult
+	var err error
+	if dbc, ok := db.(DatabaseContext); ok {
+		results, err = dbc.ExecManySQLContext(ctx, sqls)
+	} else {
+		results, err = db.ExecManySQL(sqls)
+	}
+	if err == nil {
+		for _, result := range results {
+			if result.Error != nil {
+				err = result.Error
+				break
+			}
+		}
+	}
+	if err != nil {
+		return plan, fmt.Errorf("failed to apply migration plan: %w", err)
+	}
+	return plan, nil
+}
+
+// diffTable plans the changes from the live table to the model
+func diffTable(d Dialect, model *tableModel, live TableInfo) []SchemaChange {
+	table := FormatIdentifier(d, model.Info.Name)
+	sqlite := d.Name() == (SQLiteDialect{}).Name()
+
+	var additive, destructive []SchemaChange
+	var rebuildReasons []string
+	added := make(map[string]bool)   // columns added by the additive changes
+	pending := make(map[string]bool) // columns added by destructive changes
+	for _, column := range model.Info.Columns {
+		current, ok := live.Column(column.Name)
+		if !ok {
+			needsValue := model.needsValue(column)
+			if sqlite && !model.canAddColumn(column) {
+				reason := "add column " + column.Name
+				if needsValue {
+					reason += " (NOT NULL without default)"
+				}
+				rebuildReasons = append(rebuildReasons, reason)
+				continue
+			}
+			change := SchemaChange{
+				Kind:  ChangeAddColumn,
+				Table: model.Info.Name,
+				Name:  column.Name,
+				SQL:   []string{"ALTER TABLE " + table + " ADD COLUMN " + model.columnSQL(d, column)},
+			}
+			if needsValue {
+				pending[strings.ToLower(column.Name)] = true
+				change.Destructive, change.Detail = true, "NOT NULL without default"
+				destructive = append(destructive, change)
+				continue
+			}
+			added[strings.ToLower(column.Name)] = true
+			additive = append(additive, change)
+			continue
+		}
+
+		name := FormatIdentifier(d, column.Name)
+		if !sameColumnType(d, column.Type, current.Type) {
+			detail := "type " + current.Type + " -> " + column.Type
+			if sqlite {
+				rebuildReasons = append(rebuildReasons, column.Name+" "+detail)
+				continue
+			}
+			destructive = append(destructive, SchemaChange{
+				Kind:        ChangeAlterColumn,
+				Destructive: true,
+				Table:       model.Info.Name,
+				Name:        column.Name,
+				Detail:      detail,
+				SQL:         []string{"ALTER TABLE " + table + " ALTER COLUMN " + name + " TYPE " + column.Type + " USING " + name + "::" + column.Type},
+			})
+		}
+		if !column.PrimaryKey && !current.PrimaryKey && column.Nullable != current.Nullable {
+			detail, action := "NOT NULL -> NULL", " DROP NOT NULL"
+			if !column.Nullable {
+				detail, action = "NULL -> NOT NULL", " SET NOT NULL"
+			}
+			if sqlite {
+				rebuildReasons = append(rebuildReasons, column.Name+" "+detail)
+				continue
+			}
+			destructive = append(destructive, SchemaChange{
+				Kind:        ChangeAlterColumn,
+				Destructive: true,
+				Table:       model.Info.Name,
+				Name:        column.Name,
+				Detail:      detail,
+				SQL:         []string{"ALTER TABLE " + table + " ALTER COLUMN " + name + action},
+			})
+		}
+	}
+	for _, column := range live.Columns {
+		if _, ok := model.Info.Column(column.Name); ok {
+			continue
+		}
+		if sqlite {
+			rebuildReasons = append(rebuildReasons, "drop column "+column.Name)
+			continue
+		}
+		destructive = append(destructive, SchemaChange{
+			Kind:        ChangeDropColumn,
+			Destructive: true,
+			Table:       model.Info.Name,
+			Name:        column.Name,
+			SQL:         []string{"ALTER TABLE " + table + " DROP COLUMN " + FormatIdentifier(d, column.Name)},
+		})
+	}
+
+	for _, index := range model.Info.Indexes {
+		if live.hasIndex(index.Name) {
+			continue
+		}
+		change := SchemaChange{
+			Kind:  ChangeCreateIndex,
+			Table: model.Info.Name,
+			Name:  index.Name,
+			SQL:   []string{createIndexSQL(d, model.Info.Name, index)},
+		}
+		switch {
+		case model.indexReady(index, live, added):
+			additive = append(additive, change)
+		case model.indexReady(index, live, pending, added):
+			// follows the destructive ADD COLUMN it needs
+			change.Destructive, change.Detail = true, "uses a NOT NULL column without default"
+			destructive = append(destructive, change)
+		}
+	}
+
+	changes := append(additive, destructive...)
+	if len(rebuildReasons) > 0 {
+		changes = append(changes, model.rebuildChange(d, live, rebuildReasons))
+	}
+	return changes
+}
+
+// needsValue reports whether a new column needs a value for the existing rows:
+// NOT NULL without a default, and not generated by the database
+func (m *tableModel) needsValue(column ColumnInfo) bool {
+	return !column.Nullable && column.Default == nil && column.Name != m.AutoIncrement
+}
+
+// canAddColumn reports whether SQLite's ALTER TABLE ADD COLUMN can add column: no key or
+// UNIQUE constraint, and a NOT NULL column needs a constant default
+func (m *tableModel) canAddColumn(column ColumnInfo) bool {
+	if column.PrimaryKey {
+		return false
+	}
+	for _, unique := range m.Info.UniqueConstraints {
+		for _, c := range unique.Columns {
+			if strings.EqualFold(c, column.Name) {
+				return false
+			}
+		}
+	}
+	if column.Default == nil {
+		return column.Nullable
+	}
+	value := strings.ToUpper(strings.TrimSpace(*column.Default))
+	return !strings.HasPrefix(value, "CURRENT_") && !strings.HasPrefix(value, "(")
+}
+
+// indexReady reports whether every column of index exists in the live table or is
+// added by one of the given changes
+func (m *tableModel) indexReady(index IndexInfo, live TableInfo, added ...map[string]bool) bool {
+	for _, column := range index.Columns {
+		if _, ok := live.Column(column); ok {
+			continue
+		}
+		found := false
+		for _, columns := range added {
+			found = found || columns[strings.ToLower(column)]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasIndex reports whether the table has an index with the given name (case-insensitive)
+func (t TableInfo) hasIndex(name string) bool {
+	for _, index := range t.Indexes {
+		if strings.EqualFold(index.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuildChange renders the SQLite table rebuild: new table under a temporary name,
+// copy of the columns both definitions share, drop, rename and the model indexes
+func (m *tableModel) rebuildChange(d Dialect, live TableInfo, reasons []string) SchemaChange {
+	schema, table := SplitTableName(m.Info.Name)
+	tmpName := d.QuoteIdentifier("_rebuild_" + table)
+	if schema != "" {
+		tmpName = d.QuoteIdentifier(schema) + "." + tmpName
+	}
+	tmp := *m
+	tmp.Info.Name = tmpName
+
+	var common []string
+	for _, column := range m.Info.Columns {
+		if _, ok := live.Column(column.Name); ok {
+			common = append(common, column.Name)
+		}
+	}
+	for _, index := range live.Indexes {
+		if !m.Info.hasIndex(index.Name) {
+			reasons = append(reasons, "drop index "+index.Name)
+		}
+	}
+
+	sqls := []string{tmp.createTableSQL(d)}
+	if len(common) > 0 {
+		columns := formatColumns(d, common)
+		sqls = append(sqls, "INSERT INTO "+tmpName+" ("+columns+") SELECT "+columns+" FROM "+FormatIdentifier(d, m.Info.Name))
+	}
+	sqls = append(sqls,
+		"DROP TABLE "+FormatIdentifier(d, m.Info.Name),
+		"ALTER TABLE "+tmpName+" RENAME TO "+d.QuoteIdentifier(table))
+	for _, index := range m.Info.Indexes {
+		sqls = append(sqls, createIndexSQL(d, m.Info.Name, index))
+	}
+	return SchemaChange{
+		Kind:        ChangeRebuildTable,
+		Table:       m.Info.Name,
+		Detail:      strings.Join(reasons, ", "),
+		Destructive: true,
+		SQL:         sqls,
+	}
+}
+
+// columnTypeAliases maps type names to the names PostgreSQL's format_type reports
+var columnTypeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"int2":        "smallint",
+	"int8":        "bigint",
+	"bool":        "boolean",
+	"float4":      "real",
+	"float8":      "double precision",
+	"decimal":     "numeric",
+	"varchar":     "character varying",
+	"char":        "character",
+	"timestamptz": "timestamp with time zone",
+	"timestamp":   "timestamp without time zone",
+	"timetz":      "time with time zone",
+	"time":        "time without time zone",
+}
+
+// sameColumnType compares a declared column type with the one the database reports.
+// SQLite stores values by type affinity rather than by declared type, so its types
+// are compared by affinity.
+func sameColumnType(d Dialect, declared, reported string) bool {
+	if d.Name() == (SQLiteDialect{}).Name() {
+		return sqliteAffinity(declared) == sqliteAffinity(reported)
+	}
+	return normalizeColumnType(declared) == normalizeColumnType(reported)
+}
+
+// sqliteAffinity returns the type affinity SQLite derives from a declared column type
+// (https://www.sqlite.org/datatype3.html#determination_of_column_affinity)
+func sqliteAffinity(columnType string) string {
+	t := strings.ToUpper(columnType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return "INTEGER"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case strings.Contains(t, "BLOB"), strings.TrimSpace(t) == "":
+		return "BLOB"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// normalizeColumnType makes declared and reported column types comparable:
+// lower case, single spaces, no spaces around the size and PostgreSQL names for aliases
+func normalizeColumnType(columnType string) string {
+	t := strings.ToLower(strings.Join(strings.Fields(columnType), " "))
+	t = strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(t, " (", "("), "( ", "("), " )", ")")
+	t = strings.ReplaceAll(t, ", ", ",")
+	base, size, _ := strings.Cut(t, "(")
+	if alias, ok := columnTypeAliases[base]; ok {
+		base = alias
+	}
+	if size != "" {
+		// NUMERIC(20) is reported with its scale, numeric(20,0)
+		if base == "numeric" && !strings.Contains(size, ",") {
+			size = strings.TrimSuffix(size, ")") + ",0)"
+		}
+		return base + "(" + size
+	}
+	return base
+}
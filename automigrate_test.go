@@ -0,0 +1,236 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type migrateAccount struct {
+	ID      int64   `db:"id,pk"`
+	Email   string  `db:"email,index"`
+	Name    *string `db:"name"`
+	Age     int32   `db:"age"`
+	Country *string `db:"country,index"`
+	Code    string  `db:"code,unique"`
+}
+
+func (a *migrateAccount) TableName() string { return "accounts" }
+
+// liveAccounts is the accounts table before the model gained country and code
+func liveAccounts(idType, nameType, ageType string) TableInfo {
+	return TableInfo{
+		Name: "accounts",
+		Columns: []ColumnInfo{
+			{Name: "id", Type: idType, PrimaryKey: true},
+			{Name: "email", Type: nameType},
+			{Name: "name", Type: nameType},
+			{Name: "age", Type: ageType, Nullable: true},
+			{Name: "legacy", Type: nameType, Nullable: true},
+		},
+		PrimaryKey: []string{"id"},
+		Indexes:    []IndexInfo{{Name: "idx_accounts_email", Columns: []string{"email"}}},
+	}
+}
+
+// TestAutoMigratePostgres checks additive changes are applied and destructive ones reported
+func TestAutoMigratePostgres(t *testing.T) {
+	db := &stubDB{dialect: PostgreSQLDialect{}, tables: map[string]TableInfo{
+		"accounts": liveAccounts("bigint", "text", "bigint"),
+	}}
+
+	plan, err := AutoMigrate(db, &migrateAccount{}, &ddlUser{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantSQL := []string{
+		"ALTER TABLE accounts ADD COLUMN country TEXT",
+		"CREATE INDEX idx_accounts_country ON accounts (country)",
+		"CREATE TABLE users (id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, email TEXT NOT NULL UNIQUE, name TEXT, " +
+			"active BOOLEAN NOT NULL DEFAULT true, score DOUBLE PRECISION NOT NULL, avatar BYTEA NOT NULL, " +
+			"balance DECIMAL(10,2) NOT NULL DEFAULT 0, nickname TEXT, created_at TIMESTAMPTZ NOT NULL)",
+		"CREATE INDEX idx_users_created_at ON users (created_at)",
+	}
+	if !reflect.DeepEqual(plan.SQL(), wantSQL) {
+		t.Errorf("Expected %q, got %q", wantSQL, plan.SQL())
+	}
+	if len(db.execs) != 1 || !reflect.DeepEqual(db.execs[0], wantSQL) {
+		t.Errorf("Expected one ExecManySQL call with the plan, got %q", db.execs)
+	}
+
+	wantSkipped := []SchemaChange{
+		{Kind: ChangeAlterColumn, Table: "accounts", Name: "name", Detail: "NOT NULL -> NULL", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE accounts ALTER COLUMN name DROP NOT NULL"}},
+		{Kind: ChangeAlterColumn, Table: "accounts", Name: "age", Detail: "type bigint -> INTEGER", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE accounts ALTER COLUMN age TYPE INTEGER USING age::INTEGER"}},
+		{Kind: ChangeAlterColumn, Table: "accounts", Name: "age", Detail: "NULL -> NOT NULL", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE accounts ALTER COLUMN age SET NOT NULL"}},
+		{Kind: ChangeAddColumn, Table: "accounts", Name: "code", Detail: "NOT NULL without default", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE accounts ADD COLUMN code TEXT NOT NULL UNIQUE"}},
+		{Kind: ChangeDropColumn, Table: "accounts", Name: "legacy", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE accounts DROP COLUMN legacy"}},
+	}
+	if !reflect.DeepEqual(plan.Skipped(), wantSkipped) {
+		t.Errorf("Expected %+v, got %+v", wantSkipped, plan.Skipped())
+	}
+
+	db.execs = nil
+	plan, err = AutoMigrateContext(context.Background(), db, AutoMigrateOptions{DryRun: true, AllowDestructive: true}, &migrateAccount{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("Expected nothing executed in a dry run, got %q", db.execs)
+	}
+	if len(plan.Skipped()) != 0 || len(plan.SQL()) != 7 || plan.SQL()[2] != "ALTER TABLE accounts ALTER COLUMN name DROP NOT NULL" {
+		t.Errorf("Unexpected plan: %q", plan.SQL())
+	}
+}
+
+// TestAutoMigrateSQLite checks changes SQLite cannot ALTER are planned as a table rebuild
+func TestAutoMigrateSQLite(t *testing.T) {
+	db := &stubDB{dialect: SQLiteDialect{}, tables: map[string]TableInfo{
+		"accounts": liveAccounts("INTEGER", "TEXT", "integer"),
+	}}
+
+	plan, err := AutoMigrateContext(context.Background(), db, AutoMigrateOptions{DryRun: true}, &migrateAccount{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantSQL := []string{
+		"ALTER TABLE accounts ADD COLUMN country TEXT",
+		"CREATE INDEX idx_accounts_country ON accounts (country)",
+	}
+	if !reflect.DeepEqual(plan.SQL(), wantSQL) {
+		t.Errorf("Expected %q, got %q", wantSQL, plan.SQL())
+	}
+
+	skipped := plan.Skipped()
+	if len(skipped) != 1 || skipped[0].Kind != ChangeRebuildTable {
+		t.Fatalf("Expected one skipped rebuild, got %+v", skipped)
+	}
+	if want := "name NOT NULL -> NULL, age NULL -> NOT NULL, add column code (NOT NULL without default), drop column legacy"; skipped[0].Detail != want {
+		t.Errorf("Expected %q, got %q", want, skipped[0].Detail)
+	}
+
+	plan, err = AutoMigrateContext(context.Background(), db, AutoMigrateOptions{AllowDestructive: true}, &migrateAccount{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantSQL = []string{
+		`CREATE TABLE "_rebuild_accounts" (id INTEGER PRIMARY KEY, email TEXT NOT NULL, name TEXT, age INTEGER NOT NULL, ` +
+			`country TEXT, code TEXT NOT NULL UNIQUE)`,
+		`INSERT INTO "_rebuild_accounts" (id, email, name, age) SELECT id, email, name, age FROM accounts`,
+		"DROP TABLE accounts",
+		`ALTER TABLE "_rebuild_accounts" RENAME TO "accounts"`,
+		"CREATE INDEX idx_accounts_email ON accounts (email)",
+		"CREATE INDEX idx_accounts_country ON accounts (country)",
+	}
+	if !reflect.DeepEqual(plan.SQL(), wantSQL) {
+		t.Errorf("Expected %q, got %q", wantSQL, plan.SQL())
+	}
+	if len(db.execs) != 1 || len(plan.Changes) != 1 {
+		t.Errorf("Expected the rebuild alone to be executed, got %q", db.execs)
+	}
+}
+
+type migrateTag struct {
+	ID    int64   `db:"id,pk"`
+	Label *string `db:"label"`
+	Slug  string  `db:"slug,index"`
+	Hits  int     `db:"hits,default=0"`
+}
+
+func (tg *migrateTag) TableName() string { return "tags" }
+
+// TestAutoMigrateNotNullColumn checks a new NOT NULL column without default, and its index,
+// are only added with AllowDestructive
+func TestAutoMigrateNotNullColumn(t *testing.T) {
+	db := &stubDB{dialect: PostgreSQLDialect{}, tables: map[string]TableInfo{
+		"tags": {Name: "tags", Columns: []ColumnInfo{{Name: "id", Type: "bigint", PrimaryKey: true}}, PrimaryKey: []string{"id"}},
+	}}
+
+	plan, err := AutoMigrateContext(context.Background(), db, AutoMigrateOptions{DryRun: true}, &migrateTag{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantSQL := []string{
+		"ALTER TABLE tags ADD COLUMN label TEXT",
+		"ALTER TABLE tags ADD COLUMN hits BIGINT NOT NULL DEFAULT 0",
+	}
+	if !reflect.DeepEqual(plan.SQL(), wantSQL) {
+		t.Errorf("Expected %q, got %q", wantSQL, plan.SQL())
+	}
+	wantSkipped := []SchemaChange{
+		{Kind: ChangeAddColumn, Table: "tags", Name: "slug", Detail: "NOT NULL without default", Destructive: true, Skipped: true,
+			SQL: []string{"ALTER TABLE tags ADD COLUMN slug TEXT NOT NULL"}},
+		{Kind: ChangeCreateIndex, Table: "tags", Name: "idx_tags_slug", Detail: "uses a NOT NULL column without default", Destructive: true, Skipped: true,
+			SQL: []string{"CREATE INDEX idx_tags_slug ON tags (slug)"}},
+	}
+	if !reflect.DeepEqual(plan.Skipped(), wantSkipped) {
+		t.Errorf("Expected %+v, got %+v", wantSkipped, plan.Skipped())
+	}
+
+	plan, err = AutoMigrateContext(context.Background(), db, AutoMigrateOptions{DryRun: true, AllowDestructive: true}, &migrateTag{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantSQL = append(wantSQL, "ALTER TABLE tags ADD COLUMN slug TEXT NOT NULL", "CREATE INDEX idx_tags_slug ON tags (slug)")
+	if !reflect.DeepEqual(plan.SQL(), wantSQL) {
+		t.Errorf("Expected %q, got %q", wantSQL, plan.SQL())
+	}
+}
+
+// TestSameColumnType checks SQLite types are compared by affinity, PostgreSQL types by name
+func TestSameColumnType(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		declared string
+		reported string
+		want     bool
+	}{
+		{SQLiteDialect{}, "INTEGER", "BIGINT", true},
+		{SQLiteDialect{}, "INTEGER", "int8", true},
+		{SQLiteDialect{}, "DATETIME", "TIMESTAMP", true},
+		{SQLiteDialect{}, "TEXT", "VARCHAR(255)", true},
+		{SQLiteDialect{}, "REAL", "DOUBLE PRECISION", true},
+		{SQLiteDialect{}, "BLOB", "", true},
+		{SQLiteDialect{}, "TEXT", "INTEGER", false},
+		{SQLiteDialect{}, "REAL", "DECIMAL(10,2)", false},
+		{PostgreSQLDialect{}, "BIGINT", "integer", false},
+		{PostgreSQLDialect{}, "TIMESTAMPTZ", "timestamp without time zone", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name()+" "+tt.declared+" "+tt.reported, func(t *testing.T) {
+			if got := sameColumnType(tt.dialect, tt.declared, tt.reported); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestNormalizeColumnType checks declared types match the names PostgreSQL reports
+func TestNormalizeColumnType(t *testing.T) {
+	tests := []struct {
+		declared string
+		reported string
+	}{
+		{"BIGINT", "bigint"},
+		{"TIMESTAMPTZ", "timestamp with time zone"},
+		{"DECIMAL(10, 2)", "numeric(10,2)"},
+		{"NUMERIC(20)", "numeric(20,0)"},
+		{"VARCHAR (255)", "character varying(255)"},
+		{"DOUBLE  PRECISION", "double precision"},
+		{"int4", "integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.declared, func(t *testing.T) {
+			if got, want := normalizeColumnType(tt.declared), normalizeColumnType(tt.reported); got != want {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+		})
+	}
+}
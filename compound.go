@@ -0,0 +1,197 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrInvalidSetOperator medaerror.MedaError = medaerror.MedaError{Message: "invalid set operator: must be UNION, UNION ALL, INTERSECT or EXCEPT"}
+	ErrEmptyCompound      medaerror.MedaError = medaerror.MedaError{Message: "compound query requires a Base query and at least one part"}
+	ErrCompoundConflict   medaerror.MedaError = medaerror.MedaError{Message: "a ComplexQuery with Compound may only set CTEs, use the CompoundQuery ORDER BY, LIMIT and OFFSET"}
+)
+
+// SetOperator combines the result sets of two queries
+type SetOperator string
+
+const (
+	Union     SetOperator = "UNION"
+	UnionAll  SetOperator = "UNION ALL"
+	Intersect SetOperator = "INTERSECT"
+	Except    SetOperator = "EXCEPT"
+)
+
+// CompoundPart is a query appended to a CompoundQuery with a set operator
+type CompoundPart struct {
+	Operator SetOperator   `json:"operator"` // UNION, UNION ALL, INTERSECT or EXCEPT
+	Query    *ComplexQuery `json:"query"`    // Query to combine with the result so far
+}
+
+// CompoundQuery combines several queries with UNION, UNION ALL, INTERSECT and EXCEPT.
+// Parts are applied left to right in both databases (PostgreSQL would otherwise bind
+// INTERSECT tighter than UNION and EXCEPT). OrderBy and OrderTerms apply to the combined
+// result, so they must use output column names, not table-qualified columns.
+//
+// Member queries with their own CTEs, ORDER BY, LIMIT or OFFSET, or with a Compound of
+// their own, are wrapped in a derived table (SQLite does not allow them in a compound member).
+//
+// Use it through ComplexQuery.Compound, so it works everywhere a ComplexQuery does:
+// SelectManyComplex, subquery conditions and CTE bodies (including recursive CTEs).
+//
+// Example usage:
+//
+//	query := &ComplexQuery{Compound: &CompoundQuery{
+//	    Base:       &ComplexQuery{Select: []string{"email"}, From: "customers"},
+//	    Parts:      []CompoundPart{{Operator: Union, Query: &ComplexQuery{Select: []string{"email"}, From: "subscribers"}}},
+//	    OrderTerms: []OrderTerm{{Field: "email"}},
+//	    Limit:      100,
+//	}}
+//	// SELECT email FROM customers UNION SELECT email FROM subscribers ORDER BY email ASC LIMIT 100
+type CompoundQuery struct {
+	Base       *ComplexQuery  `json:"base"`                  // First query (required)
+	Parts      []CompoundPart `json:"parts"`                 // Queries combined with Base, in order (at least one)
+	OrderBy    []string       `json:"order_by,omitempty"`    // ORDER BY of the combined result (raw SQL)
+	OrderTerms []OrderTerm    `json:"order_terms,omitempty"` // Validated ORDER BY entries, appended after OrderBy
+	Limit      int            `json:"limit,omitempty"`       // LIMIT of the combined result
+	Offset     int            `json:"offset,omitempty"`      // OFFSET of the combined result
+}
+
+// Combine returns a ComplexQuery combining queries with the same set operator,
+// for example Combine(UnionAll, q1, q2, q3). Set ORDER BY or LIMIT on the returned
+// query's Compound.
+func Combine(op SetOperator, queries ...*ComplexQuery) *ComplexQuery {
+	compound := &CompoundQuery{}
+	for i, query := range queries {
+		if i == 0 {
+			compound.Base = query
+			continue
+		}
+		compound.Parts = append(compound.Parts, CompoundPart{Operator: op, Query: query})
+	}
+	return &ComplexQuery{Compound: compound}
+}
+
+// ValidateSetOperator validates a set operator (case-insensitive)
+//
+// Returns: error if validation fails, nil otherwise
+func ValidateSetOperator(op SetOperator) error {
+	switch SetOperator(strings.ToUpper(strings.TrimSpace(string(op)))) {
+	case Union, UnionAll, Intersect, Except:
+		return nil
+	}
+	return ErrInvalidSetOperator
+}
+
+// writeCompoundSQL renders a ComplexQuery whose Compound is set: its CTEs followed by the compound
+func (cq *ComplexQuery) writeCompoundSQL(b *sqlBuilder, correlated bool) (string, error) {
+	if cq.hasSelectParts() {
+		return "", ErrCompoundConflict
+	}
+
+	withClause, err := cq.writeWith(b)
+	if err != nil {
+		return "", err
+	}
+	body, err := cq.Compound.writeSQL(b, correlated)
+	if err != nil {
+		return "", err
+	}
+	if withClause == "" {
+		return body, nil
+	}
+	return withClause + " " + body, nil
+}
+
+// hasSelectParts reports whether any field besides the CTEs and Compound is set
+func (cq *ComplexQuery) hasSelectParts() bool {
+	return len(cq.Select) > 0 || cq.Distinct || cq.From != "" || cq.FromAlias != "" ||
+		len(cq.Joins) > 0 || cq.Where != nil || len(cq.GroupBy) > 0 || cq.Having != "" ||
+		len(cq.OrderBy) > 0 || len(cq.SelectExprs) > 0 || cq.HavingCondition != nil ||
+		cq.HavingExpr != nil || len(cq.OrderByExprs) > 0 || len(cq.SelectTerms) > 0 ||
+		len(cq.GroupByColumns) > 0 || len(cq.OrderTerms) > 0 || len(cq.WindowTerms) > 0 ||
+		len(cq.Windows) > 0 || cq.Limit != 0 || cq.Offset != 0
+}
+
+// needsWrapping reports whether a compound member must be wrapped in a derived table
+func (cq *ComplexQuery) needsWrapping() bool {
+	return len(cq.CTEs) > 0 || cq.CTERaw != "" || cq.Compound != nil ||
+		len(cq.OrderBy) > 0 || len(cq.OrderTerms) > 0 || len(cq.OrderByExprs) > 0 ||
+		cq.Limit != 0 || cq.Offset != 0
+}
+
+// writeSQL renders the compound, binding values of every member in statement order.
+// A correlated compound (subquery in a condition) passes the enclosing scope to its members.
+func (cq *CompoundQuery) writeSQL(b *sqlBuilder, correlated bool) (string, error) {
+	if cq.Base == nil || len(cq.Parts) == 0 {
+		return "", ErrEmptyCompound
+	}
+
+	wrapped := 0
+	member := func(query *ComplexQuery) (string, error) {
+		if query == nil {
+			return "", ErrEmptyCompound
+		}
+		sql, err := query.writeSQL(b, correlated)
+		if err != nil {
+			return "", fmt.Errorf("failed to build compound member: %w", err)
+		}
+		if query.needsWrapping() {
+			wrapped++
+			sql = fmt.Sprintf("SELECT * FROM (%s) AS _compound%d", sql, wrapped)
+		}
+		return sql, nil
+	}
+
+	sql, err := member(cq.Base)
+	if err != nil {
+		return "", err
+	}
+	onlyIntersect := true
+	for _, part := range cq.Parts {
+		if err := ValidateSetOperator(part.Operator); err != nil {
+			return "", err
+		}
+		op := SetOperator(strings.ToUpper(strings.TrimSpace(string(part.Operator))))
+		if op == Intersect && !onlyIntersect {
+			// keep left to right evaluation on PostgreSQL, where INTERSECT binds tighter
+			wrapped++
+			sql = fmt.Sprintf("SELECT * FROM (%s) AS _compound%d", sql, wrapped)
+		}
+		if op != Intersect {
+			onlyIntersect = false
+		}
+		partSQL, err := member(part.Query)
+		if err != nil {
+			return "", err
+		}
+		sql += " " + string(op) + " " + partSQL
+	}
+
+	// ORDER BY applies to the combined result, which has no table qualifiers
+	outerQualifiers, outerAggregates := b.qualifiers, b.aggregates
+	b.qualifiers, b.aggregates = map[string]bool{}, false
+	orderFields, err := b.writeOrderBy(cq.OrderBy)
+	if err == nil {
+		var orderTerms []string
+		orderTerms, err = b.writeOrderTerms(cq.OrderTerms)
+		orderFields = append(orderFields, orderTerms...)
+	}
+	b.qualifiers, b.aggregates = outerQualifiers, outerAggregates
+	if err != nil {
+		return "", fmt.Errorf("failed to build ORDER BY: %w", err)
+	}
+	if len(orderFields) > 0 {
+		sql += " ORDER BY " + strings.Join(orderFields, ", ")
+	}
+
+	limit := cq.Limit
+	if cq.Offset > 0 && limit < 1 {
+		limit = DEFAULT_PAGINATION_LIMIT
+	}
+	if limitClause := b.dialect.LimitOffset(limit, cq.Offset); limitClause != "" {
+		sql += " " + limitClause
+	}
+	return sql, nil
+}
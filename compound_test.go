@@ -0,0 +1,200 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestCompoundQuery checks set operations for both dialects
+func TestCompoundQuery(t *testing.T) {
+	customers := &ComplexQuery{
+		Select: []string{"email"},
+		From:   "customers",
+		Where:  &Condition{Field: "status", Operator: "=", Value: "active"},
+	}
+	subscribers := &ComplexQuery{
+		Select: []string{"email"},
+		From:   "subscribers",
+		Where:  &Condition{Field: "confirmed", Operator: "=", Value: true},
+	}
+	unsubscribed := &ComplexQuery{Select: []string{"email"}, From: "unsubscribed"}
+
+	tests := []struct {
+		name         string
+		query        *ComplexQuery
+		wantSQLite   string
+		wantPostgres string
+		wantValues   []interface{}
+	}{
+		{
+			name: "union with outer order and limit",
+			query: &ComplexQuery{Compound: &CompoundQuery{
+				Base:       customers,
+				Parts:      []CompoundPart{{Operator: Union, Query: subscribers}},
+				OrderTerms: []OrderTerm{{Field: "email", Desc: true}},
+				Limit:      10,
+				Offset:     20,
+			}},
+			wantSQLite: "SELECT email FROM customers WHERE status = ? UNION SELECT email FROM subscribers WHERE confirmed = ? " +
+				"ORDER BY email DESC LIMIT 10 OFFSET 20",
+			wantPostgres: "SELECT email FROM customers WHERE status = $1 UNION SELECT email FROM subscribers WHERE confirmed = $2 " +
+				"ORDER BY email DESC LIMIT 10 OFFSET 20",
+			wantValues: []interface{}{"active", true},
+		},
+		{
+			name:         "combine union all",
+			query:        Combine(UnionAll, customers, subscribers, unsubscribed),
+			wantSQLite:   "SELECT email FROM customers WHERE status = ? UNION ALL SELECT email FROM subscribers WHERE confirmed = ? UNION ALL SELECT email FROM unsubscribed",
+			wantPostgres: "SELECT email FROM customers WHERE status = $1 UNION ALL SELECT email FROM subscribers WHERE confirmed = $2 UNION ALL SELECT email FROM unsubscribed",
+			wantValues:   []interface{}{"active", true},
+		},
+		{
+			name: "intersect after except is evaluated left to right",
+			query: &ComplexQuery{Compound: &CompoundQuery{
+				Base: customers,
+				Parts: []CompoundPart{
+					{Operator: "except", Query: unsubscribed},
+					{Operator: Intersect, Query: subscribers},
+				},
+			}},
+			wantSQLite: "SELECT * FROM (SELECT email FROM customers WHERE status = ? EXCEPT SELECT email FROM unsubscribed) AS _compound1 " +
+				"INTERSECT SELECT email FROM subscribers WHERE confirmed = ?",
+			wantPostgres: "SELECT * FROM (SELECT email FROM customers WHERE status = $1 EXCEPT SELECT email FROM unsubscribed) AS _compound1 " +
+				"INTERSECT SELECT email FROM subscribers WHERE confirmed = $2",
+			wantValues: []interface{}{"active", true},
+		},
+		{
+			name: "ordered member is wrapped",
+			query: Combine(Union,
+				&ComplexQuery{Select: []string{"email"}, From: "customers", OrderBy: []string{"created_at DESC"}, Limit: 5},
+				unsubscribed,
+			),
+			wantSQLite:   "SELECT * FROM (SELECT email FROM customers ORDER BY created_at DESC LIMIT 5) AS _compound1 UNION SELECT email FROM unsubscribed",
+			wantPostgres: "SELECT * FROM (SELECT email FROM customers ORDER BY created_at DESC LIMIT 5) AS _compound1 UNION SELECT email FROM unsubscribed",
+		},
+		{
+			name: "recursive CTE body",
+			query: &ComplexQuery{
+				CTEs: []CommonTableExpression{{
+					Name:      "org_hierarchy",
+					Recursive: true,
+					Query: Combine(UnionAll,
+						&ComplexQuery{
+							Select: []string{"id", "manager_id", "1 AS level"},
+							From:   "employees",
+							Where:  &Condition{Field: "manager_id", Operator: "IS NULL"},
+						},
+						&ComplexQuery{
+							Select:    []string{"e.id", "e.manager_id", "oh.level + 1"},
+							From:      "employees",
+							FromAlias: "e",
+							Joins:     []Join{{Type: InnerJoin, Table: "org_hierarchy", Alias: "oh", Condition: "e.manager_id = oh.id"}},
+							Where:     &Condition{Field: "oh.level", Operator: "<", Value: 10},
+						},
+					),
+				}},
+				Select: []string{"id", "level"},
+				From:   "org_hierarchy",
+			},
+			wantSQLite: "WITH RECURSIVE org_hierarchy AS (SELECT id, manager_id, 1 AS level FROM employees WHERE manager_id IS NULL " +
+				"UNION ALL SELECT e.id, e.manager_id, oh.level + 1 FROM employees AS e INNER JOIN org_hierarchy AS oh ON e.manager_id = oh.id " +
+				"WHERE oh.level < ?) SELECT id, level FROM org_hierarchy",
+			wantPostgres: "WITH RECURSIVE org_hierarchy AS (SELECT id, manager_id, 1 AS level FROM employees WHERE manager_id IS NULL " +
+				"UNION ALL SELECT e.id, e.manager_id, oh.level + 1 FROM employees AS e INNER JOIN org_hierarchy AS oh ON e.manager_id = oh.id " +
+				"WHERE oh.level < $1) SELECT id, level FROM org_hierarchy",
+			wantValues: []interface{}{10},
+		},
+		{
+			name: "compound subquery condition",
+			query: &ComplexQuery{
+				From: "users",
+				Where: &Condition{Field: "users.email", Operator: "IN", Subquery: Combine(Union,
+					&ComplexQuery{Select: []string{"email"}, From: "customers"},
+					&ComplexQuery{Select: []string{"email"}, From: "subscribers", Where: &Condition{Field: "subscribers.id", Operator: ">", Value: 7}},
+				)},
+			},
+			wantSQLite:   "SELECT * FROM users WHERE users.email IN (SELECT email FROM customers UNION SELECT email FROM subscribers WHERE subscribers.id > ?)",
+			wantPostgres: "SELECT * FROM users WHERE users.email IN (SELECT email FROM customers UNION SELECT email FROM subscribers WHERE subscribers.id > $1)",
+			wantValues:   []interface{}{7},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, d := range []Dialect{SQLiteDialect{}, PostgreSQLDialect{}} {
+			t.Run(tt.name+"/"+d.Name(), func(t *testing.T) {
+				got, values, err := tt.query.ToSQLDialect(d)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				want := tt.wantSQLite
+				if d.Name() == "postgresql" {
+					want = tt.wantPostgres
+				}
+				if got != want {
+					t.Errorf("Expected %q, got %q", want, got)
+				}
+				if len(values) == 0 && len(tt.wantValues) == 0 {
+					return
+				}
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+				}
+			})
+		}
+	}
+}
+
+// TestCompoundQueryErrors checks invalid compounds are rejected
+func TestCompoundQueryErrors(t *testing.T) {
+	users := &ComplexQuery{Select: []string{"id"}, From: "users"}
+
+	tests := []struct {
+		name    string
+		query   *ComplexQuery
+		wantErr error
+	}{
+		{
+			name:    "single query",
+			query:   Combine(Union, users),
+			wantErr: ErrEmptyCompound,
+		},
+		{
+			name:    "nil part",
+			query:   Combine(Union, users, nil),
+			wantErr: ErrEmptyCompound,
+		},
+		{
+			name:    "invalid operator",
+			query:   &ComplexQuery{Compound: &CompoundQuery{Base: users, Parts: []CompoundPart{{Operator: "UNION; DROP TABLE users", Query: users}}}},
+			wantErr: ErrInvalidSetOperator,
+		},
+		{
+			name:    "select fields next to compound",
+			query:   &ComplexQuery{From: "users", Compound: &CompoundQuery{Base: users, Parts: []CompoundPart{{Operator: Union, Query: users}}}},
+			wantErr: ErrCompoundConflict,
+		},
+		{
+			name: "qualified outer order",
+			query: &ComplexQuery{Compound: &CompoundQuery{
+				Base:       users,
+				Parts:      []CompoundPart{{Operator: Union, Query: users}},
+				OrderTerms: []OrderTerm{{Field: "users.id"}},
+			}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "invalid member",
+			query:   Combine(Union, users, &ComplexQuery{From: "users; DROP TABLE users"}),
+			wantErr: ErrInvalidTableName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.query.ToSQL(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrNoColumns             medaerror.MedaError = medaerror.MedaError{Message: "table struct has no columns: tag its exported fields with db or json"}
+	ErrUnsupportedColumnType medaerror.MedaError = medaerror.MedaError{Message: "no column type for the Go type: set one with the type= tag option"}
+	ErrInvalidTagOption      medaerror.MedaError = medaerror.MedaError{Message: "invalid db tag option"}
+)
+
+// referencesOption matches the references= tag option: table or table(column)
+var referencesOption = regexp.MustCompile(`^\s*([^()\s]+)\s*(?:\(\s*([^()\s]+)\s*\))?\s*$`)
+
+// allowedReferentialActions are the values of the ondelete= and onupdate= tag options
+var allowedReferentialActions = map[string]bool{
+	"NO ACTION":   true,
+	"RESTRICT":    true,
+	"CASCADE":     true,
+	"SET NULL":    true,
+	"SET DEFAULT": true,
+}
+
+// tableModel is the table a TableStruct describes, in the same shape DescribeTable reports
+type tableModel struct {
+	Info          TableInfo
+	AutoIncrement string // auto-incrementing primary key column, empty if none
+}
+
+// CreateTableSQL renders the CREATE TABLE statement of a TableStruct, followed by one
+// CREATE INDEX statement per index, so they can be run with ExecManySQL.
+//
+// Columns are the fields mapped by the db (or json) tag, in declaration order. Their type
+// comes from Dialect.ColumnType: bool, integers, floats, string, time.Time and []byte, also
+// named types based on them. Pointers and database/sql Null types (sql.NullString,
+// sql.Null[T], ...) are nullable, every other column is NOT NULL.
+//
+// Tag options (after the column name, comma separated):
+//
+//	pk                    part of the primary key (PrimaryKeyer takes precedence)
+//	autoincrement         auto-incrementing integer primary key (single pk column only)
+//	notnull               NOT NULL, for pointer fields
+//	unique                UNIQUE column; unique=name groups columns into one named UNIQUE constraint
+//	default=<sql>         DEFAULT, copied as SQL: default=0, default='new', default=CURRENT_TIMESTAMP
+//	type=<sql type>       column type override, e.g. type=DECIMAL(10,2) or type=JSONB
+//	index                 index named idx_<table>_<column>; index=name groups columns into one index
+//	references=table(col) foreign key, references=table uses the referenced primary key
+//	ondelete=, onupdate=  referential actions of the foreign key: cascade, restrict, set null, ...
+//
+// Example usage:
+//
+//	type Order struct {
+//	    ID        int64     `db:"id,pk,autoincrement"`
+//	    UserID    int64     `db:"user_id,references=users(id),ondelete=cascade,index"`
+//	    Status    string    `db:"status,default='new'"`
+//	    Note      *string   `db:"note"`
+//	    CreatedAt time.Time `db:"created_at,default=CURRENT_TIMESTAMP"`
+//	}
+//
+//	sqls, err := orm.CreateTableSQL(db.Dialect(), &Order{})
+//	// CREATE TABLE orders (id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, user_id BIGINT NOT NULL
+//	//   REFERENCES users (id) ON DELETE CASCADE, status TEXT NOT NULL DEFAULT 'new', note TEXT,
+//	//   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP)
+//	// CREATE INDEX idx_orders_user_id ON orders (user_id)
+func CreateTableSQL(d Dialect, obj TableStruct) ([]string, error) {
+	if d == nil {
+		d = defaultDialect
+	}
+	model, err := buildTableModel(d, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	sqls := []string{model.createTableSQL(d)}
+	for _, index := range model.Info.Indexes {
+		sqls = append(sqls, createIndexSQL(d, model.Info.Name, index))
+	}
+	return sqls, nil
+}
+
+// buildTableModel reads the columns and constraints of a TableStruct from its tags
+func buildTableModel(d Dialect, obj TableStruct) (*tableModel, error) {
+	if obj == nil {
+		return nil, ErrNoColumns
+	}
+	tableName := obj.TableName()
+	if err := ValidateTableName(tableName); err != nil {
+		return nil, err
+	}
+	info := getStructInfo(reflect.TypeOf(obj))
+	if len(info.Fields) == 0 {
+		return nil, ErrNoColumns
+	}
+
+	model := &tableModel{Info: TableInfo{Name: tableName}}
+	primaryKey, err := PrimaryKeyColumns(obj)
+	if err != nil && !errors.Is(err, ErrNoPrimaryKey) {
+		return nil, err
+	}
+	for _, column := range primaryKey {
+		field, ok := info.FieldByColumn(column)
+		if !ok {
+			return nil, fmt.Errorf("primary key column %s has no matching struct field", column)
+		}
+		model.Info.PrimaryKey = append(model.Info.PrimaryKey, field.Column)
+	}
+
+	_, table := SplitTableName(tableName)
+	indexes := make(map[string]int)
+	uniques := make(map[string]int)
+	for _, f := range info.Fields {
+		if err := ValidateAlias(f.Column); err != nil {
+			return nil, fmt.Errorf("%w: field %s", err, f.Name)
+		}
+		column := ColumnInfo{Name: f.Column}
+		for _, pk := range model.Info.PrimaryKey {
+			column.PrimaryKey = column.PrimaryKey || pk == f.Column
+		}
+
+		goType, nullable := unwrapNullable(f.Type)
+		column.Type = f.Option("type")
+		if column.Type == "" {
+			column.Type = d.ColumnType(goType)
+		}
+		if column.Type == "" {
+			return nil, fmt.Errorf("%w: field %s of type %s", ErrUnsupportedColumnType, f.Name, f.Type)
+		}
+		column.Nullable = nullable && !column.PrimaryKey && !f.HasOption("notnull")
+		if f.HasOption("default") {
+			value := f.Option("default")
+			column.Default = &value
+		}
+
+		if f.HasOption("autoincrement") {
+			if !column.PrimaryKey || len(model.Info.PrimaryKey) != 1 || !isIntegerKind(goType.Kind()) {
+				return nil, fmt.Errorf("%w: field %s autoincrement needs a single integer primary key", ErrInvalidTagOption, f.Name)
+			}
+			model.AutoIncrement = f.Column
+		}
+
+		// fields sharing a unique= or index= name form one multi-column constraint or index
+		if f.HasOption("unique") {
+			name := f.Option("unique")
+			pos, ok := uniques[strings.ToLower(name)]
+			switch {
+			case name != "" && ValidateAlias(name) != nil:
+				return nil, fmt.Errorf("%w: field %s unique=%s", ErrInvalidTagOption, f.Name, name)
+			case name != "" && ok:
+				model.Info.UniqueConstraints[pos].Columns = append(model.Info.UniqueConstraints[pos].Columns, f.Column)
+			default:
+				if name != "" {
+					uniques[strings.ToLower(name)] = len(model.Info.UniqueConstraints)
+				}
+				model.Info.UniqueConstraints = append(model.Info.UniqueConstraints, UniqueConstraint{Name: name, Columns: []string{f.Column}})
+			}
+		}
+
+		if f.HasOption("index") {
+			name := f.Option("index")
+			if name == "" {
+				name = "idx_" + table + "_" + f.Column
+			}
+			pos, ok := indexes[strings.ToLower(name)]
+			switch {
+			case ValidateAlias(name) != nil:
+				return nil, fmt.Errorf("%w: field %s index=%s", ErrInvalidTagOption, f.Name, name)
+			case ok:
+				model.Info.Indexes[pos].Columns = append(model.Info.Indexes[pos].Columns, f.Column)
+			default:
+				indexes[strings.ToLower(name)] = len(model.Info.Indexes)
+				model.Info.Indexes = append(model.Info.Indexes, IndexInfo{Name: name, Columns: []string{f.Column}})
+			}
+		}
+
+		if f.HasOption("references") {
+			fk, err := foreignKeyOption(f)
+			if err != nil {
+				return nil, err
+			}
+			model.Info.ForeignKeys = append(model.Info.ForeignKeys, fk)
+		} else if f.HasOption("ondelete") || f.HasOption("onupdate") {
+			return nil, fmt.Errorf("%w: field %s ondelete/onupdate need references", ErrInvalidTagOption, f.Name)
+		}
+
+		model.Info.Columns = append(model.Info.Columns, column)
+	}
+	return model, nil
+}
+
+// foreignKeyOption parses the references=, ondelete= and onupdate= options of a field
+func foreignKeyOption(f *structField) (ForeignKeyInfo, error) {
+	match := referencesOption.FindStringSubmatch(f.Option("references"))
+	if match == nil || ValidateTableName(match[1]) != nil || (match[2] != "" && ValidateAlias(match[2]) != nil) {
+		return ForeignKeyInfo{}, fmt.Errorf("%w: field %s references=%s", ErrInvalidTagOption, f.Name, f.Option("references"))
+	}
+	fk := ForeignKeyInfo{Columns: []string{f.Column}, RefTable: match[1]}
+	if match[2] != "" {
+		fk.RefColumns = []string{match[2]}
+	}
+	for option, action := range map[string]*string{"ondelete": &fk.OnDelete, "onupdate": &fk.OnUpdate} {
+		if !f.HasOption(option) {
+			continue
+		}
+		value := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(f.Option(option), "_", " ")), " "))
+		if !allowedReferentialActions[value] {
+			return ForeignKeyInfo{}, fmt.Errorf("%w: field %s %s=%s", ErrInvalidTagOption, f.Name, option, f.Option(option))
+		}
+		*action = value
+	}
+	return fk, nil
+}
+
+// unwrapNullable returns the value type of pointers and database/sql Null types,
+// and whether the column may be NULL
+func unwrapNullable(t reflect.Type) (reflect.Type, bool) {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+	// sql.NullString{String, Valid}, sql.Null[T]{V, Valid}, ...
+	if t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && t.NumField() == 2 && t.Field(1).Name == "Valid" {
+		return t.Field(0).Type, true
+	}
+	return t, nullable
+}
+
+// isIntegerKind reports whether k is a signed or unsigned integer kind
+func isIntegerKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uint64
+}
+
+// createTableSQL renders the CREATE TABLE statement, without the indexes
+func (m *tableModel) createTableSQL(d Dialect) string {
+	definitions := make([]string, 0, len(m.Info.Columns)+2)
+	for _, column := range m.Info.Columns {
+		definitions = append(definitions, m.columnSQL(d, column))
+	}
+	if len(m.Info.PrimaryKey) > 1 {
+		definitions = append(definitions, "PRIMARY KEY ("+formatColumns(d, m.Info.PrimaryKey)+")")
+	}
+	for _, unique := range m.Info.UniqueConstraints {
+		if unique.Name != "" {
+			definitions = append(definitions, "CONSTRAINT "+FormatIdentifier(d, unique.Name)+" UNIQUE ("+formatColumns(d, unique.Columns)+")")
+		}
+	}
+	return "CREATE TABLE " + FormatIdentifier(d, m.Info.Name) + " (" + strings.Join(definitions, ", ") + ")"
+}
+
+// columnSQL renders a column definition as used by CREATE TABLE and ALTER TABLE ADD COLUMN
+func (m *tableModel) columnSQL(d Dialect, column ColumnInfo) string {
+	sql := FormatIdentifier(d, column.Name) + " "
+	switch {
+	case column.Name == m.AutoIncrement:
+		sql += d.AutoIncrementColumn()
+	case column.PrimaryKey && len(m.Info.PrimaryKey) == 1:
+		sql += column.Type + " PRIMARY KEY"
+		// SQLite accepts NULL in any primary key but the INTEGER rowid alias
+		if d.Name() == (SQLiteDialect{}).Name() && !strings.EqualFold(column.Type, "INTEGER") {
+			sql += " NOT NULL"
+		}
+	default:
+		sql += column.Type
+		if !column.Nullable {
+			sql += " NOT NULL"
+		}
+	}
+	for _, unique := range m.Info.UniqueConstraints {
+		if unique.Name == "" && unique.Columns[0] == column.Name {
+			sql += " UNIQUE"
+		}
+	}
+	if column.Default != nil {
+		sql += " DEFAULT " + *column.Default
+	}
+	for _, fk := range m.Info.ForeignKeys {
+		if fk.Columns[0] != column.Name {
+			continue
+		}
+		sql += " REFERENCES " + FormatIdentifier(d, fk.RefTable)
+		if len(fk.RefColumns) > 0 {
+			sql += " (" + formatColumns(d, fk.RefColumns) + ")"
+		}
+		if fk.OnDelete != "" {
+			sql += " ON DELETE " + fk.OnDelete
+		}
+		if fk.OnUpdate != "" {
+			sql += " ON UPDATE " + fk.OnUpdate
+		}
+	}
+	return sql
+}
+
+// createIndexSQL renders CREATE [UNIQUE] INDEX for a table
+func createIndexSQL(d Dialect, table string, index IndexInfo) string {
+	sql := "CREATE INDEX "
+	if index.Unique {
+		sql = "CREATE UNIQUE INDEX "
+	}
+	return sql + FormatIdentifier(d, index.Name) + " ON " + FormatIdentifier(d, table) + " (" + formatColumns(d, index.Columns) + ")"
+}
+
+// formatColumns renders a comma separated column list
+func formatColumns(d Dialect, columns []string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = FormatIdentifier(d, column)
+	}
+	return strings.Join(parts, ", ")
+}
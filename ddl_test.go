@@ -0,0 +1,168 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ddlUser struct {
+	ID        int64          `db:"id,pk,autoincrement"`
+	Email     string         `db:"email,unique"`
+	Name      *string        `db:"name"`
+	Active    bool           `db:"active,default=true"`
+	Score     float64        `db:"score"`
+	Avatar    []byte         `db:"avatar"`
+	Balance   int            `db:"balance,type=DECIMAL(10,2),default=0"`
+	Nickname  sql.NullString `db:"nickname"`
+	CreatedAt time.Time      `db:"created_at,index"`
+	Internal  string         `db:"-"`
+}
+
+func (u *ddlUser) TableName() string { return "users" }
+
+type ddlOrderItem struct {
+	OrderID   int64            `db:"order_id,pk,references=orders(id),ondelete=cascade,index=idx_items_order"`
+	ProductID int32            `db:"product_id,pk,references=products"`
+	Position  int16            `db:"position,unique=uq_items_position,index=idx_items_order"`
+	Batch     *int             `db:"batch,notnull,unique=uq_items_position"`
+	Note      sql.Null[string] `db:"note"`
+}
+
+func (oi *ddlOrderItem) TableName() string { return "shop.order_items" }
+
+type ddlToken struct {
+	Code  string `db:"code,pk"`
+	Hits  uint64 `db:"hits"`
+	Uses  uint32 `db:"uses"`
+	Owner uint   `db:"owner"`
+}
+
+func (tk *ddlToken) TableName() string { return "tokens" }
+
+type ddlUnsupported struct {
+	Data map[string]string `db:"data"`
+}
+
+func (u *ddlUnsupported) TableName() string { return "unsupported" }
+
+type ddlBadReference struct {
+	UserID int `db:"user_id,references=users(id);drop"`
+}
+
+func (b *ddlBadReference) TableName() string { return "bad_reference" }
+
+type ddlBadAction struct {
+	UserID int `db:"user_id,references=users,ondelete=explode"`
+}
+
+func (b *ddlBadAction) TableName() string { return "bad_action" }
+
+type ddlBadAutoIncrement struct {
+	Code string `db:"code,pk,autoincrement"`
+}
+
+func (b *ddlBadAutoIncrement) TableName() string { return "bad_autoincrement" }
+
+type ddlNoColumns struct {
+	internal string
+}
+
+func (n *ddlNoColumns) TableName() string { return "empty" }
+
+// TestCreateTableSQL checks column types, constraints and indexes in both dialects
+func TestCreateTableSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		obj     TableStruct
+		want    []string
+	}{
+		{
+			name:    "sqlite user",
+			dialect: SQLiteDialect{},
+			obj:     &ddlUser{},
+			want: []string{
+				"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL UNIQUE, name TEXT, " +
+					"active BOOLEAN NOT NULL DEFAULT true, score REAL NOT NULL, avatar BLOB NOT NULL, " +
+					"balance DECIMAL(10,2) NOT NULL DEFAULT 0, nickname TEXT, created_at DATETIME NOT NULL)",
+				"CREATE INDEX idx_users_created_at ON users (created_at)",
+			},
+		},
+		{
+			name:    "postgres user",
+			dialect: PostgreSQLDialect{},
+			obj:     &ddlUser{},
+			want: []string{
+				"CREATE TABLE users (id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY, email TEXT NOT NULL UNIQUE, name TEXT, " +
+					"active BOOLEAN NOT NULL DEFAULT true, score DOUBLE PRECISION NOT NULL, avatar BYTEA NOT NULL, " +
+					"balance DECIMAL(10,2) NOT NULL DEFAULT 0, nickname TEXT, created_at TIMESTAMPTZ NOT NULL)",
+				"CREATE INDEX idx_users_created_at ON users (created_at)",
+			},
+		},
+		{
+			name:    "postgres composite keys",
+			dialect: PostgreSQLDialect{},
+			obj:     &ddlOrderItem{},
+			want: []string{
+				"CREATE TABLE shop.order_items (order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE, " +
+					"product_id INTEGER NOT NULL REFERENCES products, position SMALLINT NOT NULL, batch BIGINT NOT NULL, note TEXT, " +
+					"PRIMARY KEY (order_id, product_id), CONSTRAINT uq_items_position UNIQUE (position, batch))",
+				"CREATE INDEX idx_items_order ON shop.order_items (order_id, position)",
+			},
+		},
+		{
+			name:    "sqlite text primary key",
+			dialect: SQLiteDialect{},
+			obj:     &ddlToken{},
+			want: []string{
+				"CREATE TABLE tokens (code TEXT PRIMARY KEY NOT NULL, hits INTEGER NOT NULL, uses INTEGER NOT NULL, owner INTEGER NOT NULL)",
+			},
+		},
+		{
+			name:    "postgres unsigned integers",
+			dialect: PostgreSQLDialect{},
+			obj:     &ddlToken{},
+			want: []string{
+				"CREATE TABLE tokens (code TEXT PRIMARY KEY, hits NUMERIC(20) NOT NULL, uses BIGINT NOT NULL, owner NUMERIC(20) NOT NULL)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CreateTableSQL(tt.dialect, tt.obj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestCreateTableSQLErrors checks unsupported types and invalid tag options are rejected
+func TestCreateTableSQLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     TableStruct
+		wantErr error
+	}{
+		{"unsupported type", &ddlUnsupported{}, ErrUnsupportedColumnType},
+		{"invalid reference", &ddlBadReference{}, ErrInvalidTagOption},
+		{"invalid referential action", &ddlBadAction{}, ErrInvalidTagOption},
+		{"autoincrement on text", &ddlBadAutoIncrement{}, ErrInvalidTagOption},
+		{"no columns", &ddlNoColumns{}, ErrNoColumns},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateTableSQL(SQLiteDialect{}, tt.obj); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
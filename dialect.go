@@ -0,0 +1,391 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Dialect describes the SQL differences between database engines so the same
+// builders (Condition, ComplexQuery, update/delete/upsert) emit correct SQL for
+// every backend. Each backend exposes its dialect through Database.Dialect().
+//
+// Usage:
+//
+//	query, values, err := condition.ToSelectStringDialect(orm.PostgreSQLDialect{}, "users")
+//	// SELECT * FROM users WHERE age > $1
+type Dialect interface {
+	// Name of the dialect, e.g. "sqlite" or "postgresql"
+	Name() string
+	// Placeholder returns the bind parameter for the n-th value (1-based)
+	Placeholder(n int) string
+	// QuoteIdentifier always quotes a single identifier part (no dots)
+	QuoteIdentifier(name string) string
+	// NeedsQuoting reports whether an unquoted identifier part must be quoted to be used as-is
+	NeedsQuoting(name string) bool
+	// LimitOffset renders the pagination clause, empty when both are zero
+	LimitOffset(limit, offset int) string
+	// BoolLiteral and TimeLiteral render values inlined into raw SQL
+	BoolLiteral(b bool) string
+	TimeLiteral(t time.Time) string
+	// SupportsReturning reports whether INSERT/UPDATE ... RETURNING can be used
+	SupportsReturning() bool
+	// UpsertClause renders the conflict handling of an INSERT. No update columns means DO NOTHING.
+	UpsertClause(conflictColumns, updateColumns []string) string
+	// ColumnType maps a Go type to a column type for CREATE TABLE, empty when there is no mapping.
+	// Pointers and sql.Null* wrappers are unwrapped by the caller (see CreateTableSQL).
+	ColumnType(t reflect.Type) string
+	// AutoIncrementColumn renders the type and PRIMARY KEY of an auto-incrementing integer column
+	AutoIncrementColumn() string
+}
+
+// defaultDialect keeps the historical `?` placeholder output of ToWhereString,
+// ToSelectString and ToSQL.
+var defaultDialect Dialect = SQLiteDialect{}
+
+// SQLiteDialect is used by the RQLite backends
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Name() string { return "sqlite" }
+
+func (SQLiteDialect) Placeholder(int) string { return "?" }
+
+func (SQLiteDialect) QuoteIdentifier(name string) string { return quoteWith(name, '"') }
+
+// NeedsQuoting for SQLite: only reserved words, identifiers are case-insensitive
+func (SQLiteDialect) NeedsQuoting(name string) bool { return isReservedWord(name) }
+
+// LimitOffset for SQLite; OFFSET is only valid after a LIMIT, -1 means no limit
+func (SQLiteDialect) LimitOffset(limit, offset int) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf("LIMIT -1 OFFSET %d", offset)
+	}
+	return ""
+}
+
+// BoolLiteral for SQLite, which stores booleans as integers
+func (SQLiteDialect) BoolLiteral(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// TimeLiteral uses the text format understood by SQLite date functions
+func (SQLiteDialect) TimeLiteral(t time.Time) string {
+	return "'" + t.UTC().Format("2006-01-02 15:04:05.999999999") + "'"
+}
+
+// SupportsReturning is false: RETURNING needs SQLite 3.35+ and RQLite's
+// /db/execute endpoint does not return rows anyway
+func (SQLiteDialect) SupportsReturning() bool { return false }
+
+func (SQLiteDialect) UpsertClause(conflictColumns, updateColumns []string) string {
+	return onConflictClause(conflictColumns, updateColumns)
+}
+
+// ColumnType for SQLite uses the type names RQLite decodes back into the Go type:
+// every integer is INTEGER (so a single INTEGER primary key is the rowid) and
+// time.Time is DATETIME.
+// SQLite integers are signed 64-bit, so a uint or uint64 column holds at most
+// math.MaxInt64; use a type=TEXT tag option for larger values.
+func (SQLiteDialect) ColumnType(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "DATETIME"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "BLOB"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	case reflect.String:
+		return "TEXT"
+	}
+	return ""
+}
+
+// AutoIncrementColumn for SQLite, AUTOINCREMENT also prevents the reuse of deleted ids
+func (SQLiteDialect) AutoIncrementColumn() string { return "INTEGER PRIMARY KEY AUTOINCREMENT" }
+
+// PostgreSQLDialect is used by the postgres backend.
+// PostgreSQL folds unquoted identifiers to lower case, so a column created as "createdAt"
+// can only be reached quoted; set QuoteMixedCase to quote every identifier with upper case letters.
+type PostgreSQLDialect struct {
+	QuoteMixedCase bool
+}
+
+func (PostgreSQLDialect) Name() string { return "postgresql" }
+
+func (PostgreSQLDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (PostgreSQLDialect) QuoteIdentifier(name string) string { return quoteWith(name, '"') }
+
+func (d PostgreSQLDialect) NeedsQuoting(name string) bool {
+	return isReservedWord(name) || (d.QuoteMixedCase && name != strings.ToLower(name))
+}
+
+func (PostgreSQLDialect) LimitOffset(limit, offset int) string {
+	parts := make([]string, 0, 2)
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (PostgreSQLDialect) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (PostgreSQLDialect) TimeLiteral(t time.Time) string {
+	return "TIMESTAMPTZ '" + t.Format(time.RFC3339Nano) + "'"
+}
+
+func (PostgreSQLDialect) SupportsReturning() bool { return true }
+
+func (PostgreSQLDialect) UpsertClause(conflictColumns, updateColumns []string) string {
+	return onConflictClause(conflictColumns, updateColumns)
+}
+
+// ColumnType for PostgreSQL picks the smallest integer type holding every value of the Go type
+func (PostgreSQLDialect) ColumnType(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "TIMESTAMPTZ"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return "BYTEA"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "BIGINT"
+	case reflect.Uint, reflect.Uint64:
+		return "NUMERIC(20)"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "DOUBLE PRECISION"
+	case reflect.String:
+		return "TEXT"
+	}
+	return ""
+}
+
+func (PostgreSQLDialect) AutoIncrementColumn() string {
+	return "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY"
+}
+
+// onConflictClause is the ON CONFLICT syntax shared by SQLite (>= 3.24) and PostgreSQL
+func onConflictClause(conflictColumns, updateColumns []string) string {
+	target := ""
+	if len(conflictColumns) > 0 {
+		target = " (" + strings.Join(conflictColumns, ", ") + ")"
+	}
+	if len(updateColumns) == 0 {
+		return "ON CONFLICT" + target + " DO NOTHING"
+	}
+	sets := make([]string, 0, len(updateColumns))
+	for _, col := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%s = excluded.%s", col, col))
+	}
+	return "ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// reservedWords are keywords that cannot be used as bare identifiers in PostgreSQL
+// and/or SQLite. Identifiers matching one of them are quoted when rendered.
+var reservedWords = map[string]bool{
+	"all": true, "alter": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "between": true, "both": true, "by": true, "case": true,
+	"cast": true, "check": true, "collate": true, "column": true, "constraint": true,
+	"create": true, "cross": true, "current_date": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "default": true, "deferrable": true,
+	"delete": true, "desc": true, "distinct": true, "do": true, "drop": true, "else": true,
+	"end": true, "escape": true, "except": true, "exists": true, "false": true,
+	"fetch": true, "for": true, "foreign": true, "from": true, "full": true, "grant": true,
+	"group": true, "having": true, "in": true, "index": true, "inner": true, "insert": true,
+	"intersect": true, "into": true, "is": true, "join": true, "leading": true, "left": true,
+	"like": true, "limit": true, "natural": true, "not": true, "null": true, "offset": true,
+	"on": true, "only": true, "or": true, "order": true, "outer": true, "primary": true,
+	"references": true, "returning": true, "right": true, "select": true, "session_user": true,
+	"set": true, "some": true, "table": true, "then": true, "to": true, "trailing": true,
+	"transaction": true, "true": true, "union": true, "unique": true, "update": true,
+	"user": true, "using": true, "values": true, "when": true, "where": true, "window": true,
+	"with": true,
+}
+
+func isReservedWord(name string) bool { return reservedWords[strings.ToLower(name)] }
+
+// FormatIdentifier renders a validated, possibly qualified identifier (schema.table.column)
+// for the dialect: parts that are already quoted are kept, parts that are reserved words
+// (or otherwise need it, see Dialect.NeedsQuoting) are quoted, the rest is left bare.
+//
+//	orm.FormatIdentifier(orm.PostgreSQLDialect{}, "orders.user")  // orders."user"
+func FormatIdentifier(d Dialect, name string) string {
+	if d == nil {
+		d = defaultDialect
+	}
+	parts := splitIdentifier(name)
+	for i, part := range parts {
+		if !strings.HasPrefix(part, `"`) && d.NeedsQuoting(part) {
+			parts[i] = d.QuoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitIdentifier splits a qualified identifier on dots that are not inside double quotes
+func splitIdentifier(name string) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '"':
+			inQuote = !inQuote
+		case '.':
+			if !inQuote {
+				parts = append(parts, name[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// identifierKey normalizes an identifier for comparison: quoted parts keep their case,
+// bare parts are case-insensitive
+func identifierKey(name string) string {
+	parts := splitIdentifier(name)
+	for i, part := range parts {
+		if strings.HasPrefix(part, `"`) {
+			parts[i] = strings.Trim(part, `"`)
+		} else {
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteWith wraps name in the quote character, doubling embedded quotes
+func quoteWith(name string, quote byte) string {
+	q := string(quote)
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+// FormatLiteral renders a Go value as an inline SQL literal for the dialect.
+// Prefer parameterized queries; this is meant for raw SQL dumps and debugging.
+func FormatLiteral(d Dialect, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return d.BoolLiteral(v)
+	case time.Time:
+		return d.TimeLiteral(v)
+	case *time.Time:
+		if v == nil {
+			return "NULL"
+		}
+		return d.TimeLiteral(*v)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "'" + strings.ReplaceAll(string(v), "'", "''") + "'"
+	}
+	return InterfaceToSQLString(value)
+}
+
+// sqlBuilder accumulates bind values while SQL is rendered, so placeholders are
+// numbered in the order they appear in the final statement (matters for $N).
+type sqlBuilder struct {
+	dialect Dialect
+	args    []interface{}
+	// qualifiers are the table names/aliases a qualified column may reference,
+	// keyed by identifierKey. nil means qualifiers are not checked.
+	qualifiers map[string]bool
+	// aggregates allows aggregate function fields such as COUNT(orders.id), set while rendering HAVING
+	aggregates bool
+}
+
+func newSQLBuilder(d Dialect) *sqlBuilder {
+	if d == nil {
+		d = defaultDialect
+	}
+	return &sqlBuilder{dialect: d}
+}
+
+// bind registers a value and returns its placeholder
+func (b *sqlBuilder) bind(value interface{}) string {
+	b.args = append(b.args, value)
+	return b.dialect.Placeholder(len(b.args))
+}
+
+// ident renders a validated identifier for the builder's dialect
+func (b *sqlBuilder) ident(name string) string {
+	return FormatIdentifier(b.dialect, name)
+}
+
+// allowQualifier registers a table name or alias that qualified columns may use
+func (b *sqlBuilder) allowQualifier(name string) {
+	if b.qualifiers == nil {
+		b.qualifiers = make(map[string]bool)
+	}
+	b.qualifiers[identifierKey(name)] = true
+}
+
+// allowTable registers the qualifiers of a FROM/JOIN table: only the alias once the
+// table is aliased (as the database requires), otherwise the table name and, for
+// schema.table, the bare table name
+func (b *sqlBuilder) allowTable(table, alias string) {
+	if alias != "" {
+		b.allowQualifier(alias)
+		return
+	}
+	b.allowQualifier(table)
+	if parts := splitIdentifier(table); len(parts) > 1 {
+		b.allowQualifier(parts[len(parts)-1])
+	}
+}
+
+// checkQualifier verifies that the qualifier of a column (users in users.id) is a
+// table or alias of the query being rendered
+func (b *sqlBuilder) checkQualifier(field string) error {
+	if b.qualifiers == nil {
+		return nil
+	}
+	parts := splitIdentifier(field)
+	if len(parts) < 2 {
+		return nil
+	}
+	return b.checkTableRef(strings.Join(parts[:len(parts)-1], "."))
+}
+
+// checkTableRef verifies that a table name or alias belongs to the query being rendered
+func (b *sqlBuilder) checkTableRef(name string) error {
+	if b.qualifiers == nil || b.qualifiers[identifierKey(name)] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownQualifier, name)
+}
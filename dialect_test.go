@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestDialectPlaceholders tests that bind values are numbered in the order they are rendered
+func TestDialectPlaceholders(t *testing.T) {
+	condition := &Condition{Logic: "OR", Nested: []Condition{
+		{Field: "id", Operator: "IN", Value: []interface{}{1, 2}},
+		{Field: "age", Operator: "BETWEEN", Value: []interface{}{18, 30}},
+		{Field: "name", Operator: "=", Value: "ana"},
+	}}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		wantSQL string
+	}{
+		{"sqlite", SQLiteDialect{}, "(id IN (?, ?)) OR (age BETWEEN ? AND ?) OR (name = ?)"},
+		{"postgresql", PostgreSQLDialect{}, "(id IN ($1, $2)) OR (age BETWEEN $3 AND $4) OR (name = $5)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, values, err := condition.ToWhereStringDialect(tt.dialect)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Expected %q, got %q", tt.wantSQL, sql)
+			}
+			if want := []interface{}{1, 2, 18, 30, "ana"}; !reflect.DeepEqual(values, want) {
+				t.Errorf("Expected %v, got %v", want, values)
+			}
+		})
+	}
+
+	b := newSQLBuilder(nil)
+	if got := b.bind(1) + b.bind(2); got != "??" {
+		t.Errorf("Expected the nil dialect to fall back to ?, got %q", got)
+	}
+}
+
+// TestQuoteIdentifier tests quoting of the identifier parts FormatIdentifier splits
+// (the quoting rules themselves are covered by TestFormatIdentifier)
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"embedded quote is doubled", PostgreSQLDialect{}.QuoteIdentifier(`we"ird`), `"we""ird"`},
+		{"sqlite quotes with double quotes", SQLiteDialect{}.QuoteIdentifier("order"), `"order"`},
+		{"dot inside a quoted part", FormatIdentifier(PostgreSQLDialect{}, `"My.Table".select`), `"My.Table"."select"`},
+		{"schema table column", FormatIdentifier(PostgreSQLDialect{}, "app.orders.group"), `app.orders."group"`},
+		{"nil dialect", FormatIdentifier(nil, "select"), `"select"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+// TestDialectRendering tests the per-dialect pagination and literal rendering
+func TestDialectRendering(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"sqlite limit", SQLiteDialect{}.LimitOffset(10, 0), "LIMIT 10"},
+		{"sqlite limit offset", SQLiteDialect{}.LimitOffset(10, 20), "LIMIT 10 OFFSET 20"},
+		{"sqlite offset only", SQLiteDialect{}.LimitOffset(0, 20), "LIMIT -1 OFFSET 20"},
+		{"sqlite none", SQLiteDialect{}.LimitOffset(0, 0), ""},
+		{"postgresql offset only", PostgreSQLDialect{}.LimitOffset(0, 20), "OFFSET 20"},
+		{"postgresql limit offset", PostgreSQLDialect{}.LimitOffset(10, 20), "LIMIT 10 OFFSET 20"},
+		{"sqlite bool", FormatLiteral(SQLiteDialect{}, true), "1"},
+		{"postgresql bool", FormatLiteral(PostgreSQLDialect{}, false), "FALSE"},
+		{"sqlite time", FormatLiteral(SQLiteDialect{}, ts), "'2024-01-02 03:04:05'"},
+		{"postgresql time", FormatLiteral(PostgreSQLDialect{}, ts), "TIMESTAMPTZ '2024-01-02T03:04:05Z'"},
+		{"nil time pointer", FormatLiteral(PostgreSQLDialect{}, (*time.Time)(nil)), "NULL"},
+		{"string escapes quotes", FormatLiteral(SQLiteDialect{}, "it's"), "'it''s'"},
+		{"do nothing", onConflictClause([]string{"id"}, nil), "ON CONFLICT (id) DO NOTHING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, tt.got)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func main() {
-	fmt.Println("=== SimpleORM PostgreSQL Example ===\n")
+	fmt.Print("=== SimpleORM PostgreSQL Example ===\n\n")
 
 	// Database configuration
 	config := postgres.NewConfig(
@@ -44,6 +44,7 @@ func main() {
 	runStructExamples(db)
 	runTransactionExamples(db)
 	runUpdateDeleteExamples(db)
+	runPrimaryKeyExamples(db)
 
 	fmt.Println("\n=== All Examples Complete ===")
 }
@@ -310,6 +311,44 @@ func runUpdateDeleteExamples(db postgres.PostgresDirectDB) {
 	fmt.Printf("✓ Verified update - Alice's new email: %s\n", updatedUser.Data["email"])
 }
 
+// runPrimaryKeyExamples round-trips structs using their `pk` tagged fields
+func runPrimaryKeyExamples(db postgres.PostgresDirectDB) {
+	fmt.Println("\n--- Example 5: Find, Update, Reload & Delete by Primary Key ---")
+
+	// FindByPK: typed lookup by primary key
+	user, err := orm.FindByPK[User](db, 1)
+	if err != nil {
+		log.Printf("FindByPK failed: %v", err)
+		return
+	}
+	fmt.Printf("✓ Found user #%d: %s (%s)\n", user.ID, user.Name, user.Email)
+
+	// UpdateTableStruct: writes every non-key column WHERE id = user.ID
+	user.Age++
+	if result := orm.UpdateTableStruct(db, &user); result.Error != nil {
+		log.Printf("UpdateTableStruct failed: %v", result.Error)
+		return
+	}
+
+	// ReloadTableStruct: refresh the struct from the database
+	if err := orm.ReloadTableStruct(db, &user); err != nil {
+		log.Printf("ReloadTableStruct failed: %v", err)
+		return
+	}
+	fmt.Printf("✓ Updated and reloaded user #%d, age is now %d\n", user.ID, user.Age)
+
+	// DeleteTableStruct: removes the row identified by the primary key
+	order := &Order{UserID: user.ID, Total: 10, Status: "cancelled"}
+	if result := db.InsertOneTableStruct(order, false); result.Error == nil {
+		order.ID = result.LastInsertID
+		if result := orm.DeleteTableStruct(db, order); result.Error != nil {
+			log.Printf("DeleteTableStruct failed: %v", result.Error)
+			return
+		}
+		fmt.Printf("✓ Deleted order #%d by primary key\n", order.ID)
+	}
+}
+
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -5,7 +5,7 @@ import "time"
 // User represents a user in the system
 // Implements orm.TableStruct interface
 type User struct {
-	ID        int       `json:"id" db:"id"`
+	ID        int       `json:"id" db:"id,pk"`
 	Name      string    `json:"name" db:"name"`
 	Email     string    `json:"email" db:"email"`
 	Age       int       `json:"age" db:"age"`
@@ -20,7 +20,7 @@ func (u *User) TableName() string {
 // Product represents a product in the catalog
 // Implements orm.TableStruct interface
 type Product struct {
-	ID        int       `json:"id" db:"id"`
+	ID        int       `json:"id" db:"id,pk"`
 	Name      string    `json:"name" db:"name"`
 	Price     float64   `json:"price" db:"price"`
 	Stock     int       `json:"stock" db:"stock"`
@@ -35,7 +35,7 @@ func (p *Product) TableName() string {
 // Order represents a customer order
 // Implements orm.TableStruct interface
 type Order struct {
-	ID        int       `json:"id" db:"id"`
+	ID        int       `json:"id" db:"id,pk"`
 	UserID    int       `json:"user_id" db:"user_id"`
 	Total     float64   `json:"total" db:"total"`
 	Status    string    `json:"status" db:"status"`
@@ -50,7 +50,7 @@ func (o *Order) TableName() string {
 // OrderItem represents a line item in an order
 // Implements orm.TableStruct interface
 type OrderItem struct {
-	ID        int     `json:"id" db:"id"`
+	ID        int     `json:"id" db:"id,pk"`
 	OrderID   int     `json:"order_id" db:"order_id"`
 	ProductID int     `json:"product_id" db:"product_id"`
 	Quantity  int     `json:"quantity" db:"quantity"`
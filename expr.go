@@ -0,0 +1,80 @@
+package orm
+
+import (
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrEmptyExpression     medaerror.MedaError = medaerror.MedaError{Message: "expression SQL cannot be empty"}
+	ErrExpressionArgsCount medaerror.MedaError = medaerror.MedaError{Message: "number of ? placeholders in expression does not match the number of arguments"}
+)
+
+// Expression is a TRUSTED raw SQL fragment with ? placeholders for its arguments.
+// Unlike Condition.Field, the SQL is not validated: never build it from user input,
+// pass user input through Args instead. The ? placeholders are renumbered for the
+// dialect ($1, $2, ... on PostgreSQL) in statement order, question marks inside
+// quoted literals or identifiers are left alone.
+//
+// Expressions are never read from or written to JSON (the fields and the Condition /
+// ComplexQuery fields holding them are tagged json:"-"), so decoding a client-supplied
+// Condition or ComplexQuery cannot inject raw SQL. They can only be set from Go code.
+//
+// Usage:
+//
+//	// as a condition leaf
+//	where := &orm.Condition{Logic: "AND", Nested: []orm.Condition{
+//	    {Expr: orm.Expr("lower(email) = lower(?)", email)},
+//	    {Expr: orm.Expr("created_at > datetime('now', ?)", "-7 days")},
+//	}}
+//
+//	// in a ComplexQuery
+//	query := orm.ComplexQuery{
+//	    From:         "products",
+//	    SelectExprs:  []*orm.Expression{orm.Expr("price * ? AS price_with_tax", 1.21)},
+//	    OrderByExprs: []*orm.Expression{orm.Expr("abs(price - ?)", target)},
+//	}
+type Expression struct {
+	SQL  string        `json:"-"`
+	Args []interface{} `json:"-"`
+}
+
+// Expr creates a trusted raw SQL Expression, see Expression
+func Expr(sql string, args ...interface{}) *Expression {
+	return &Expression{SQL: sql, Args: args}
+}
+
+// writeExpr renders the expression, binding its arguments into the builder
+func (e *Expression) writeExpr(b *sqlBuilder) (string, error) {
+	if e == nil || strings.TrimSpace(e.SQL) == "" {
+		return "", ErrEmptyExpression
+	}
+
+	var sb strings.Builder
+	used := 0
+	var quote byte // current quote character, 0 outside literals
+	for i := 0; i < len(e.SQL); i++ {
+		ch := e.SQL[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0 // doubled quotes simply close and reopen
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			if used >= len(e.Args) {
+				return "", ErrExpressionArgsCount
+			}
+			sb.WriteString(b.bind(e.Args[used]))
+			used++
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	if used != len(e.Args) {
+		return "", ErrExpressionArgsCount
+	}
+	return sb.String(), nil
+}
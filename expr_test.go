@@ -0,0 +1,144 @@
+package orm
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestExpressionCondition checks raw expression leaves inside conditions for both dialects
+func TestExpressionCondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		condition    Condition
+		wantSQLite   string
+		wantPostgres string
+		wantValues   []interface{}
+		wantErr      error
+	}{
+		{
+			name:         "function call",
+			condition:    Condition{Expr: Expr("lower(email) = lower(?)", "A@B.COM")},
+			wantSQLite:   "lower(email) = lower(?)",
+			wantPostgres: "lower(email) = lower($1)",
+			wantValues:   []interface{}{"A@B.COM"},
+		},
+		{
+			name:         "question mark in literal is not a placeholder",
+			condition:    Condition{Expr: Expr("note <> '?' AND created_at > datetime('now', ?)", "-7 days")},
+			wantSQLite:   "note <> '?' AND created_at > datetime('now', ?)",
+			wantPostgres: "note <> '?' AND created_at > datetime('now', $1)",
+			wantValues:   []interface{}{"-7 days"},
+		},
+		{
+			name: "mixed with field conditions",
+			condition: Condition{Logic: "AND", Nested: []Condition{
+				{Field: "status", Operator: "=", Value: "active"},
+				{Expr: Expr("age BETWEEN ? AND ?", 18, 65)},
+				{Field: "country", Operator: "=", Value: "NL"},
+			}},
+			wantSQLite:   "(status = ?) AND (age BETWEEN ? AND ?) AND (country = ?)",
+			wantPostgres: "(status = $1) AND (age BETWEEN $2 AND $3) AND (country = $4)",
+			wantValues:   []interface{}{"active", 18, 65, "NL"},
+		},
+		{
+			name:      "too few arguments",
+			condition: Condition{Expr: Expr("a = ? AND b = ?", 1)},
+			wantErr:   ErrExpressionArgsCount,
+		},
+		{
+			name:      "too many arguments",
+			condition: Condition{Expr: Expr("a = ?", 1, 2)},
+			wantErr:   ErrExpressionArgsCount,
+		},
+		{
+			name:      "empty expression",
+			condition: Condition{Expr: Expr("  ")},
+			wantErr:   ErrEmptyExpression,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, d := range []Dialect{SQLiteDialect{}, PostgreSQLDialect{}} {
+			t.Run(tt.name+"/"+d.Name(), func(t *testing.T) {
+				got, values, err := tt.condition.ToWhereStringDialect(d)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				want := tt.wantSQLite
+				if d.Name() == "postgresql" {
+					want = tt.wantPostgres
+				}
+				if got != want {
+					t.Errorf("Expected %q, got %q", want, got)
+				}
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+				}
+			})
+		}
+	}
+}
+
+// TestExpressionComplexQuery checks expressions in SELECT, HAVING and ORDER BY are bound in statement order
+func TestExpressionComplexQuery(t *testing.T) {
+	query := &ComplexQuery{
+		Select:       []string{"category"},
+		SelectExprs:  []*Expression{Expr("SUM(price * ?) AS total", 1.21)},
+		From:         "products",
+		Where:        &Condition{Field: "active", Operator: "=", Value: true},
+		GroupBy:      []string{"category"},
+		Having:       "COUNT(*) > 1",
+		HavingExpr:   Expr("SUM(price) > ?", 100),
+		OrderByExprs: []*Expression{Expr("abs(SUM(price) - ?)", 500)},
+		Limit:        5,
+	}
+
+	want := "SELECT category, SUM(price * $1) AS total FROM products WHERE active = $2 GROUP BY category " +
+		"HAVING (COUNT(*) > 1) AND (SUM(price) > $3) ORDER BY abs(SUM(price) - $4) LIMIT 5"
+	wantValues := []interface{}{1.21, true, 100, 500}
+
+	got, values, err := query.ToSQLDialect(PostgreSQLDialect{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("Expected values %v, got %v", wantValues, values)
+	}
+}
+
+// TestExpressionNotDecoded checks trusted expressions cannot be injected through JSON
+func TestExpressionNotDecoded(t *testing.T) {
+	var condition Condition
+	if err := json.Unmarshal([]byte(`{"field":"id","expr":{"sql":"1=1 OR 1=1"},"Expr":{"SQL":"1=1"}}`), &condition); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition.Expr != nil {
+		t.Errorf("Expected no expression from JSON, got %+v", condition.Expr)
+	}
+
+	var query ComplexQuery
+	payload := `{"from":"users","select_exprs":[{"sql":"password"}],"having_expr":{"sql":"1=1"},` +
+		`"order_by_exprs":[{"sql":"(SELECT 1)"}],"SelectExprs":[{"SQL":"password"}]}`
+	if err := json.Unmarshal([]byte(payload), &query); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query.SelectExprs != nil || query.HavingExpr != nil || query.OrderByExprs != nil {
+		t.Errorf("Expected no expressions from JSON, got %+v", query)
+	}
+
+	data, _ := json.Marshal(Condition{Expr: Expr("secret = ?", 1)})
+	if string(data) != "{}" {
+		t.Errorf("Expected the expression to be left out of JSON, got %s", data)
+	}
+}
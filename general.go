@@ -2,6 +2,7 @@ package orm
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -22,13 +23,16 @@ var (
 	MAX_MULTIPLE_INSERTS int                 = DEFAULT_MAX_MULTIPLE_INSERTS
 
 	// Security: SQL Injection Protection
-	ErrInvalidFieldName    medaerror.MedaError = medaerror.MedaError{Message: "invalid field name: must contain only alphanumeric characters and underscores"}
+	ErrInvalidFieldName    medaerror.MedaError = medaerror.MedaError{Message: "invalid field name: must be an identifier (letters, digits, underscores or double-quoted), optionally qualified as table.column"}
 	ErrInvalidOperator     medaerror.MedaError = medaerror.MedaError{Message: "invalid SQL operator: not in allowed list"}
 	ErrEmptyTableName      medaerror.MedaError = medaerror.MedaError{Message: "table name cannot be empty"}
-	ErrInvalidTableName    medaerror.MedaError = medaerror.MedaError{Message: "invalid table name: must contain only alphanumeric characters and underscores"}
+	ErrInvalidTableName    medaerror.MedaError = medaerror.MedaError{Message: "invalid table name: must be an identifier (letters, digits, underscores or double-quoted), optionally qualified as schema.table"}
+	ErrInvalidAlias        medaerror.MedaError = medaerror.MedaError{Message: "invalid alias: must contain only alphanumeric characters and underscores"}
+	ErrUnknownQualifier    medaerror.MedaError = medaerror.MedaError{Message: "column qualifier does not match any table or alias of the query"}
 	ErrEmptyConditionField medaerror.MedaError = medaerror.MedaError{Message: "condition field cannot be empty when operator is specified"}
 	ErrMissingPrimaryKey   medaerror.MedaError = medaerror.MedaError{Message: "missing primary key in record data"}
 	ErrSQLMultipleRows     medaerror.MedaError = medaerror.MedaError{Message: "query returned multiple rows when expecting one"}
+	ErrInvalidBetweenValue medaerror.MedaError = medaerror.MedaError{Message: "BETWEEN requires a slice or array of exactly two values"}
 
 	// Whitelist of allowed SQL operators to prevent SQL injection
 	allowedOperators = map[string]bool{
@@ -45,15 +49,26 @@ var (
 		"IN":          true,
 		"NOT IN":      true,
 		"BETWEEN":     true,
+		"NOT BETWEEN": true,
 		"IS":          true,
 		"IS NOT":      true,
 		"IS NULL":     true,
 		"IS NOT NULL": true,
+		"EXISTS":      true, // only with Condition.Subquery
+		"NOT EXISTS":  true, // only with Condition.Subquery
 	}
 
 	// Regular expression for validating SQL identifiers (table/column names)
 	// Allows: letters, numbers, underscores; must start with letter or underscore
 	sqlIdentifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// A part of a qualified identifier: a bare identifier or a double-quoted one
+	// (no embedded quotes or NUL), parts are separated by dots
+	sqlIdentifierPart = `(?:[a-zA-Z_][a-zA-Z0-9_]*|"[^"\x00]+")`
+	// schema.table
+	sqlTableNameRegex = regexp.MustCompile(`^` + sqlIdentifierPart + `(?:\.` + sqlIdentifierPart + `)?$`)
+	// schema.table.column
+	sqlFieldNameRegex = regexp.MustCompile(`^` + sqlIdentifierPart + `(?:\.` + sqlIdentifierPart + `){0,2}$`)
 )
 
 // Struct to get the schema from sqlite_master table in SQLite
@@ -139,16 +154,31 @@ type ParametereizedSQL struct {
 //	  },
 //	}
 //	// Output: WHERE ((age > 18 AND country = 'USA') OR (status = 'active' AND role = 'admin'))
+//
+//	// Multi-value operators
+//	Condition{Field: "status", Operator: "IN", Value: []string{"new", "paid"}}  // status IN (?, ?)
+//	Condition{Field: "age", Operator: "BETWEEN", Value: []int{18, 65}}          // age BETWEEN ? AND ?
+//	Condition{Field: "deleted_at", Operator: "IS NULL"}                         // deleted_at IS NULL
+//
+//	// Subqueries (see Exists, NotExists)
+//	Condition{Field: "id", Operator: "IN", Subquery: &ComplexQuery{Select: []string{"user_id"}, From: "orders"}}
+//	// id IN (SELECT user_id FROM orders)
+//
+//	// Trusted raw SQL (see Expression)
+//	Condition{Expr: Expr("lower(email) = ?", email)}
 type Condition struct {
-	Field    string      `json:"field,omitempty"        db:"field"`
-	Operator string      `json:"operator,omitempty"     db:"operator"`
-	Value    interface{} `json:"value,omitempty"        db:"value"`
-	Logic    string      `json:"logic,omitempty"        db:"logic"`    // "AND" or "OR"
-	Nested   []Condition `json:"nested,omitempty"       db:"nested"`   // For nested conditions
-	OrderBy  []string    `json:"order_by,omitempty"     db:"order_by"` // Fields to order by
-	GroupBy  []string    `json:"group_by,omitempty"     db:"group_by"` // Fields to group by
-	Limit    int         `json:"limit,omitempty"        db:"limit"`    // Limit for pagination
-	Offset   int         `json:"offset,omitempty"       db:"offset"`   // Offset for pagination
+	Field      string        `json:"field,omitempty"        db:"field"`
+	Operator   string        `json:"operator,omitempty"     db:"operator"`
+	Value      interface{}   `json:"value,omitempty"        db:"value"`
+	Logic      string        `json:"logic,omitempty"        db:"logic"`       // "AND" or "OR"
+	Nested     []Condition   `json:"nested,omitempty"       db:"nested"`      // For nested conditions
+	OrderBy    []string      `json:"order_by,omitempty"     db:"order_by"`    // Fields to order by, "column [ASC|DESC] [NULLS FIRST|LAST]" (validated)
+	GroupBy    []string      `json:"group_by,omitempty"     db:"group_by"`    // Columns to group by (validated)
+	Limit      int           `json:"limit,omitempty"        db:"limit"`       // Limit for pagination
+	Offset     int           `json:"offset,omitempty"       db:"offset"`      // Offset for pagination
+	Subquery   *ComplexQuery `json:"subquery,omitempty"     db:"subquery"`    // Compare against / test a subquery instead of Value
+	Expr       *Expression   `json:"-"`                                       // Trusted raw SQL leaf, replaces Field/Operator/Value (Go code only, never decoded)
+	OrderTerms []OrderTerm   `json:"order_terms,omitempty"  db:"order_terms"` // Validated ORDER BY, appended after OrderBy
 }
 
 // And creates a new Condition with AND logic for the given conditions.
@@ -200,36 +230,60 @@ func (c *Condition) Or(conditions ...Condition) *Condition {
 //   - []interface{}: Slice of values corresponding to the parameters
 //   - error: Validation error if field name or operator is invalid
 func (c *Condition) ToWhereString() (string, []interface{}, error) {
+	return c.ToWhereStringDialect(defaultDialect)
+}
+
+// ToWhereStringDialect is ToWhereString with placeholders rendered for the given dialect
+// (e.g. $1, $2 for PostgreSQL).
+func (c *Condition) ToWhereStringDialect(d Dialect) (string, []interface{}, error) {
+	b := newSQLBuilder(d)
+	clause, err := c.writeWhere(b)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, b.args, nil
+}
+
+// writeWhere renders the condition tree, binding values into the builder
+func (c *Condition) writeWhere(b *sqlBuilder) (string, error) {
 	var clauses []string
-	var args []interface{}
 
-	if c.Field != "" { // Base case for simple condition
+	if c.Expr != nil { // Trusted raw SQL leaf
+		clause, err := c.Expr.writeExpr(b)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, clause)
+	} else if c.Field != "" || c.Subquery != nil || c.Operator != "" && len(c.Nested) == 0 { // Base case for simple condition
 		// Security: Validate field name to prevent SQL injection
-		if err := ValidateFieldName(c.Field); err != nil {
-			return "", nil, err
+		// (qualified columns must also reference a table or alias of the query)
+		if err := b.checkField(c.Field); err != nil {
+			return "", err
 		}
 
 		// Security: Validate operator to prevent SQL injection
 		if err := ValidateOperator(c.Operator); err != nil {
-			return "", nil, err
+			return "", err
 		}
 
 		// Ensure both field and operator are present
 		if c.Operator == "" {
-			return "", nil, ErrInvalidOperator
+			return "", ErrInvalidOperator
 		}
 
-		clauses = append(clauses, fmt.Sprintf("%s %s ?", c.Field, strings.ToUpper(c.Operator)))
-		args = append(args, c.Value)
+		clause, err := c.writeComparison(b)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, clause)
 	} else { // Handle nested conditions
 		for _, nested := range c.Nested {
-			subClause, subArgs, err := nested.ToWhereString()
+			subClause, err := nested.writeWhere(b)
 			if err != nil {
-				return "", nil, err
+				return "", err
 			}
 			if subClause != "" { // Only append non-empty clauses
 				clauses = append(clauses, fmt.Sprintf("(%s)", subClause))
-				args = append(args, subArgs...)
 			}
 		}
 	}
@@ -239,7 +293,77 @@ func (c *Condition) ToWhereString() (string, []interface{}, error) {
 		logic = "AND" // Default to AND if not specified
 	}
 
-	return strings.Join(clauses, fmt.Sprintf(" %s ", logic)), args, nil
+	return strings.Join(clauses, fmt.Sprintf(" %s ", logic)), nil
+}
+
+// writeComparison renders a single field/operator/value condition:
+//   - IN / NOT IN expand a slice into (?, ?, ?). An empty slice renders 1 = 0 for IN
+//     (matches nothing) and 1 = 1 for NOT IN (matches everything). A non-slice value
+//     is treated as a one-element list.
+//   - BETWEEN / NOT BETWEEN take a slice or array of exactly two values.
+//   - IS NULL / IS NOT NULL take no value, IS / IS NOT with a nil value render NULL.
+//   - every other operator binds Value as a single parameter.
+func (c *Condition) writeComparison(b *sqlBuilder) (string, error) {
+	op := strings.Join(strings.Fields(strings.ToUpper(c.Operator)), " ")
+	if c.Subquery != nil || op == "EXISTS" || op == "NOT EXISTS" {
+		return c.writeSubqueryComparison(b, op)
+	}
+	if c.Field == "" {
+		return "", ErrEmptyConditionField
+	}
+	field := b.column(c.Field)
+
+	switch op {
+	case "IN", "NOT IN":
+		values, ok := sliceValues(c.Value)
+		if !ok {
+			values = []interface{}{c.Value}
+		}
+		if len(values) == 0 {
+			if op == "IN" {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
+		}
+		placeholders := make([]string, 0, len(values))
+		for _, v := range values {
+			placeholders = append(placeholders, b.bind(v))
+		}
+		return fmt.Sprintf("%s %s (%s)", field, op, strings.Join(placeholders, ", ")), nil
+	case "BETWEEN", "NOT BETWEEN":
+		values, ok := sliceValues(c.Value)
+		if !ok || len(values) != 2 {
+			return "", ErrInvalidBetweenValue
+		}
+		return fmt.Sprintf("%s %s %s AND %s", field, op, b.bind(values[0]), b.bind(values[1])), nil
+	case "IS NULL", "IS NOT NULL":
+		return fmt.Sprintf("%s %s", field, op), nil
+	case "IS", "IS NOT":
+		if c.Value == nil {
+			return fmt.Sprintf("%s %s NULL", field, op), nil
+		}
+	}
+	return fmt.Sprintf("%s %s %s", field, op, b.bind(c.Value)), nil
+}
+
+// sliceValues flattens any slice or array (except []byte, which is a single BLOB value)
+// into []interface{}. Returns false if value is not a list.
+func sliceValues(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case nil, []byte:
+		return nil, false
+	case []interface{}:
+		return v, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
 }
 
 // ToSelectString generates a complete SELECT SQL query string with WHERE, GROUP BY, ORDER BY,
@@ -257,43 +381,58 @@ func (c *Condition) ToWhereString() (string, []interface{}, error) {
 //   - []interface{}: Slice of values for the parameterized query
 //   - error: Validation error if table name, field name, or operator is invalid
 func (c *Condition) ToSelectString(tableName string) (string, []interface{}, error) {
+	return c.ToSelectStringDialect(defaultDialect, tableName)
+}
+
+// ToSelectStringDialect is ToSelectString rendered for the given dialect
+// (placeholders and LIMIT/OFFSET syntax).
+func (c *Condition) ToSelectStringDialect(d Dialect, tableName string) (string, []interface{}, error) {
 	// Security: Validate table name to prevent SQL injection
 	if err := ValidateTableName(tableName); err != nil {
 		return "", nil, err
 	}
 
-	whereClause, values, err := c.ToWhereString()
+	b := newSQLBuilder(d)
+	b.allowTable(tableName, "")
+	whereClause, err := c.writeWhere(b)
 	if err != nil {
 		return "", nil, err
 	}
+	tableName = b.ident(tableName)
 
+	orderFields, err := b.writeOrderBy(c.OrderBy)
+	if err != nil {
+		return "", nil, err
+	}
+	orderTerms, err := b.writeOrderTerms(c.OrderTerms)
+	if err != nil {
+		return "", nil, err
+	}
 	orderClause := ""
-	if len(c.OrderBy) > 0 {
-		orderClause = "ORDER BY " + strings.Join(c.OrderBy, ", ")
+	if orderFields = append(orderFields, orderTerms...); len(orderFields) > 0 {
+		orderClause = "ORDER BY " + strings.Join(orderFields, ", ")
 	}
 
+	groupFields, err := b.writeColumns(c.GroupBy)
+	if err != nil {
+		return "", nil, err
+	}
 	groupClause := ""
-	if len(c.GroupBy) > 0 {
-		groupClause = "GROUP BY " + strings.Join(c.GroupBy, ", ")
+	if len(groupFields) > 0 {
+		groupClause = "GROUP BY " + strings.Join(groupFields, ", ")
 	}
 
 	// if offset has value but limit is not, then use default limit
 	if c.Offset > 0 && c.Limit < 1 {
 		c.Limit = DEFAULT_PAGINATION_LIMIT
 	}
-	limitClause := ""
-	if c.Limit > 0 {
-		limitClause = fmt.Sprintf("LIMIT %d", c.Limit)
+	limitClause := b.dialect.LimitOffset(c.Limit, c.Offset)
 
-		if c.Offset > 0 {
-			limitClause += fmt.Sprintf(" OFFSET %d", c.Offset)
-		}
-	}
 	// If there is no WHERE statement, just do the order and groupby
 	if strings.TrimSpace(whereClause) == "" {
-		return fmt.Sprintf("SELECT * FROM %s %s %s %s", tableName, groupClause, orderClause, limitClause), values, nil
+		return fmt.Sprintf("SELECT * FROM %s %s %s %s", tableName, groupClause, orderClause, limitClause), b.args, nil
 	}
-	return fmt.Sprintf("SELECT * FROM %s WHERE %s %s %s %s", tableName, whereClause, groupClause, orderClause, limitClause), values, nil
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s %s %s %s", tableName, whereClause, groupClause, orderClause, limitClause), b.args, nil
 }
 
 // PrintDebug prints debug information about a database schema object.
@@ -314,12 +453,13 @@ func (s SchemaStruct) PrintDebug(sql bool) {
 }
 
 // ValidateTableName validates a table name to prevent SQL injection.
-// It checks that the name is not empty and contains only alphanumeric characters and underscores.
-// The name must start with a letter or underscore.
+// It checks that the name is not empty and is an identifier made of alphanumeric characters
+// and underscores (starting with a letter or underscore), optionally schema-qualified.
+// Each part may also be double-quoted, e.g. "Order Items", as long as it has no embedded quote.
 //
 // Usage:
 //
-//	if err := ValidateTableName("users"); err != nil {
+//	if err := ValidateTableName("public.users"); err != nil {
 //	    return err
 //	}
 //
@@ -328,19 +468,19 @@ func ValidateTableName(tableName string) error {
 	if tableName == "" {
 		return ErrEmptyTableName
 	}
-	if !sqlIdentifierRegex.MatchString(tableName) {
+	if !sqlTableNameRegex.MatchString(tableName) {
 		return ErrInvalidTableName
 	}
 	return nil
 }
 
 // ValidateFieldName validates a field/column name to prevent SQL injection.
-// It checks that the name contains only alphanumeric characters and underscores.
-// The name must start with a letter or underscore.
+// Same rules as ValidateTableName, with up to three parts: column, table.column
+// or schema.table.column.
 //
 // Usage:
 //
-//	if err := ValidateFieldName("user_id"); err != nil {
+//	if err := ValidateFieldName("users.user_id"); err != nil {
 //	    return err
 //	}
 //
@@ -349,12 +489,20 @@ func ValidateFieldName(fieldName string) error {
 	if fieldName == "" {
 		return nil // Empty field names are allowed in nested conditions
 	}
-	if !sqlIdentifierRegex.MatchString(fieldName) {
+	if !sqlFieldNameRegex.MatchString(fieldName) {
 		return ErrInvalidFieldName
 	}
 	return nil
 }
 
+// ValidateAlias validates a table alias or CTE name, which must be a single bare identifier
+func ValidateAlias(alias string) error {
+	if !sqlIdentifierRegex.MatchString(alias) {
+		return ErrInvalidAlias
+	}
+	return nil
+}
+
 // ValidateOperator validates a SQL operator against a whitelist to prevent SQL injection.
 // It checks if the operator is in the allowed list of safe SQL operators.
 //
@@ -419,29 +567,43 @@ type CommonTableExpression struct {
 
 // ToSQL converts a CTE to its SQL representation
 func (cte *CommonTableExpression) ToSQL() (string, []interface{}, error) {
+	return cte.ToSQLDialect(defaultDialect)
+}
+
+// ToSQLDialect converts a CTE to SQL for the given dialect
+func (cte *CommonTableExpression) ToSQLDialect(d Dialect) (string, []interface{}, error) {
+	b := newSQLBuilder(d)
+	sql, err := cte.writeSQL(b)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, b.args, nil
+}
+
+// writeSQL renders the CTE definition, binding values into the builder
+func (cte *CommonTableExpression) writeSQL(b *sqlBuilder) (string, error) {
 	// Validate CTE name
-	if err := ValidateTableName(cte.Name); err != nil {
-		return "", nil, fmt.Errorf("invalid CTE name: %w", err)
+	if err := ValidateAlias(cte.Name); err != nil {
+		return "", fmt.Errorf("invalid CTE name: %w", err)
 	}
 
-	var values []interface{}
 	var querySQL string
 	var err error
 
 	// Use structured query if provided, otherwise use raw SQL
 	if cte.Query != nil {
-		querySQL, values, err = cte.Query.ToSQL()
+		querySQL, err = cte.Query.writeSQL(b, false)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to build CTE query: %w", err)
+			return "", fmt.Errorf("failed to build CTE query: %w", err)
 		}
 	} else if cte.RawSQL != "" {
 		querySQL = cte.RawSQL
 	} else {
-		return "", nil, fmt.Errorf("CTE must have either Query or RawSQL defined")
+		return "", fmt.Errorf("CTE must have either Query or RawSQL defined")
 	}
 
 	// Build CTE definition
-	cteSQL := cte.Name
+	cteSQL := b.ident(cte.Name)
 
 	// Add column list if specified
 	if len(cte.Columns) > 0 {
@@ -450,7 +612,7 @@ func (cte *CommonTableExpression) ToSQL() (string, []interface{}, error) {
 
 	cteSQL += " AS (" + querySQL + ")"
 
-	return cteSQL, values, nil
+	return cteSQL, nil
 }
 
 // ComplexQuery represents a complex SQL query structure that supports:
@@ -461,6 +623,11 @@ func (cte *CommonTableExpression) ToSQL() (string, []interface{}, error) {
 // - ORDER BY, LIMIT, OFFSET
 // - DISTINCT, CTEs (structured and raw), and subqueries
 //
+// OrderBy and GroupBy are validated like condition fields. Select and Having are the
+// historical raw SQL strings and are deprecated: they are spliced into the statement
+// verbatim, so they must never carry client input (decoded JSON included). Use
+// SelectTerms/HavingCondition for validated input, SelectExprs/HavingExpr for trusted SQL.
+//
 // Example usage:
 //
 //	query := ComplexQuery{
@@ -480,19 +647,29 @@ func (cte *CommonTableExpression) ToSQL() (string, []interface{}, error) {
 //	    Limit:     10,
 //	}
 type ComplexQuery struct {
-	Select    []string                 `json:"select,omitempty"`     // Fields to select (default: ["*"])
-	Distinct  bool                     `json:"distinct,omitempty"`   // Add DISTINCT keyword
-	From      string                   `json:"from"`                 // Main table name (required)
-	FromAlias string                   `json:"from_alias,omitempty"` // Alias for main table
-	Joins     []Join                   `json:"joins,omitempty"`      // JOIN clauses
-	Where     *Condition               `json:"where,omitempty"`      // WHERE conditions
-	GroupBy   []string                 `json:"group_by,omitempty"`   // GROUP BY fields
-	Having    string                   `json:"having,omitempty"`     // HAVING clause (raw SQL)
-	OrderBy   []string                 `json:"order_by,omitempty"`   // ORDER BY fields
-	Limit     int                      `json:"limit,omitempty"`      // LIMIT value
-	Offset    int                      `json:"offset,omitempty"`     // OFFSET value
-	CTEs      []CommonTableExpression  `json:"ctes,omitempty"`       // Structured CTEs (recommended)
-	CTERaw    string                   `json:"cte_raw,omitempty"`    // Raw CTE string (for backward compatibility)
+	Select          []string                `json:"select,omitempty"`           // Deprecated: raw SQL spliced verbatim, never fill from client input; use SelectTerms/SelectExprs (default: ["*"])
+	Distinct        bool                    `json:"distinct,omitempty"`         // Add DISTINCT keyword
+	From            string                  `json:"from"`                       // Main table name (required)
+	FromAlias       string                  `json:"from_alias,omitempty"`       // Alias for main table
+	Joins           []Join                  `json:"joins,omitempty"`            // JOIN clauses
+	Where           *Condition              `json:"where,omitempty"`            // WHERE conditions
+	GroupBy         []string                `json:"group_by,omitempty"`         // GROUP BY columns (validated like GroupByColumns)
+	Having          string                  `json:"having,omitempty"`           // Deprecated: raw SQL spliced verbatim, never fill from client input; use HavingCondition/HavingExpr
+	OrderBy         []string                `json:"order_by,omitempty"`         // ORDER BY entries, "column [ASC|DESC] [NULLS FIRST|LAST]" (validated)
+	SelectExprs     []*Expression           `json:"-"`                          // Trusted expressions appended to Select (Go code only, never decoded)
+	HavingCondition *Condition              `json:"having_condition,omitempty"` // Parameterized HAVING, fields may be aggregates like COUNT(orders.id)
+	HavingExpr      *Expression             `json:"-"`                          // Trusted HAVING expression, ANDed with Having (Go code only, never decoded)
+	OrderByExprs    []*Expression           `json:"-"`                          // Trusted expressions appended to OrderBy (Go code only, never decoded)
+	SelectTerms     []SelectTerm            `json:"select_terms,omitempty"`     // Validated SELECT entries, appended after Select
+	GroupByColumns  []string                `json:"group_by_columns,omitempty"` // Validated GROUP BY columns, appended after GroupBy
+	OrderTerms      []OrderTerm             `json:"order_terms,omitempty"`      // Validated ORDER BY entries, appended after OrderBy
+	Limit           int                     `json:"limit,omitempty"`            // LIMIT value
+	Offset          int                     `json:"offset,omitempty"`           // OFFSET value
+	CTEs            []CommonTableExpression `json:"ctes,omitempty"`             // Structured CTEs (recommended)
+	CTERaw          string                  `json:"cte_raw,omitempty"`          // Raw CTE string (for backward compatibility)
+	Compound        *CompoundQuery          `json:"compound,omitempty"`         // UNION/INTERSECT/EXCEPT of other queries, replaces the SELECT (only CTEs may be combined with it)
+	WindowTerms     []WindowTerm            `json:"window_terms,omitempty"`     // Window functions, appended after SelectTerms
+	Windows         []NamedWindow           `json:"windows,omitempty"`          // Named windows (WINDOW clause)
 }
 
 // ToSQL converts a ComplexQuery to a SQL query string with parameterized values.
@@ -503,42 +680,77 @@ type ComplexQuery struct {
 //   - []interface{}: Values for the parameterized query
 //   - error: Validation error if any field is invalid
 func (cq *ComplexQuery) ToSQL() (string, []interface{}, error) {
+	return cq.ToSQLDialect(defaultDialect)
+}
+
+// ToSQLDialect converts a ComplexQuery to SQL for the given dialect
+// (placeholders and LIMIT/OFFSET syntax).
+func (cq *ComplexQuery) ToSQLDialect(d Dialect) (string, []interface{}, error) {
+	b := newSQLBuilder(d)
+	sql, err := cq.writeSQL(b, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, b.args, nil
+}
+
+// writeSQL renders the query, binding values into the builder. A correlated query
+// (subquery in a condition) may also qualify columns with the enclosing query's tables.
+func (cq *ComplexQuery) writeSQL(b *sqlBuilder, correlated bool) (string, error) {
+	if cq.Compound != nil {
+		return cq.writeCompoundSQL(b, correlated)
+	}
+
 	var queryParts []string
-	var values []interface{}
 
 	// Security: Validate main table name
 	if err := ValidateTableName(cq.From); err != nil {
-		return "", nil, err
+		return "", err
+	}
+	if cq.FromAlias != "" {
+		if err := ValidateAlias(cq.FromAlias); err != nil {
+			return "", err
+		}
 	}
 
 	// CTE (WITH clause) - added first if present
-	// Support both structured CTEs and raw CTE string
-	if len(cq.CTEs) > 0 {
-		cteStrings := make([]string, 0, len(cq.CTEs))
-		recursive := false
-
-		for _, cte := range cq.CTEs {
-			cteSQL, cteValues, err := cte.ToSQL()
-			if err != nil {
-				return "", nil, fmt.Errorf("failed to build CTE: %w", err)
-			}
-			cteStrings = append(cteStrings, cteSQL)
-			values = append(values, cteValues...)
+	withClause, err := cq.writeWith(b)
+	if err != nil {
+		return "", err
+	}
+	if withClause != "" {
+		queryParts = append(queryParts, withClause)
+	}
 
-			if cte.Recursive {
-				recursive = true
-			}
+	// Columns of this query may only be qualified with its own tables/aliases
+	// (plus the enclosing ones when correlated), CTE queries rendered above have their own scope
+	outerQualifiers, outerAggregates := b.qualifiers, b.aggregates
+	defer func() { b.qualifiers, b.aggregates = outerQualifiers, outerAggregates }()
+	b.aggregates = false
+	checked := true
+	switch {
+	case !correlated:
+		b.qualifiers = make(map[string]bool)
+	case outerQualifiers == nil:
+		// the enclosing statement does not track its tables (e.g. a plain WHERE), neither can we
+		b.qualifiers = nil
+		checked = false
+	default:
+		b.qualifiers = make(map[string]bool, len(outerQualifiers))
+		for k := range outerQualifiers {
+			b.qualifiers[k] = true
 		}
-
-		withClause := "WITH "
-		if recursive {
-			withClause = "WITH RECURSIVE "
+	}
+	if checked {
+		b.allowTable(cq.From, cq.FromAlias)
+		for _, join := range cq.Joins {
+			b.allowTable(join.Table, join.Alias)
 		}
-		withClause += strings.Join(cteStrings, ", ")
-		queryParts = append(queryParts, withClause)
-	} else if cq.CTERaw != "" {
-		// Fallback to raw CTE string for backward compatibility
-		queryParts = append(queryParts, cq.CTERaw)
+	}
+
+	windowNames, err := windowKeys(cq.Windows)
+	if err != nil {
+		return "", fmt.Errorf("invalid WINDOW clause: %w", err)
 	}
 
 	// SELECT clause
@@ -546,17 +758,41 @@ func (cq *ComplexQuery) ToSQL() (string, []interface{}, error) {
 	if cq.Distinct {
 		selectClause += " DISTINCT"
 	}
-	if len(cq.Select) == 0 {
+	selectFields := append([]string(nil), cq.Select...)
+	b.aggregates = true
+	for _, term := range cq.SelectTerms {
+		termSQL, err := b.writeSelectTerm(term)
+		if err != nil {
+			return "", fmt.Errorf("failed to build SELECT term: %w", err)
+		}
+		selectFields = append(selectFields, termSQL)
+	}
+	for _, term := range cq.WindowTerms {
+		termSQL, err := b.writeWindowTerm(term, windowNames)
+		if err != nil {
+			return "", fmt.Errorf("failed to build window function: %w", err)
+		}
+		selectFields = append(selectFields, termSQL)
+	}
+	b.aggregates = false
+	for _, expr := range cq.SelectExprs {
+		exprSQL, err := expr.writeExpr(b)
+		if err != nil {
+			return "", fmt.Errorf("failed to build SELECT expression: %w", err)
+		}
+		selectFields = append(selectFields, exprSQL)
+	}
+	if len(selectFields) == 0 {
 		selectClause += " *"
 	} else {
-		selectClause += " " + strings.Join(cq.Select, ", ")
+		selectClause += " " + strings.Join(selectFields, ", ")
 	}
 	queryParts = append(queryParts, selectClause)
 
 	// FROM clause
-	fromClause := "FROM " + cq.From
+	fromClause := "FROM " + b.ident(cq.From)
 	if cq.FromAlias != "" {
-		fromClause += " AS " + cq.FromAlias
+		fromClause += " AS " + b.ident(cq.FromAlias)
 	}
 	queryParts = append(queryParts, fromClause)
 
@@ -564,12 +800,16 @@ func (cq *ComplexQuery) ToSQL() (string, []interface{}, error) {
 	for _, join := range cq.Joins {
 		// Security: Validate join table name
 		if err := ValidateTableName(join.Table); err != nil {
-			return "", nil, fmt.Errorf("invalid join table: %w", err)
+			return "", fmt.Errorf("invalid join table: %w", err)
 		}
-
-		joinClause := string(join.Type) + " " + join.Table
 		if join.Alias != "" {
-			joinClause += " AS " + join.Alias
+			if err := ValidateAlias(join.Alias); err != nil {
+				return "", fmt.Errorf("invalid join alias: %w", err)
+			}
+		}
+		joinClause := string(join.Type) + " " + b.ident(join.Table)
+		if join.Alias != "" {
+			joinClause += " AS " + b.ident(join.Alias)
 		}
 		if join.Condition != "" && join.Type != CrossJoin {
 			joinClause += " ON " + join.Condition
@@ -579,41 +819,123 @@ func (cq *ComplexQuery) ToSQL() (string, []interface{}, error) {
 
 	// WHERE clause
 	if cq.Where != nil {
-		whereClause, whereValues, err := cq.Where.ToWhereString()
+		whereClause, err := cq.Where.writeWhere(b)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to build WHERE clause: %w", err)
+			return "", fmt.Errorf("failed to build WHERE clause: %w", err)
 		}
 		if whereClause != "" {
 			queryParts = append(queryParts, "WHERE "+whereClause)
-			values = append(values, whereValues...)
 		}
 	}
 
 	// GROUP BY clause
-	if len(cq.GroupBy) > 0 {
-		queryParts = append(queryParts, "GROUP BY "+strings.Join(cq.GroupBy, ", "))
+	groupFields, err := b.writeColumns(append(append([]string(nil), cq.GroupBy...), cq.GroupByColumns...))
+	if err != nil {
+		return "", fmt.Errorf("failed to build GROUP BY: %w", err)
+	}
+	if len(groupFields) > 0 {
+		queryParts = append(queryParts, "GROUP BY "+strings.Join(groupFields, ", "))
 	}
 
 	// HAVING clause
+	var havingParts []string
 	if cq.Having != "" {
-		queryParts = append(queryParts, "HAVING "+cq.Having)
+		havingParts = append(havingParts, cq.Having)
+	}
+	if cq.HavingCondition != nil {
+		b.aggregates = true
+		havingSQL, err := cq.HavingCondition.writeWhere(b)
+		b.aggregates = false
+		if err != nil {
+			return "", fmt.Errorf("failed to build HAVING condition: %w", err)
+		}
+		if havingSQL != "" {
+			havingParts = append(havingParts, havingSQL)
+		}
+	}
+	if cq.HavingExpr != nil {
+		exprSQL, err := cq.HavingExpr.writeExpr(b)
+		if err != nil {
+			return "", fmt.Errorf("failed to build HAVING expression: %w", err)
+		}
+		havingParts = append(havingParts, exprSQL)
+	}
+	if len(havingParts) == 1 {
+		queryParts = append(queryParts, "HAVING "+havingParts[0])
+	} else if len(havingParts) > 1 {
+		queryParts = append(queryParts, "HAVING ("+strings.Join(havingParts, ") AND (")+")")
+	}
+
+	// WINDOW clause
+	b.aggregates = true
+	windowClause, err := b.writeWindows(cq.Windows, windowNames)
+	b.aggregates = false
+	if err != nil {
+		return "", fmt.Errorf("failed to build WINDOW clause: %w", err)
+	}
+	if windowClause != "" {
+		queryParts = append(queryParts, windowClause)
 	}
 
 	// ORDER BY clause
-	if len(cq.OrderBy) > 0 {
-		queryParts = append(queryParts, "ORDER BY "+strings.Join(cq.OrderBy, ", "))
+	b.aggregates = true
+	orderFields, err := b.writeOrderBy(cq.OrderBy)
+	if err == nil {
+		var orderTerms []string
+		orderTerms, err = b.writeOrderTerms(cq.OrderTerms)
+		orderFields = append(orderFields, orderTerms...)
+	}
+	b.aggregates = false
+	if err != nil {
+		return "", fmt.Errorf("failed to build ORDER BY: %w", err)
+	}
+	for _, expr := range cq.OrderByExprs {
+		exprSQL, err := expr.writeExpr(b)
+		if err != nil {
+			return "", fmt.Errorf("failed to build ORDER BY expression: %w", err)
+		}
+		orderFields = append(orderFields, exprSQL)
+	}
+	if len(orderFields) > 0 {
+		queryParts = append(queryParts, "ORDER BY "+strings.Join(orderFields, ", "))
 	}
 
 	// LIMIT and OFFSET
 	if cq.Offset > 0 && cq.Limit < 1 {
 		cq.Limit = DEFAULT_PAGINATION_LIMIT
 	}
-	if cq.Limit > 0 {
-		queryParts = append(queryParts, fmt.Sprintf("LIMIT %d", cq.Limit))
-	}
-	if cq.Offset > 0 {
-		queryParts = append(queryParts, fmt.Sprintf("OFFSET %d", cq.Offset))
+	if limitClause := b.dialect.LimitOffset(cq.Limit, cq.Offset); limitClause != "" {
+		queryParts = append(queryParts, limitClause)
 	}
 
-	return strings.Join(queryParts, " "), values, nil
+	return strings.Join(queryParts, " "), nil
+}
+
+// writeWith renders the WITH clause of the query, empty when it has no CTEs.
+// Support both structured CTEs and raw CTE string
+func (cq *ComplexQuery) writeWith(b *sqlBuilder) (string, error) {
+	if len(cq.CTEs) > 0 {
+		cteStrings := make([]string, 0, len(cq.CTEs))
+		recursive := false
+
+		for _, cte := range cq.CTEs {
+			cteSQL, err := cte.writeSQL(b)
+			if err != nil {
+				return "", fmt.Errorf("failed to build CTE: %w", err)
+			}
+			cteStrings = append(cteStrings, cteSQL)
+
+			if cte.Recursive {
+				recursive = true
+			}
+		}
+
+		withClause := "WITH "
+		if recursive {
+			withClause = "WITH RECURSIVE "
+		}
+		return withClause + strings.Join(cteStrings, ", "), nil
+	}
+	// Fallback to raw CTE string for backward compatibility
+	return cq.CTERaw, nil
 }
@@ -0,0 +1,339 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestConditionOperators checks the rendering of every operator family for both dialects
+func TestConditionOperators(t *testing.T) {
+	tests := []struct {
+		name         string
+		condition    Condition
+		wantSQLite   string
+		wantPostgres string
+		wantValues   []interface{}
+		wantErr      error
+	}{
+		{
+			name:         "simple equality",
+			condition:    Condition{Field: "name", Operator: "=", Value: "alice"},
+			wantSQLite:   "name = ?",
+			wantPostgres: "name = $1",
+			wantValues:   []interface{}{"alice"},
+		},
+		{
+			name:         "IN with interface slice",
+			condition:    Condition{Field: "id", Operator: "IN", Value: []interface{}{1, 2, 3}},
+			wantSQLite:   "id IN (?, ?, ?)",
+			wantPostgres: "id IN ($1, $2, $3)",
+			wantValues:   []interface{}{1, 2, 3},
+		},
+		{
+			name:         "IN with typed slice",
+			condition:    Condition{Field: "status", Operator: "in", Value: []string{"new", "paid"}},
+			wantSQLite:   "status IN (?, ?)",
+			wantPostgres: "status IN ($1, $2)",
+			wantValues:   []interface{}{"new", "paid"},
+		},
+		{
+			name:         "NOT IN with array",
+			condition:    Condition{Field: "id", Operator: "NOT IN", Value: [2]int{7, 8}},
+			wantSQLite:   "id NOT IN (?, ?)",
+			wantPostgres: "id NOT IN ($1, $2)",
+			wantValues:   []interface{}{7, 8},
+		},
+		{
+			name:         "IN with scalar",
+			condition:    Condition{Field: "id", Operator: "IN", Value: 5},
+			wantSQLite:   "id IN (?)",
+			wantPostgres: "id IN ($1)",
+			wantValues:   []interface{}{5},
+		},
+		{
+			name:         "IN with empty slice matches nothing",
+			condition:    Condition{Field: "id", Operator: "IN", Value: []int{}},
+			wantSQLite:   "1 = 0",
+			wantPostgres: "1 = 0",
+		},
+		{
+			name:         "NOT IN with empty slice matches everything",
+			condition:    Condition{Field: "id", Operator: "NOT IN", Value: []string{}},
+			wantSQLite:   "1 = 1",
+			wantPostgres: "1 = 1",
+		},
+		{
+			name:         "IN with bytes is a single value",
+			condition:    Condition{Field: "hash", Operator: "IN", Value: []byte("ab")},
+			wantSQLite:   "hash IN (?)",
+			wantPostgres: "hash IN ($1)",
+			wantValues:   []interface{}{[]byte("ab")},
+		},
+		{
+			name:         "BETWEEN",
+			condition:    Condition{Field: "age", Operator: "BETWEEN", Value: []int{18, 65}},
+			wantSQLite:   "age BETWEEN ? AND ?",
+			wantPostgres: "age BETWEEN $1 AND $2",
+			wantValues:   []interface{}{18, 65},
+		},
+		{
+			name:         "NOT BETWEEN",
+			condition:    Condition{Field: "price", Operator: "not between", Value: []interface{}{1.5, 9.5}},
+			wantSQLite:   "price NOT BETWEEN ? AND ?",
+			wantPostgres: "price NOT BETWEEN $1 AND $2",
+			wantValues:   []interface{}{1.5, 9.5},
+		},
+		{
+			name:      "BETWEEN with one value",
+			condition: Condition{Field: "age", Operator: "BETWEEN", Value: []int{18}},
+			wantErr:   ErrInvalidBetweenValue,
+		},
+		{
+			name:      "BETWEEN with scalar",
+			condition: Condition{Field: "age", Operator: "BETWEEN", Value: 18},
+			wantErr:   ErrInvalidBetweenValue,
+		},
+		{
+			name:         "IS NULL ignores value",
+			condition:    Condition{Field: "deleted_at", Operator: "IS NULL", Value: "ignored"},
+			wantSQLite:   "deleted_at IS NULL",
+			wantPostgres: "deleted_at IS NULL",
+		},
+		{
+			name:         "IS NOT NULL",
+			condition:    Condition{Field: "deleted_at", Operator: "is not null"},
+			wantSQLite:   "deleted_at IS NOT NULL",
+			wantPostgres: "deleted_at IS NOT NULL",
+		},
+		{
+			name:         "IS with nil value",
+			condition:    Condition{Field: "parent_id", Operator: "IS"},
+			wantSQLite:   "parent_id IS NULL",
+			wantPostgres: "parent_id IS NULL",
+		},
+		{
+			name:         "IS NOT with value",
+			condition:    Condition{Field: "active", Operator: "IS NOT", Value: true},
+			wantSQLite:   "active IS NOT ?",
+			wantPostgres: "active IS NOT $1",
+			wantValues:   []interface{}{true},
+		},
+		{
+			name: "nested placeholders are numbered in order",
+			condition: Condition{Logic: "OR", Nested: []Condition{
+				{Field: "id", Operator: "IN", Value: []int{1, 2}},
+				{Field: "age", Operator: "BETWEEN", Value: []int{3, 4}},
+				{Field: "deleted_at", Operator: "IS NULL"},
+				{Field: "name", Operator: "=", Value: "x"},
+			}},
+			wantSQLite:   "(id IN (?, ?)) OR (age BETWEEN ? AND ?) OR (deleted_at IS NULL) OR (name = ?)",
+			wantPostgres: "(id IN ($1, $2)) OR (age BETWEEN $3 AND $4) OR (deleted_at IS NULL) OR (name = $5)",
+			wantValues:   []interface{}{1, 2, 3, 4, "x"},
+		},
+		{
+			name:      "operator without field",
+			condition: Condition{Operator: "=", Value: 1},
+			wantErr:   ErrEmptyConditionField,
+		},
+		{
+			name:      "invalid operator",
+			condition: Condition{Field: "id", Operator: "; DROP", Value: 1},
+			wantErr:   ErrInvalidOperator,
+		},
+	}
+
+	dialects := []struct {
+		dialect Dialect
+		want    func(i int) string
+	}{
+		{SQLiteDialect{}, func(i int) string { return tests[i].wantSQLite }},
+		{PostgreSQLDialect{}, func(i int) string { return tests[i].wantPostgres }},
+	}
+
+	for i, tt := range tests {
+		for _, d := range dialects {
+			t.Run(tt.name+"/"+d.dialect.Name(), func(t *testing.T) {
+				got, values, err := tt.condition.ToWhereStringDialect(d.dialect)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if want := d.want(i); got != want {
+					t.Errorf("Expected %q, got %q", want, got)
+				}
+				if len(values) != 0 || len(tt.wantValues) != 0 {
+					if !reflect.DeepEqual(values, tt.wantValues) {
+						t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestToWhereStringDefaultDialect makes sure the dialect-less API keeps the ? placeholders
+func TestToWhereStringDefaultDialect(t *testing.T) {
+	c := Condition{Field: "id", Operator: "IN", Value: []int{1, 2}}
+	got, values, err := c.ToWhereString()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "id IN (?, ?)" {
+		t.Errorf("Expected %q, got %q", "id IN (?, ?)", got)
+	}
+	if len(values) != 2 {
+		t.Errorf("Expected 2 values, got %d", len(values))
+	}
+}
+
+// TestValidateIdentifiers checks qualified and quoted names are accepted while injection attempts are not
+func TestValidateIdentifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		fieldErr error
+		tableErr error
+	}{
+		{"simple", "users", nil, nil},
+		{"qualified", "users.status", nil, nil},
+		{"schema qualified column", "public.users.status", nil, ErrInvalidTableName},
+		{"quoted", `"Order Items"`, nil, nil},
+		{"quoted part", `public."Order Items"`, nil, nil},
+		{"too many parts", "a.b.c.d", ErrInvalidFieldName, ErrInvalidTableName},
+		{"trailing dot", "users.", ErrInvalidFieldName, ErrInvalidTableName},
+		{"leading digit", "1users", ErrInvalidFieldName, ErrInvalidTableName},
+		{"injection", "users; DROP TABLE users", ErrInvalidFieldName, ErrInvalidTableName},
+		{"comment", "users.id--", ErrInvalidFieldName, ErrInvalidTableName},
+		{"embedded quote", `"a"b"`, ErrInvalidFieldName, ErrInvalidTableName},
+		{"quote breakout", `"a"; DROP TABLE x; --"`, ErrInvalidFieldName, ErrInvalidTableName},
+		{"empty quoted", `""`, ErrInvalidFieldName, ErrInvalidTableName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFieldName(tt.input); !errors.Is(err, tt.fieldErr) {
+				t.Errorf("ValidateFieldName(%q): expected %v, got %v", tt.input, tt.fieldErr, err)
+			}
+			if err := ValidateTableName(tt.input); !errors.Is(err, tt.tableErr) {
+				t.Errorf("ValidateTableName(%q): expected %v, got %v", tt.input, tt.tableErr, err)
+			}
+		})
+	}
+}
+
+// TestFormatIdentifier checks reserved words and mixed case names are quoted per dialect
+func TestFormatIdentifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		want    string
+	}{
+		{"plain", SQLiteDialect{}, "users.status", "users.status"},
+		{"reserved word", SQLiteDialect{}, "order", `"order"`},
+		{"reserved qualified", PostgreSQLDialect{}, "orders.user", `orders."user"`},
+		{"reserved any case", PostgreSQLDialect{}, "Group", `"Group"`},
+		{"already quoted", PostgreSQLDialect{}, `"Order Items".id`, `"Order Items".id`},
+		{"mixed case sqlite", SQLiteDialect{}, "createdAt", "createdAt"},
+		{"mixed case postgres", PostgreSQLDialect{}, "createdAt", "createdAt"},
+		{"mixed case postgres quoted", PostgreSQLDialect{QuoteMixedCase: true}, "public.createdAt", `public."createdAt"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatIdentifier(tt.dialect, tt.input); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestQualifiedColumns checks qualified WHERE columns against the tables and aliases of the query
+func TestQualifiedColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ComplexQuery
+		want    string
+		wantErr error
+	}{
+		{
+			name: "table qualified",
+			query: ComplexQuery{
+				From:  "users",
+				Joins: []Join{{Type: LeftJoin, Table: "orders", Condition: "users.id = orders.user_id"}},
+				Where: &Condition{Field: "users.status", Operator: "=", Value: "active"},
+			},
+			want: "SELECT * FROM users LEFT JOIN orders ON users.id = orders.user_id WHERE users.status = $1",
+		},
+		{
+			name: "join alias",
+			query: ComplexQuery{
+				From:      "users",
+				FromAlias: "u",
+				Joins:     []Join{{Type: InnerJoin, Table: "orders", Alias: "o", Condition: "u.id = o.user_id"}},
+				Where: &Condition{Logic: "AND", Nested: []Condition{
+					{Field: "u.status", Operator: "=", Value: "active"},
+					{Field: "o.total", Operator: ">", Value: 10},
+				}},
+			},
+			want: "SELECT * FROM users AS u INNER JOIN orders AS o ON u.id = o.user_id WHERE (u.status = $1) AND (o.total > $2)",
+		},
+		{
+			name: "schema qualified table",
+			query: ComplexQuery{
+				From:  "public.users",
+				Where: &Condition{Field: "users.user", Operator: "=", Value: 1},
+			},
+			want: `SELECT * FROM public.users WHERE users."user" = $1`,
+		},
+		{
+			name: "table name hidden by alias",
+			query: ComplexQuery{
+				From:      "users",
+				FromAlias: "u",
+				Where:     &Condition{Field: "users.status", Operator: "=", Value: "active"},
+			},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name: "unknown table",
+			query: ComplexQuery{
+				From:  "users",
+				Where: &Condition{Field: "orders.total", Operator: ">", Value: 10},
+			},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name: "invalid alias",
+			query: ComplexQuery{
+				From:      "users",
+				FromAlias: "u; DROP TABLE users",
+			},
+			wantErr: ErrInvalidAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := tt.query.ToSQLDialect(PostgreSQLDialect{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
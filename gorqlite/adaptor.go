@@ -66,3 +66,22 @@ func WriteResultsToBasicSQLResults(res []gorqlite.WriteResult) []orm.BasicSQLRes
 	}
 	return ret
 }
+
+// Convert every row of a QueryResult to DBRecords, an empty result returns no records
+func QueryResultToDBRecords(qr gorqlite.QueryResult, tableName string) ([]orm.DBRecord, error) {
+	if qr.Err != nil {
+		return nil, qr.Err
+	}
+	records := make([]orm.DBRecord, 0, qr.NumRows())
+	for qr.Next() {
+		result, err := qr.Map()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, orm.DBRecord{
+			TableName: tableName,
+			Data:      result,
+		})
+	}
+	return records, nil
+}
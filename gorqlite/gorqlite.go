@@ -1,6 +1,7 @@
 package gorqlite
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -29,6 +30,9 @@ type RQLiteDB struct {
 	conn   *gorqlite.Connection
 }
 
+// Ensure RQLiteDB satisfies both the plain and the context-aware interfaces
+var _ orm.DatabaseContext = RQLiteDB{}
+
 // This is to "connect" to the DB. Basically we use gorqlite to do so.
 // If using credential, then it will use that as well and return error if it's not matched
 func NewDatabase(config RqliteConfig) (RQLiteDB, error) {
@@ -61,6 +65,11 @@ func (db RQLiteDB) Peers() ([]string, error) {
 	return db.conn.Peers()
 }
 
+// Dialect returns the SQL dialect of RQLite (SQLite)
+func (db RQLiteDB) Dialect() orm.Dialect {
+	return orm.SQLiteDialect{}
+}
+
 // Get all the schema for the database, basically returns all the table that exists!
 func (db RQLiteDB) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruct {
 	// getTableAndView := "SELECT * FROM sqlite_master ORDER BY type,tbl_name, name"
@@ -88,7 +97,16 @@ func (db RQLiteDB) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruct {
 // Returns the status of the database, for Rqlite this will also return the peers and leaders
 // Can use this as ping-pong as well
 func (db RQLiteDB) Status() (orm.NodeStatusStruct, error) {
+	return db.StatusContext(context.Background())
+}
+
+// StatusContext is the context-aware variant of Status. gorqlite has no context-aware
+// Leader / Peers, so ctx is only checked before the calls.
+func (db RQLiteDB) StatusContext(ctx context.Context) (orm.NodeStatusStruct, error) {
 	var status orm.NodeStatusStruct
+	if err := ctx.Err(); err != nil {
+		return status, err
+	}
 	status.DBMS = "rqlite"
 	status.DBMSDriver = "gorqlite"
 	status.URL = db.Config.URL
@@ -128,8 +146,13 @@ func (db RQLiteDB) Status() (orm.NodeStatusStruct, error) {
 // -     conditions but only return 1
 // Select only 1 row, if multiple rows returned, it only takes the first one
 func (db RQLiteDB) SelectOne(tableName string) (orm.DBRecord, error) {
+	return db.SelectOneContext(context.Background(), tableName)
+}
+
+// SelectOneContext is the context-aware variant of SelectOne
+func (db RQLiteDB) SelectOneContext(ctx context.Context, tableName string) (orm.DBRecord, error) {
 	// el := metrics.StartTimeIt("", 0)
-	qr, err := db.conn.QueryOne("SELECT * FROM " + tableName)
+	qr, err := db.conn.QueryOneContext(ctx, "SELECT * FROM "+tableName)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -167,13 +190,18 @@ func (db RQLiteDB) SelectOne(tableName string) (orm.DBRecord, error) {
 // Select 1 row from a table passed in tableName with condition (read condition struct for usage)
 // Will return only 1 row of type DBRecord and error if any.
 func (db RQLiteDB) SelectOneWithCondition(tableName string, condition *orm.Condition) (orm.DBRecord, error) {
+	return db.SelectOneWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectOneWithConditionContext is the context-aware variant of SelectOneWithCondition
+func (db RQLiteDB) SelectOneWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) (orm.DBRecord, error) {
 	if condition != nil {
 		statement, err := ConditionToParameterized(tableName, condition)
 		if err != nil {
 			return orm.DBRecord{}, fmt.Errorf("failed to build query: %w", err)
 		}
 
-		qr, err := db.conn.QueryOneParameterized(statement)
+		qr, err := db.conn.QueryOneParameterizedContext(ctx, statement)
 		if err != nil {
 			return orm.DBRecord{}, err
 		}
@@ -194,12 +222,17 @@ func (db RQLiteDB) SelectOneWithCondition(tableName string, condition *orm.Condi
 			Data:      result,
 		}, nil
 	}
-	return db.SelectOne(tableName)
+	return db.SelectOneContext(ctx, tableName)
 }
 
 func (db RQLiteDB) SelectMany(tableName string) (orm.DBRecords, error) {
+	return db.SelectManyContext(context.Background(), tableName)
+}
+
+// SelectManyContext is the context-aware variant of SelectMany
+func (db RQLiteDB) SelectManyContext(ctx context.Context, tableName string) (orm.DBRecords, error) {
 	var records orm.DBRecords
-	qr, err := db.conn.QueryOne("SELECT * FROM " + tableName)
+	qr, err := db.conn.QueryOneContext(ctx, "SELECT * FROM "+tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -226,13 +259,18 @@ func (db RQLiteDB) SelectMany(tableName string) (orm.DBRecords, error) {
 
 // Select many rows (returned as []DBRecords) with condition.
 func (db RQLiteDB) SelectManyWithCondition(tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
+	return db.SelectManyWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectManyWithConditionContext is the context-aware variant of SelectManyWithCondition
+func (db RQLiteDB) SelectManyWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
 	var records []orm.DBRecord
 	statement, err := ConditionToParameterized(tableName, condition)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 	// simplelog.LogThis(statement.Query + ";" + fmt.Sprintln(statement.Arguments))
-	qr, err := db.conn.QueryOneParameterized(statement)
+	qr, err := db.conn.QueryOneParameterizedContext(ctx, statement)
 	if err != nil {
 		return nil, err
 	}
@@ -262,7 +300,12 @@ func (db RQLiteDB) SelectManyWithCondition(tableName string, condition *orm.Cond
 // Execute 1 raw sql statement, can be anything. Query, Update, Insert, etc
 // Combine the error from write function to result.Err
 func (db RQLiteDB) ExecOneSQL(sql string) orm.BasicSQLResult {
-	res, err := db.conn.WriteOne(sql)
+	return db.ExecOneSQLContext(context.Background(), sql)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (db RQLiteDB) ExecOneSQLContext(ctx context.Context, sql string) orm.BasicSQLResult {
+	res, err := db.conn.WriteOneContext(ctx, sql)
 	ret := WriteResultToBasicSQLResult(res)
 	if err != nil {
 		ret.Error = fmt.Errorf("failed to execute sql: %w", err)
@@ -272,7 +315,12 @@ func (db RQLiteDB) ExecOneSQL(sql string) orm.BasicSQLResult {
 
 // Execute 1 raw sql statement, can be anything. Query, Update, Insert, etc
 func (db RQLiteDB) ExecOneSQLParameterized(p orm.ParametereizedSQL) orm.BasicSQLResult {
-	res, err := db.conn.WriteOneParameterized(FromOneParameterizedSQL(p))
+	return db.ExecOneSQLParameterizedContext(context.Background(), p)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (db RQLiteDB) ExecOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) orm.BasicSQLResult {
+	res, err := db.conn.WriteOneParameterizedContext(ctx, FromOneParameterizedSQL(p))
 	ret := WriteResultToBasicSQLResult(res)
 	if err != nil {
 		ret.Error = fmt.Errorf("failed to execute parameterized sql: %w", err)
@@ -282,7 +330,12 @@ func (db RQLiteDB) ExecOneSQLParameterized(p orm.ParametereizedSQL) orm.BasicSQL
 
 // Execute many raw sql statement, can be anything. Query, Update, Insert, etc
 func (db RQLiteDB) ExecManySQL(sql []string) ([]orm.BasicSQLResult, error) {
-	res, err := db.conn.Write(sql)
+	return db.ExecManySQLContext(context.Background(), sql)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (db RQLiteDB) ExecManySQLContext(ctx context.Context, sql []string) ([]orm.BasicSQLResult, error) {
+	res, err := db.conn.WriteContext(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +344,12 @@ func (db RQLiteDB) ExecManySQL(sql []string) ([]orm.BasicSQLResult, error) {
 
 // Execute many raw sql statement, can be anything. Query, Update, Insert, etc
 func (db RQLiteDB) ExecManySQLParameterized(p []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
-	res, err := db.conn.WriteParameterized(FromManyParameterizedSQL(p))
+	return db.ExecManySQLParameterizedContext(context.Background(), p)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (db RQLiteDB) ExecManySQLParameterizedContext(ctx context.Context, p []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	res, err := db.conn.WriteParameterizedContext(ctx, FromManyParameterizedSQL(p))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute parameterized sql: %w", err)
 	}
@@ -304,6 +362,11 @@ func (db RQLiteDB) ExecManySQLParameterized(p []orm.ParametereizedSQL) ([]orm.Ba
 // the sql command but the API is succeed.
 // if false then we can get the error right away from the command
 func (db RQLiteDB) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.BasicSQLResult {
+	return db.InsertOneDBRecordContext(context.Background(), record, queue)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (db RQLiteDB) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, queue bool) orm.BasicSQLResult {
 	statement := DBRecordToInsertParameterized(&record)
 
 	var res orm.BasicSQLResult
@@ -311,12 +374,12 @@ func (db RQLiteDB) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.BasicS
 	// if this is queued then return rowAffected=1 and LastInsertID as the sequence number
 	if queue {
 		var seq int64
-		seq, err = db.conn.QueueOneParameterized(statement)
+		seq, err = db.conn.QueueOneParameterizedContext(ctx, statement)
 		res.LastInsertID = int(seq)
 		res.RowsAffected = 1
 	} else {
 		var r gorqlite.WriteResult // need to declare this so err can use parent's scope
-		r, err = db.conn.WriteOneParameterized(statement)
+		r, err = db.conn.WriteOneParameterizedContext(ctx, statement)
 		// ret := WriteResultToBasicSQLResult(res)
 		// record.Data["id"] = r.LastInsertID
 		res = WriteResultToBasicSQLResult(r)
@@ -332,6 +395,11 @@ func (db RQLiteDB) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.BasicS
 // When queue=true the query result is not used, it will return only 1 result
 // with LastInsertID = sequence number and RowsAffected as len(records)
 func (db RQLiteDB) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyDBRecordsContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (db RQLiteDB) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	var statements []gorqlite.ParameterizedStatement
 	// This is assuming that function is NEVER called with 0 array or nul
 	tableName := records[0].TableName
@@ -344,12 +412,12 @@ func (db RQLiteDB) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]or
 	// var ress []gorqlite.WriteResult
 	if queue {
 		var seq int64
-		seq, err = db.conn.QueueParameterized(statements)
+		seq, err = db.conn.QueueParameterizedContext(ctx, statements)
 		reses = append(reses, orm.BasicSQLResult{LastInsertID: int(seq), RowsAffected: len(records)})
 	} else {
 		var res []gorqlite.WriteResult // need to declare this so err can use parent's scope
 		// res, err = db.conn.WriteParameterized(statements)
-		res, err = db.conn.WriteParameterized(statements)
+		res, err = db.conn.WriteParameterizedContext(ctx, statements)
 		// NOTE: cannot put the last inserted ID back to records parameter because
 		//       golang slice/array is not in order!!
 		// for i, r := range res {
@@ -368,6 +436,11 @@ func (db RQLiteDB) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]or
 // When queue=true the query result is not used, instead the first array of
 // Result{LastInsertID : is the sequence number }
 func (db RQLiteDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyDBRecordsSameTableContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (db RQLiteDB) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	var reses []orm.BasicSQLResult
 	numRecs := len(records)
 	if numRecs == 0 {
@@ -378,12 +451,12 @@ func (db RQLiteDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue bo
 	var err error
 	if queue {
 		var seq int64
-		seq, err = db.conn.QueueParameterized(statements)
+		seq, err = db.conn.QueueParameterizedContext(ctx, statements)
 		// Use the first array of Result, using LastInsertID (int)
 		reses = append(reses, orm.BasicSQLResult{LastInsertID: int(seq), RowsAffected: numRecs})
 	} else {
 		var res []gorqlite.WriteResult
-		res, err = db.conn.WriteParameterized(statements)
+		res, err = db.conn.WriteParameterizedContext(ctx, statements)
 		reses = WriteResultsToBasicSQLResults(res)
 	}
 	if err != nil {
@@ -402,13 +475,22 @@ func (db RQLiteDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue bo
 //
 // Insert TableStruct to DB
 func (db RQLiteDB) InsertOneTableStruct(obj orm.TableStruct, queue bool) orm.BasicSQLResult {
+	return db.InsertOneTableStructContext(context.Background(), obj, queue)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct
+func (db RQLiteDB) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct, queue bool) orm.BasicSQLResult {
 	// fmt.Println("struct : ", obj)
-	record, err := orm.TableStructToDBRecord(obj)
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
 	// fmt.Println("record : ", record)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
-	return db.InsertOneDBRecord(record, queue)
+	result := db.InsertOneDBRecordContext(ctx, record, queue)
+	if result.Error == nil {
+		result.Error = orm.RunAfterInsert(ctx, obj)
+	}
+	return result
 	// statement := DBRecordToInsertParameterized(&record)
 
 	// _, err = db.conn.QueueOneParameterized(statement)
@@ -420,15 +502,24 @@ func (db RQLiteDB) InsertOneTableStruct(obj orm.TableStruct, queue bool) orm.Bas
 
 // Insert many table structs (in array) but use the DB records.
 func (db RQLiteDB) InsertManyTableStructs(objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyTableStructsContext(context.Background(), objs, queue)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs
+func (db RQLiteDB) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
 	var records orm.DBRecords
 	for _, obj := range objs {
-		record, err := orm.TableStructToDBRecord(obj)
+		record, err := orm.TableStructToInsertRecord(ctx, obj)
 		if err != nil {
 			return []orm.BasicSQLResult{}, err
 		}
 		records = append(records, record)
 	}
-	return db.InsertManyDBRecords(records, queue)
+	results, err := db.InsertManyDBRecordsContext(ctx, records, queue)
+	if err != nil {
+		return results, err
+	}
+	return results, orm.RunAfterInsertAll(ctx, objs)
 	// var statements []gorqlite.ParameterizedStatement
 	// // This is assuming that function is NEVER called with 0 array or nul
 	// tableName := objs[0].TableName()
@@ -446,3 +537,33 @@ func (db RQLiteDB) InsertManyTableStructs(objs []orm.TableStruct, queue bool) ([
 	// }
 	// return nil
 }
+
+// Update the columns in set on every row matching where.
+// An empty condition is refused unless allRows is true.
+func (db RQLiteDB) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return db.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
+
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (db RQLiteDB) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQL(tableName, set, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// Delete every row matching where.
+// An empty condition is refused unless allRows is true.
+func (db RQLiteDB) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return db.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (db RQLiteDB) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQL(tableName, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
@@ -0,0 +1,328 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	orm "github.com/medatechnology/simpleorm"
+	"github.com/rqlite/gorqlite"
+)
+
+// Select many rows with a ComplexQuery (JOINs, custom fields, GROUP BY, ...)
+func (db RQLiteDB) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRecord, error) {
+	return db.SelectManyComplexContext(context.Background(), query)
+}
+
+// SelectManyComplexContext is the context-aware variant of SelectManyComplex
+func (db RQLiteDB) SelectManyComplexContext(ctx context.Context, query *orm.ComplexQuery) ([]orm.DBRecord, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+	sql, values, err := query.ToSQLDialect(db.Dialect())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build complex query: %w", err)
+	}
+	return db.selectRecords(ctx, orm.SQLAndValuesToParameterized(sql, values), query.From)
+}
+
+// Select with a ComplexQuery that must return exactly one row
+func (db RQLiteDB) SelectOneComplex(query *orm.ComplexQuery) (orm.DBRecord, error) {
+	return db.SelectOneComplexContext(context.Background(), query)
+}
+
+// SelectOneComplexContext is the context-aware variant of SelectOneComplex
+func (db RQLiteDB) SelectOneComplexContext(ctx context.Context, query *orm.ComplexQuery) (orm.DBRecord, error) {
+	records, err := db.SelectManyComplexContext(ctx, query)
+	if err != nil {
+		return orm.DBRecord{}, err
+	}
+	return onlyOne(records)
+}
+
+// Select one page of the rows matching condition, with the total count of matching rows.
+// With opts.Consistent both statements go in one request, which gorqlite runs as a transaction.
+func (db RQLiteDB) SelectPage(tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	return db.SelectPageContext(context.Background(), tableName, condition, opts)
+}
+
+// SelectPageContext is the context-aware variant of SelectPage
+func (db RQLiteDB) SelectPageContext(ctx context.Context, tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	pageQuery, err := condition.ToPageQueryDialect(db.Dialect(), tableName)
+	if err != nil {
+		return orm.Page{}, fmt.Errorf("failed to build query: %w", err)
+	}
+	return db.selectPage(ctx, pageQuery, tableName, opts)
+}
+
+// SelectPage for a ComplexQuery
+func (db RQLiteDB) SelectPageComplex(query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	return db.SelectPageComplexContext(context.Background(), query, opts)
+}
+
+// SelectPageComplexContext is the context-aware variant of SelectPageComplex
+func (db RQLiteDB) SelectPageComplexContext(ctx context.Context, query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	if query == nil {
+		return orm.Page{}, fmt.Errorf("query cannot be nil")
+	}
+	pageQuery, err := query.ToPageQueryDialect(db.Dialect())
+	if err != nil {
+		return orm.Page{}, fmt.Errorf("failed to build complex query: %w", err)
+	}
+	return db.selectPage(ctx, pageQuery, query.From, opts)
+}
+
+// selectPage runs the page and count statements, in a single request when consistent
+func (db RQLiteDB) selectPage(ctx context.Context, pageQuery orm.PageQuery, tableName string, opts orm.PageOptions) (orm.Page, error) {
+	statements := FromManyParameterizedSQL([]orm.ParametereizedSQL{pageQuery.Select, pageQuery.Count})
+	var qrs []gorqlite.QueryResult
+	if opts.Consistent {
+		var err error
+		qrs, err = db.conn.QueryParameterizedContext(ctx, statements)
+		if err != nil {
+			return orm.Page{}, err
+		}
+	} else {
+		for _, statement := range statements {
+			qr, err := db.conn.QueryOneParameterizedContext(ctx, statement)
+			if err != nil {
+				return orm.Page{}, err
+			}
+			qrs = append(qrs, qr)
+		}
+	}
+	if len(qrs) != 2 {
+		return orm.Page{}, fmt.Errorf("expected 2 results, got %d", len(qrs))
+	}
+
+	records, err := QueryResultToDBRecords(qrs[0], tableName)
+	if err != nil {
+		return orm.Page{}, err
+	}
+	count, err := QueryResultToDBRecords(qrs[1], tableName)
+	if err != nil {
+		return orm.Page{}, err
+	}
+	return pageQuery.Page(records, count)
+}
+
+// Stream the rows matching condition. gorqlite reads the whole response before returning,
+// so the rows are yielded from memory; use the rqlite package for real streaming.
+func (db RQLiteDB) StreamWithCondition(tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamWithConditionContext(context.Background(), tableName, condition)
+}
+
+// StreamWithConditionContext is the context-aware variant of StreamWithCondition
+func (db RQLiteDB) StreamWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	if condition == nil {
+		condition = &orm.Condition{}
+	}
+	query, values, err := condition.ToSelectStringDialect(db.Dialect(), tableName)
+	if err != nil {
+		return streamError(fmt.Errorf("failed to build query: %w", err))
+	}
+	return db.stream(ctx, orm.SQLAndValuesToParameterized(query, values), tableName)
+}
+
+// Streaming variant of SelectManyComplex, see StreamWithCondition
+func (db RQLiteDB) StreamComplex(query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamComplexContext(context.Background(), query)
+}
+
+// StreamComplexContext is the context-aware variant of StreamComplex
+func (db RQLiteDB) StreamComplexContext(ctx context.Context, query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	if query == nil {
+		return streamError(fmt.Errorf("query cannot be nil"))
+	}
+	sql, values, err := query.ToSQLDialect(db.Dialect())
+	if err != nil {
+		return streamError(fmt.Errorf("failed to build complex query: %w", err))
+	}
+	return db.stream(ctx, orm.SQLAndValuesToParameterized(sql, values), query.From)
+}
+
+// Streaming variant of SelectOneSQLParameterized, see StreamWithCondition
+func (db RQLiteDB) StreamSQLParameterized(p orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamSQLParameterizedContext(context.Background(), p)
+}
+
+// StreamSQLParameterizedContext is the context-aware variant of StreamSQLParameterized
+func (db RQLiteDB) StreamSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return db.stream(ctx, p, "")
+}
+
+// stream sends the query each time the sequence is ranged over and yields its rows
+func (db RQLiteDB) stream(ctx context.Context, p orm.ParametereizedSQL, tableName string) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		qr, err := db.conn.QueryOneParameterizedContext(ctx, FromOneParameterizedSQL(p))
+		if err != nil {
+			yield(orm.DBRecord{}, err)
+			return
+		}
+		for qr.Next() {
+			result, err := qr.Map()
+			if err != nil {
+				yield(orm.DBRecord{}, err)
+				return
+			}
+			if !yield(orm.DBRecord{TableName: tableName, Data: result}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// streamError returns a sequence yielding only err
+func streamError(err error) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		yield(orm.DBRecord{}, err)
+	}
+}
+
+// Select using 1 raw sql statement, returns ErrSQLNoRows if nothing matched
+func (db RQLiteDB) SelectOneSQL(sql string) (orm.DBRecords, error) {
+	return db.SelectOneSQLContext(context.Background(), sql)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (db RQLiteDB) SelectOneSQLContext(ctx context.Context, sql string) (orm.DBRecords, error) {
+	return db.selectRecords(ctx, orm.ParametereizedSQL{Query: sql}, "")
+}
+
+// Select using many raw sql statements in one request, a statement without rows
+// returns an empty DBRecords
+func (db RQLiteDB) SelectManySQL(sqls []string) ([]orm.DBRecords, error) {
+	return db.SelectManySQLContext(context.Background(), sqls)
+}
+
+// SelectManySQLContext is the context-aware variant of SelectManySQL
+func (db RQLiteDB) SelectManySQLContext(ctx context.Context, sqls []string) ([]orm.DBRecords, error) {
+	qrs, err := db.conn.QueryContext(ctx, sqls)
+	if err != nil {
+		return nil, err
+	}
+	return queryResultsToDBRecords(qrs)
+}
+
+// Select using 1 raw sql statement that must return exactly one row
+func (db RQLiteDB) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
+	return db.SelectOnlyOneSQLContext(context.Background(), sql)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (db RQLiteDB) SelectOnlyOneSQLContext(ctx context.Context, sql string) (orm.DBRecord, error) {
+	records, err := db.SelectOneSQLContext(ctx, sql)
+	if err != nil {
+		return orm.DBRecord{}, err
+	}
+	return onlyOne(records)
+}
+
+// Select using 1 parameterized sql statement, returns ErrSQLNoRows if nothing matched
+func (db RQLiteDB) SelectOneSQLParameterized(p orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return db.SelectOneSQLParameterizedContext(context.Background(), p)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (db RQLiteDB) SelectOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return db.selectRecords(ctx, p, "")
+}
+
+// Select using many parameterized sql statements in one request, see SelectManySQL
+func (db RQLiteDB) SelectManySQLParameterized(p []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
+	return db.SelectManySQLParameterizedContext(context.Background(), p)
+}
+
+// SelectManySQLParameterizedContext is the context-aware variant of SelectManySQLParameterized
+func (db RQLiteDB) SelectManySQLParameterizedContext(ctx context.Context, p []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
+	qrs, err := db.conn.QueryParameterizedContext(ctx, FromManyParameterizedSQL(p))
+	if err != nil {
+		return nil, err
+	}
+	return queryResultsToDBRecords(qrs)
+}
+
+// Select using 1 parameterized sql statement that must return exactly one row
+func (db RQLiteDB) SelectOnlyOneSQLParameterized(p orm.ParametereizedSQL) (orm.DBRecord, error) {
+	return db.SelectOnlyOneSQLParameterizedContext(context.Background(), p)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (db RQLiteDB) SelectOnlyOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) (orm.DBRecord, error) {
+	records, err := db.SelectOneSQLParameterizedContext(ctx, p)
+	if err != nil {
+		return orm.DBRecord{}, err
+	}
+	return onlyOne(records)
+}
+
+// selectRecords runs one statement, no rows is ErrSQLNoRows like the other selects
+func (db RQLiteDB) selectRecords(ctx context.Context, p orm.ParametereizedSQL, tableName string) ([]orm.DBRecord, error) {
+	qr, err := db.conn.QueryOneParameterizedContext(ctx, FromOneParameterizedSQL(p))
+	if err != nil {
+		return nil, err
+	}
+	records, err := QueryResultToDBRecords(qr, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, orm.ErrSQLNoRows
+	}
+	return records, nil
+}
+
+// queryResultsToDBRecords converts the result of each statement
+func queryResultsToDBRecords(qrs []gorqlite.QueryResult) ([]orm.DBRecords, error) {
+	results := make([]orm.DBRecords, 0, len(qrs))
+	for _, qr := range qrs {
+		records, err := QueryResultToDBRecords(qr, "")
+		if err != nil {
+			return results, err
+		}
+		results = append(results, records)
+	}
+	return results, nil
+}
+
+// onlyOne returns the single record, or an error when there is none or more than one
+func onlyOne(records []orm.DBRecord) (orm.DBRecord, error) {
+	if len(records) == 0 {
+		return orm.DBRecord{}, orm.ErrSQLNoRows
+	}
+	if len(records) > 1 {
+		return orm.DBRecord{}, orm.ErrSQLMoreThanOneRow
+	}
+	return records[0], nil
+}
+
+// Insert a record or resolve the conflict according to opts
+func (db RQLiteDB) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return db.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
+
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (db RQLiteDB) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	sql, values, err := record.ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(sql, values))
+}
+
+// Upsert records of one table, batched by MAX_MULTIPLE_INSERTS. No records is a no-op.
+func (db RQLiteDB) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return db.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
+
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (db RQLiteDB) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecManySQLParameterizedContext(ctx, paramSQLs)
+}
@@ -0,0 +1,66 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+
+	orm "github.com/medatechnology/simpleorm"
+	"github.com/medatechnology/simpleorm/rqlite"
+)
+
+// ListTables returns the names of the user tables, without SQLite's internal tables
+func (db RQLiteDB) ListTables() ([]string, error) {
+	return db.ListTablesContext(context.Background())
+}
+
+// ListTablesContext is the context-aware variant of ListTables
+func (db RQLiteDB) ListTablesContext(ctx context.Context) ([]string, error) {
+	qr, err := db.conn.QueryOneContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE '"+PREFIX_SQLITE_TABLE+"%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for qr.Next() {
+		var name string
+		if err := qr.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+// DescribeTable returns the columns and constraints of a table. It sends the same PRAGMA
+// queries as the rqlite package, see rqlite.DescribeTableQueries.
+func (db RQLiteDB) DescribeTable(tableName string) (orm.TableInfo, error) {
+	return db.DescribeTableContext(context.Background(), tableName)
+}
+
+// DescribeTableContext is the context-aware variant of DescribeTable
+func (db RQLiteDB) DescribeTableContext(ctx context.Context, tableName string) (orm.TableInfo, error) {
+	queries, err := rqlite.DescribeTableQueries(tableName)
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+	}
+	qrs, err := db.conn.QueryParameterizedContext(ctx, FromManyParameterizedSQL(queries))
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+	}
+	if len(qrs) != len(queries) {
+		return orm.TableInfo{}, orm.WrapSelectError(fmt.Errorf("expected %d results, got %d", len(queries), len(qrs)), tableName)
+	}
+
+	var results [][]orm.DBRecord
+	for _, qr := range qrs {
+		records, err := QueryResultToDBRecords(qr, tableName)
+		if err != nil {
+			return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+		}
+		results = append(results, records)
+	}
+	info, err := rqlite.TableInfoFromPragmaResults(tableName, results)
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+	}
+	return info, nil
+}
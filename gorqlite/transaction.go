@@ -0,0 +1,353 @@
+package gorqlite
+
+import (
+	"context"
+	"fmt"
+
+	orm "github.com/medatechnology/simpleorm"
+	"github.com/rqlite/gorqlite"
+)
+
+// gorqliteTransaction implements orm.TransactionContext with the same buffered model
+// as the rqlite package: writes are collected in order and sent as one transactional
+// request on Commit, selects run immediately outside of the transaction.
+type gorqliteTransaction struct {
+	db         RQLiteDB
+	ctx        context.Context                   // Context bound to the transaction, used on Commit
+	statements []gorqlite.ParameterizedStatement // Buffered statements, in call order
+	committed  bool
+	rolledBack bool
+	inserted   []orm.TableStruct // Buffered structs whose AfterInsert hook runs on Commit
+	abortErr   error             // Hook error that rolled the transaction back
+}
+
+// BeginTransaction starts a new buffered transaction
+func (db RQLiteDB) BeginTransaction() (orm.Transaction, error) {
+	return db.BeginTransactionContext(context.Background())
+}
+
+// BeginTransactionContext starts a new buffered transaction bound to ctx. Nothing is
+// sent before Commit, so ctx mostly governs the final write request.
+func (db RQLiteDB) BeginTransactionContext(ctx context.Context) (orm.TransactionContext, error) {
+	return &gorqliteTransaction{db: db, ctx: ctx}, nil
+}
+
+// Commit sends the buffered statements in one request, which gorqlite runs as a
+// transaction, then runs the AfterInsert hooks of the buffered structs
+func (tx *gorqliteTransaction) Commit() error {
+	if tx.committed {
+		return fmt.Errorf("transaction already committed")
+	}
+	if tx.abortErr != nil {
+		return fmt.Errorf("transaction aborted: %w", tx.abortErr)
+	}
+	if tx.rolledBack {
+		return fmt.Errorf("transaction already rolled back")
+	}
+
+	if len(tx.statements) > 0 {
+		// A failing statement aborts the whole request, the error is in the results too
+		if _, err := tx.db.conn.WriteParameterizedContext(tx.ctx, tx.statements); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	tx.committed = true
+	return orm.RunAfterInsertAll(tx.ctx, tx.inserted)
+}
+
+// Rollback discards the buffered statements without sending them
+func (tx *gorqliteTransaction) Rollback() error {
+	if tx.committed {
+		return fmt.Errorf("cannot rollback: transaction already committed")
+	}
+	tx.statements = nil
+	tx.inserted = nil
+	tx.rolledBack = true
+	return nil
+}
+
+// check returns the error for a finished transaction or a done ctx
+func (tx *gorqliteTransaction) check(ctx context.Context) error {
+	if tx.committed {
+		return fmt.Errorf("transaction already committed")
+	}
+	if tx.rolledBack {
+		return fmt.Errorf("transaction already rolled back")
+	}
+	return ctx.Err()
+}
+
+// buffer appends the statements, the results are only known on Commit
+func (tx *gorqliteTransaction) buffer(ctx context.Context, p ...orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	if err := tx.check(ctx); err != nil {
+		return nil, err
+	}
+	tx.statements = append(tx.statements, FromManyParameterizedSQL(p)...)
+	return make([]orm.BasicSQLResult, len(p)), nil
+}
+
+// ExecOneSQL buffers a SQL statement
+func (tx *gorqliteTransaction) ExecOneSQL(sql string) orm.BasicSQLResult {
+	return tx.ExecOneSQLContext(context.Background(), sql)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (tx *gorqliteTransaction) ExecOneSQLContext(ctx context.Context, sql string) orm.BasicSQLResult {
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.ParametereizedSQL{Query: sql})
+}
+
+// ExecOneSQLParameterized buffers a parameterized SQL statement
+func (tx *gorqliteTransaction) ExecOneSQLParameterized(p orm.ParametereizedSQL) orm.BasicSQLResult {
+	return tx.ExecOneSQLParameterizedContext(context.Background(), p)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (tx *gorqliteTransaction) ExecOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) orm.BasicSQLResult {
+	if _, err := tx.buffer(ctx, p); err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return orm.BasicSQLResult{}
+}
+
+// ExecManySQL buffers SQL statements
+func (tx *gorqliteTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
+	return tx.ExecManySQLContext(context.Background(), sqls)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (tx *gorqliteTransaction) ExecManySQLContext(ctx context.Context, sqls []string) ([]orm.BasicSQLResult, error) {
+	p := make([]orm.ParametereizedSQL, 0, len(sqls))
+	for _, sql := range sqls {
+		p = append(p, orm.ParametereizedSQL{Query: sql})
+	}
+	return tx.buffer(ctx, p...)
+}
+
+// ExecManySQLParameterized buffers parameterized SQL statements
+func (tx *gorqliteTransaction) ExecManySQLParameterized(p []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return tx.ExecManySQLParameterizedContext(context.Background(), p)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (tx *gorqliteTransaction) ExecManySQLParameterizedContext(ctx context.Context, p []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return tx.buffer(ctx, p...)
+}
+
+// SelectOneSQL runs the query immediately, it does not see the buffered writes
+func (tx *gorqliteTransaction) SelectOneSQL(sql string) (orm.DBRecords, error) {
+	return tx.SelectOneSQLContext(context.Background(), sql)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (tx *gorqliteTransaction) SelectOneSQLContext(ctx context.Context, sql string) (orm.DBRecords, error) {
+	if err := tx.check(ctx); err != nil {
+		return nil, err
+	}
+	return tx.db.SelectOneSQLContext(ctx, sql)
+}
+
+// SelectOnlyOneSQL runs the query immediately and expects exactly one row
+func (tx *gorqliteTransaction) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
+	return tx.SelectOnlyOneSQLContext(context.Background(), sql)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (tx *gorqliteTransaction) SelectOnlyOneSQLContext(ctx context.Context, sql string) (orm.DBRecord, error) {
+	if err := tx.check(ctx); err != nil {
+		return orm.DBRecord{}, err
+	}
+	return tx.db.SelectOnlyOneSQLContext(ctx, sql)
+}
+
+// SelectOneSQLParameterized runs the query immediately, it does not see the buffered writes
+func (tx *gorqliteTransaction) SelectOneSQLParameterized(p orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return tx.SelectOneSQLParameterizedContext(context.Background(), p)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (tx *gorqliteTransaction) SelectOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) (orm.DBRecords, error) {
+	if err := tx.check(ctx); err != nil {
+		return nil, err
+	}
+	return tx.db.SelectOneSQLParameterizedContext(ctx, p)
+}
+
+// SelectOnlyOneSQLParameterized runs the query immediately and expects exactly one row
+func (tx *gorqliteTransaction) SelectOnlyOneSQLParameterized(p orm.ParametereizedSQL) (orm.DBRecord, error) {
+	return tx.SelectOnlyOneSQLParameterizedContext(context.Background(), p)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (tx *gorqliteTransaction) SelectOnlyOneSQLParameterizedContext(ctx context.Context, p orm.ParametereizedSQL) (orm.DBRecord, error) {
+	if err := tx.check(ctx); err != nil {
+		return orm.DBRecord{}, err
+	}
+	return tx.db.SelectOnlyOneSQLParameterizedContext(ctx, p)
+}
+
+// InsertOneDBRecord buffers an insert
+func (tx *gorqliteTransaction) InsertOneDBRecord(record orm.DBRecord) orm.BasicSQLResult {
+	return tx.InsertOneDBRecordContext(context.Background(), record)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (tx *gorqliteTransaction) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord) orm.BasicSQLResult {
+	if err := orm.ValidateTableName(record.TableName); err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	sql, values := record.ToInsertSQLParameterized()
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(sql, values))
+}
+
+// InsertManyDBRecords buffers inserts of records that can be of different tables
+func (tx *gorqliteTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyDBRecordsContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (tx *gorqliteTransaction) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	results := make([]orm.BasicSQLResult, 0, len(records))
+	for _, record := range records {
+		result := tx.InsertOneDBRecordContext(ctx, record)
+		results = append(results, result)
+		if result.Error != nil {
+			return results, result.Error
+		}
+	}
+	return results, nil
+}
+
+// InsertManyDBRecordsSameTable buffers batched inserts of records of one table
+func (tx *gorqliteTransaction) InsertManyDBRecordsSameTable(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyDBRecordsSameTableContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (tx *gorqliteTransaction) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	tableName := records[0].TableName
+	for i, record := range records {
+		if record.TableName != tableName {
+			return nil, fmt.Errorf("all records must be from the same table, record %d has table '%s' but expected '%s'",
+				i, record.TableName, tableName)
+		}
+	}
+	return tx.buffer(ctx, orm.ToInsertSQLParameterizedFromSlice(records)...)
+}
+
+// InsertOneTableStruct buffers the insert of a TableStruct. An error from its BeforeInsert
+// or Validate hook rolls the transaction back, AfterInsert runs on Commit.
+func (tx *gorqliteTransaction) InsertOneTableStruct(obj orm.TableStruct) orm.BasicSQLResult {
+	return tx.InsertOneTableStructContext(context.Background(), obj)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct
+func (tx *gorqliteTransaction) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct) orm.BasicSQLResult {
+	record, err := tx.insertRecord(ctx, obj)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	result := tx.InsertOneDBRecordContext(ctx, record)
+	if result.Error == nil {
+		tx.inserted = append(tx.inserted, obj)
+	}
+	return result
+}
+
+// InsertManyTableStructs buffers the inserts of many TableStructs, see InsertOneTableStruct
+func (tx *gorqliteTransaction) InsertManyTableStructs(objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyTableStructsContext(context.Background(), objs)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs
+func (tx *gorqliteTransaction) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
+	records := make([]orm.DBRecord, 0, len(objs))
+	for _, obj := range objs {
+		record, err := tx.insertRecord(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	results, err := tx.InsertManyDBRecordsContext(ctx, records)
+	if err == nil {
+		tx.inserted = append(tx.inserted, objs...)
+	}
+	return results, err
+}
+
+// insertRecord runs the before-insert hooks of obj and converts it, rolling the
+// transaction back when a hook fails
+func (tx *gorqliteTransaction) insertRecord(ctx context.Context, obj orm.TableStruct) (orm.DBRecord, error) {
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
+	if err != nil {
+		if !tx.committed {
+			tx.Rollback()
+			tx.abortErr = err
+		}
+		return record, orm.WrapInsertError(err, obj.TableName())
+	}
+	return record, nil
+}
+
+// UpsertOneDBRecord buffers an upsert of a single record
+func (tx *gorqliteTransaction) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return tx.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
+
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (tx *gorqliteTransaction) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	sql, values, err := record.ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(sql, values))
+}
+
+// UpsertManyDBRecordsSameTable buffers batched upserts for records of one table
+func (tx *gorqliteTransaction) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return tx.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
+
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (tx *gorqliteTransaction) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx.buffer(ctx, paramSQLs...)
+}
+
+// UpdateWithCondition buffers an UPDATE built from set and where
+func (tx *gorqliteTransaction) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return tx.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
+
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (tx *gorqliteTransaction) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQL(tableName, set, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// DeleteWithCondition buffers a DELETE built from where
+func (tx *gorqliteTransaction) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return tx.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (tx *gorqliteTransaction) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQL(tableName, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
@@ -0,0 +1,112 @@
+package orm
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrInvalidAggregate medaerror.MedaError = medaerror.MedaError{Message: "invalid aggregate: only COUNT, SUM, AVG, MIN and MAX of a column (or COUNT(*)) are allowed"}
+)
+
+var (
+	// allowedAggregates are the aggregate functions accepted as HavingCondition fields,
+	// all of them exist in both SQLite and PostgreSQL
+	allowedAggregates = map[string]bool{
+		"COUNT": true,
+		"SUM":   true,
+		"AVG":   true,
+		"MIN":   true,
+		"MAX":   true,
+	}
+
+	// FUNC(column), FUNC(DISTINCT column) or FUNC(*)
+	aggregateFieldRegex = regexp.MustCompile(`(?i)^([a-z_]+)\s*\(\s*(distinct\s+)?(.*?)\s*\)$`)
+)
+
+// aggregateField is a parsed HavingCondition field like COUNT(DISTINCT orders.id)
+type aggregateField struct {
+	Function string
+	Distinct bool
+	Column   string // "*" for COUNT(*)
+}
+
+// parseAggregate splits FUNC(column); ok is false when field is not a function call at all
+func parseAggregate(field string) (aggregateField, bool) {
+	m := aggregateFieldRegex.FindStringSubmatch(strings.TrimSpace(field))
+	if m == nil {
+		return aggregateField{}, false
+	}
+	return aggregateField{
+		Function: strings.ToUpper(m[1]),
+		Distinct: m[2] != "",
+		Column:   m[3],
+	}, true
+}
+
+// ValidateAggregateField validates an aggregate expression used as a HavingCondition field.
+// The function must be whitelisted and its argument a valid (optionally qualified) column,
+// or * for COUNT(*).
+//
+// Usage:
+//
+//	if err := ValidateAggregateField("COUNT(DISTINCT orders.id)"); err != nil {
+//	    return err
+//	}
+//
+// Returns: error if validation fails, nil otherwise
+func ValidateAggregateField(field string) error {
+	agg, ok := parseAggregate(field)
+	if !ok || !allowedAggregates[agg.Function] {
+		return ErrInvalidAggregate
+	}
+	if agg.Column == "*" {
+		if agg.Function != "COUNT" || agg.Distinct {
+			return ErrInvalidAggregate
+		}
+		return nil
+	}
+	if agg.Column == "" || ValidateFieldName(agg.Column) != nil {
+		return ErrInvalidAggregate
+	}
+	return nil
+}
+
+// checkField validates a condition field: a column, or an aggregate while rendering HAVING.
+// Qualified columns must reference a table or alias of the query.
+func (b *sqlBuilder) checkField(field string) error {
+	if b.aggregates {
+		if agg, ok := parseAggregate(field); ok {
+			if err := ValidateAggregateField(field); err != nil {
+				return err
+			}
+			if agg.Column == "*" {
+				return nil
+			}
+			return b.checkQualifier(agg.Column)
+		}
+	}
+	if err := ValidateFieldName(field); err != nil {
+		return err
+	}
+	return b.checkQualifier(field)
+}
+
+// column renders a field validated by checkField
+func (b *sqlBuilder) column(field string) string {
+	if b.aggregates {
+		if agg, ok := parseAggregate(field); ok {
+			arg := agg.Column
+			if arg != "*" {
+				arg = b.ident(arg)
+			}
+			if agg.Distinct {
+				arg = "DISTINCT " + arg
+			}
+			return agg.Function + "(" + arg + ")"
+		}
+	}
+	return b.ident(field)
+}
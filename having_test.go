@@ -0,0 +1,117 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestValidateAggregateField checks the aggregate whitelist
+func TestValidateAggregateField(t *testing.T) {
+	tests := []struct {
+		field   string
+		wantErr error
+	}{
+		{"COUNT(*)", nil},
+		{"count(orders.id)", nil},
+		{"COUNT(DISTINCT orders.user_id)", nil},
+		{"SUM(total)", nil},
+		{"avg( price )", nil},
+		{"MIN(created_at)", nil},
+		{"MAX(public.orders.total)", nil},
+		{"SUM(*)", ErrInvalidAggregate},
+		{"COUNT(DISTINCT *)", ErrInvalidAggregate},
+		{"COUNT()", ErrInvalidAggregate},
+		{"pg_sleep(10)", ErrInvalidAggregate},
+		{"SUM(total) OR 1=1", ErrInvalidAggregate},
+		{"SUM(total); DROP TABLE orders", ErrInvalidAggregate},
+		{"SUM(price * 2)", ErrInvalidAggregate},
+		{"total", ErrInvalidAggregate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if err := ValidateAggregateField(tt.field); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestHavingCondition checks HAVING values are bound after WHERE and before ORDER BY expressions
+func TestHavingCondition(t *testing.T) {
+	query := &ComplexQuery{
+		Select:  []string{"users.id", "COUNT(orders.id) AS order_count"},
+		From:    "users",
+		Joins:   []Join{{Type: LeftJoin, Table: "orders", Condition: "users.id = orders.user_id"}},
+		Where:   &Condition{Field: "users.status", Operator: "=", Value: "active"},
+		GroupBy: []string{"users.id"},
+		HavingCondition: &Condition{Logic: "AND", Nested: []Condition{
+			{Field: "COUNT(orders.id)", Operator: ">", Value: 5},
+			{Field: "sum(DISTINCT orders.total)", Operator: "BETWEEN", Value: []int{100, 1000}},
+			{Field: "users.id", Operator: "!=", Value: 1},
+		}},
+		OrderByExprs: []*Expression{Expr("COUNT(orders.id) - ?", 3)},
+	}
+
+	want := "SELECT users.id, COUNT(orders.id) AS order_count FROM users LEFT JOIN orders ON users.id = orders.user_id " +
+		"WHERE users.status = $1 GROUP BY users.id " +
+		"HAVING (COUNT(orders.id) > $2) AND (SUM(DISTINCT orders.total) BETWEEN $3 AND $4) AND (users.id != $5) " +
+		"ORDER BY COUNT(orders.id) - $6"
+	wantValues := []interface{}{"active", 5, 100, 1000, 1, 3}
+
+	got, values, err := query.ToSQLDialect(PostgreSQLDialect{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("Expected values %v, got %v", wantValues, values)
+	}
+}
+
+// TestHavingConditionErrors checks aggregates are rejected outside HAVING and validated inside it
+func TestHavingConditionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ComplexQuery
+		wantErr error
+	}{
+		{
+			name:    "aggregate in WHERE",
+			query:   ComplexQuery{From: "orders", Where: &Condition{Field: "COUNT(id)", Operator: ">", Value: 1}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name:    "unknown function",
+			query:   ComplexQuery{From: "orders", HavingCondition: &Condition{Field: "random(id)", Operator: ">", Value: 1}},
+			wantErr: ErrInvalidAggregate,
+		},
+		{
+			name:    "unknown qualifier in aggregate",
+			query:   ComplexQuery{From: "orders", HavingCondition: &Condition{Field: "SUM(users.total)", Operator: ">", Value: 1}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name: "aggregate in HAVING subquery WHERE",
+			query: ComplexQuery{From: "orders", HavingCondition: &Condition{
+				Field: "SUM(total)", Operator: ">", Subquery: &ComplexQuery{
+					Select: []string{"AVG(total)"},
+					From:   "orders",
+					Where:  &Condition{Field: "COUNT(id)", Operator: ">", Value: 1},
+				},
+			}},
+			wantErr: ErrInvalidFieldName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.query.ToSQL(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -59,10 +59,8 @@ func TableStructToDBRecord(obj TableStruct) (DBRecord, error) {
 	}, nil
 }
 
-// Decided to just use MapToStruct manually
-// func DBRecordToStruct(rec DBRecord) TableStruct {
-// 	return object.MapToStruct[TableStruct](rec.Data)
-// }
+// For the other direction (DBRecord -> struct) use ScanRecord / ScanRecords
+// or the typed SelectInto / SelectOneInto helpers in scan.go
 
 // Get all sum timing
 func TotalTimeElapsedInSecond(reses []BasicSQLResult) float64 {
@@ -103,49 +101,18 @@ func InterfaceToSQLString(interfaceVal interface{}) string {
 // Convert the .sql file into each individual sql commands
 // Input is []string which are the content of the .sql file
 // Output is []string of each sql commands.
+// Statements are split with SplitSQL (without a dialect), so semicolons inside strings,
+// comments, trigger bodies and $$ bodies are kept. Use SplitSQL directly for line numbers.
+// NOTE: since the switch to SplitSQL, comments inside a statement are no longer stripped
+// and multi-line statements keep their line breaks; only comment-only parts are dropped.
+// The ErrUnterminatedSQL error of SplitSQL is discarded: an unclosed quote or comment
+// makes the rest of the script one last statement. Call SplitSQL to detect it.
 func ConvertSQLCommands(lines []string) []string {
-	var commands []string
-	var currentCommand strings.Builder
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		commentIndex := strings.Index(line, "--")
-		if commentIndex != -1 {
-			line = line[:commentIndex] // Remove comment part
-		}
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		currentCommand.WriteString(line)
-		currentCommand.WriteString(" ")
-
-		if strings.Contains(line, ";") {
-			parts := strings.Split(currentCommand.String(), ";")
-			for _, part := range parts[:len(parts)-1] { // Process parts before the last one
-				command := strings.TrimSpace(part)
-				if command != "" {
-					commands = append(commands, command)
-				}
-			}
-			currentCommand.Reset()
-			lastPart := parts[len(parts)-1]
-			currentCommand.WriteString(lastPart)
-		}
+	statements, _ := SplitSQL(nil, strings.Join(lines, "\n"))
+	commands := make([]string, len(statements))
+	for i, statement := range statements {
+		commands[i] = statement.SQL
 	}
-
-	if currentCommand.Len() > 0 {
-		command := strings.TrimSpace(currentCommand.String())
-		if command != "" {
-			commands = append(commands, command)
-		}
-	}
-
 	return commands
 }
 
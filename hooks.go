@@ -0,0 +1,142 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrHookFailed       medaerror.MedaError = medaerror.MedaError{Message: "lifecycle hook failed"}
+	ErrValidationFailed medaerror.MedaError = medaerror.MedaError{Message: "validation failed"}
+)
+
+// Lifecycle hooks are optional interfaces a TableStruct can implement. The backends call
+// them from InsertOneTableStruct, InsertManyTableStructs (and their transaction variants),
+// UpdateTableStruct calls BeforeUpdate, and the typed select helpers (SelectInto,
+// SelectOneInto, FindByPK, ReloadTableStruct) call AfterSelect on every loaded struct.
+// Implement them on the pointer receiver and pass pointers, so the hooks can modify the struct:
+//
+//	func (u *User) BeforeInsert(ctx context.Context) error {
+//	    u.CreatedAt = time.Now().UTC()
+//	    u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+//	    return nil
+//	}
+//
+//	func (u *User) Validate() error {
+//	    if u.Email == "" {
+//	        return errors.New("email is required")
+//	    }
+//	    return nil
+//	}
+//
+// An error from BeforeInsert, BeforeUpdate or Validate aborts the operation before anything
+// is sent to the database (on the buffered rqlite transaction it also rolls the transaction
+// back). Errors are wrapped with ErrHookFailed or ErrValidationFailed and keep the original
+// error for errors.Is / errors.As.
+
+// BeforeInserter is called before a struct is converted and inserted
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter is called once the insert succeeded. On the buffered rqlite
+// transaction it is called after a successful Commit.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdater is called before UpdateTableStruct writes the struct
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterSelecter is called on each struct loaded by the typed select helpers
+type AfterSelecter interface {
+	AfterSelect(ctx context.Context) error
+}
+
+// Validator is called after BeforeInsert / BeforeUpdate, so it sees the final values
+type Validator interface {
+	Validate() error
+}
+
+// RunBeforeInsert calls the BeforeInsert hook then Validate, when obj implements them
+func RunBeforeInsert(ctx context.Context, obj TableStruct) error {
+	if hook, ok := obj.(BeforeInserter); ok {
+		if err := hook.BeforeInsert(ctx); err != nil {
+			return fmt.Errorf("%w: %s BeforeInsert: %w", ErrHookFailed, obj.TableName(), err)
+		}
+	}
+	return runValidate(obj)
+}
+
+// RunAfterInsert calls the AfterInsert hook when obj implements it
+func RunAfterInsert(ctx context.Context, obj TableStruct) error {
+	if hook, ok := obj.(AfterInserter); ok {
+		if err := hook.AfterInsert(ctx); err != nil {
+			return fmt.Errorf("%w: %s AfterInsert: %w", ErrHookFailed, obj.TableName(), err)
+		}
+	}
+	return nil
+}
+
+// RunBeforeUpdate calls the BeforeUpdate hook then Validate, when obj implements them
+func RunBeforeUpdate(ctx context.Context, obj TableStruct) error {
+	if hook, ok := obj.(BeforeUpdater); ok {
+		if err := hook.BeforeUpdate(ctx); err != nil {
+			return fmt.Errorf("%w: %s BeforeUpdate: %w", ErrHookFailed, obj.TableName(), err)
+		}
+	}
+	return runValidate(obj)
+}
+
+// RunAfterSelect calls the AfterSelect hook when obj implements it
+func RunAfterSelect(ctx context.Context, obj interface{}) error {
+	if hook, ok := obj.(AfterSelecter); ok {
+		if err := hook.AfterSelect(ctx); err != nil {
+			return fmt.Errorf("%w: AfterSelect: %w", ErrHookFailed, err)
+		}
+	}
+	return nil
+}
+
+// TableStructToInsertRecord runs the before-insert hooks of obj and converts it to a DBRecord.
+// Backends use it in place of TableStructToDBRecord when inserting a TableStruct.
+func TableStructToInsertRecord(ctx context.Context, obj TableStruct) (DBRecord, error) {
+	if err := RunBeforeInsert(ctx, obj); err != nil {
+		return DBRecord{}, err
+	}
+	return TableStructToDBRecord(obj)
+}
+
+// RunAfterInsertAll calls the AfterInsert hook of each struct, stopping at the first error
+func RunAfterInsertAll(ctx context.Context, objs []TableStruct) error {
+	for _, obj := range objs {
+		if err := RunAfterInsert(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runValidate calls Validate when obj implements Validator
+func runValidate(obj TableStruct) error {
+	if v, ok := obj.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrValidationFailed, obj.TableName(), err)
+		}
+	}
+	return nil
+}
+
+// afterSelectAll calls the AfterSelect hook on each row
+func afterSelectAll[T any](ctx context.Context, rows []T) error {
+	for i := range rows {
+		if err := RunAfterSelect(ctx, &rows[i]); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return nil
+}
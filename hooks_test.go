@@ -0,0 +1,112 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errHookEmail = errors.New("email is required")
+
+type hookUser struct {
+	ID        int64     `db:"id,pk"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Display   string    `db:"-"`
+}
+
+func (u *hookUser) TableName() string { return "users" }
+
+func (u *hookUser) BeforeInsert(ctx context.Context) error {
+	u.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	return nil
+}
+
+func (u *hookUser) BeforeUpdate(ctx context.Context) error {
+	u.UpdatedAt = time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	return nil
+}
+
+func (u *hookUser) AfterSelect(ctx context.Context) error {
+	if u.Email == "broken" {
+		return errors.New("broken row")
+	}
+	u.Display = "<" + u.Email + ">"
+	return nil
+}
+
+func (u *hookUser) Validate() error {
+	if u.Email == "" {
+		return errHookEmail
+	}
+	return nil
+}
+
+// TestTableStructToInsertRecord checks BeforeInsert runs before Validate and the conversion
+func TestTableStructToInsertRecord(t *testing.T) {
+	user := &hookUser{Email: "  Ana@Example.COM "}
+	record, err := TableStructToInsertRecord(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if record.Data["email"] != "ana@example.com" || user.CreatedAt.IsZero() {
+		t.Errorf("Expected the BeforeInsert changes in the record, got %v", record.Data)
+	}
+
+	_, err = TableStructToInsertRecord(context.Background(), &hookUser{Email: "   "})
+	if !errors.Is(err, ErrValidationFailed) || !errors.Is(err, errHookEmail) {
+		t.Errorf("Expected %v wrapping %v, got %v", ErrValidationFailed, errHookEmail, err)
+	}
+}
+
+// TestUpdateTableStructHooks checks BeforeUpdate sets fields and Validate aborts the update
+func TestUpdateTableStructHooks(t *testing.T) {
+	db := &stubDB{}
+	if result := UpdateTableStruct(db, &hookUser{ID: 1, Email: "ana@example.com"}); result.Error != nil {
+		t.Fatalf("Unexpected error: %v", result.Error)
+	}
+	if len(db.updates) != 1 || db.updates[0]["updated_at"] != time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC) {
+		t.Errorf("Expected updated_at set by BeforeUpdate, got %v", db.updates)
+	}
+
+	result := UpdateTableStruct(db, &hookUser{ID: 1})
+	if !errors.Is(result.Error, errHookEmail) {
+		t.Errorf("Expected %v, got %v", errHookEmail, result.Error)
+	}
+	if len(db.updates) != 1 {
+		t.Errorf("Expected the invalid update to be skipped, got %v", db.updates)
+	}
+}
+
+// TestSelectIntoAfterSelect checks AfterSelect runs on every loaded struct
+func TestSelectIntoAfterSelect(t *testing.T) {
+	db := &stubDB{rows: []DBRecord{
+		{TableName: "users", Data: map[string]interface{}{"id": int64(1), "email": "ana@example.com"}},
+		{TableName: "users", Data: map[string]interface{}{"id": int64(2), "email": "bob@example.com"}},
+	}}
+
+	users, err := SelectInto[hookUser](db, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].Display != "<ana@example.com>" || users[1].Display != "<bob@example.com>" {
+		t.Errorf("Expected AfterSelect on every row, got %+v", users)
+	}
+
+	user, err := FindByPK[hookUser](db, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.Display != "<ana@example.com>" {
+		t.Errorf("Expected %q, got %q", "<ana@example.com>", user.Display)
+	}
+
+	db.rows = append(db.rows, DBRecord{TableName: "users", Data: map[string]interface{}{"id": int64(3), "email": "broken"}})
+	if _, err := SelectInto[hookUser](db, nil); !errors.Is(err, ErrHookFailed) {
+		t.Errorf("Expected %v, got %v", ErrHookFailed, err)
+	}
+}
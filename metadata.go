@@ -0,0 +1,180 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structField describes one struct field that is mapped to a table column.
+// The column name comes from the `db` tag, falling back to the `json` tag.
+// Anything after the first comma in the `db` tag is kept as options, e.g.
+//
+//	ID    int    `db:"id,pk"`
+//	Email string `db:"email,unique,notnull"`
+//	Price int    `db:"price,default=0,type=DECIMAL(10,2)"`
+type structField struct {
+	Column  string            // Column name in the database
+	Name    string            // Go field name, used in error messages
+	Index   []int             // Field index path, supports embedded structs
+	Type    reflect.Type      // Go type of the field
+	Options map[string]string // Tag options, flags have an empty value
+}
+
+// HasOption reports whether the field tag contains the given option
+func (f *structField) HasOption(name string) bool {
+	_, ok := f.Options[name]
+	return ok
+}
+
+// Option returns the value of a key=value tag option
+func (f *structField) Option(name string) string {
+	return f.Options[name]
+}
+
+// structInfo is the cached reflection metadata of a struct type
+type structInfo struct {
+	Type     reflect.Type
+	Fields   []*structField
+	byColumn map[string]*structField // lower-cased column name -> field
+}
+
+// FieldByColumn looks up a field by column name (case-insensitive)
+func (s *structInfo) FieldByColumn(column string) (*structField, bool) {
+	f, ok := s.byColumn[strings.ToLower(column)]
+	return f, ok
+}
+
+var (
+	structInfoCache sync.Map // reflect.Type -> *structInfo
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// getStructInfo returns the metadata for a struct type (or pointer to struct),
+// building and caching it on first use so hot paths do not reflect per row.
+func getStructInfo(t reflect.Type) *structInfo {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if cached, ok := structInfoCache.Load(t); ok {
+		return cached.(*structInfo)
+	}
+
+	info := &structInfo{
+		Type:     t,
+		byColumn: make(map[string]*structField),
+	}
+	if t.Kind() == reflect.Struct {
+		collectStructFields(t, nil, info)
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+// collectStructFields walks the struct fields, flattening untagged embedded structs
+func collectStructFields(t reflect.Type, parent []int, info *structInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		column, options, tagged := parseFieldTag(sf)
+		if column == "-" {
+			continue
+		}
+
+		// Untagged embedded struct: promote its fields like encoding/json does
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				collectStructFields(ft, index, info)
+			}
+			continue
+		}
+
+		if !sf.IsExported() || column == "" {
+			continue
+		}
+
+		key := strings.ToLower(column)
+		if existing, exists := info.byColumn[key]; exists {
+			// The shallowest field wins like in encoding/json, at the same depth the first one.
+			// Embedded structs are walked before the outer fields that follow them, so an
+			// outer field can still replace a promoted one here.
+			if len(existing.Index) <= len(index) {
+				continue
+			}
+			info.Fields = removeStructField(info.Fields, existing)
+		}
+		field := &structField{
+			Column:  column,
+			Name:    sf.Name,
+			Index:   index,
+			Type:    sf.Type,
+			Options: options,
+		}
+		info.Fields = append(info.Fields, field)
+		info.byColumn[key] = field
+	}
+}
+
+// removeStructField returns fields without field
+func removeStructField(fields []*structField, field *structField) []*structField {
+	for i, f := range fields {
+		if f == field {
+			return append(fields[:i], fields[i+1:]...)
+		}
+	}
+	return fields
+}
+
+// parseFieldTag extracts the column name and options of a struct field.
+// The `db` tag has priority; the `json` tag is used only for the name.
+func parseFieldTag(sf reflect.StructField) (string, map[string]string, bool) {
+	if tag, ok := sf.Tag.Lookup("db"); ok {
+		parts := splitTagOptions(tag)
+		options := make(map[string]string, len(parts)-1)
+		for _, opt := range parts[1:] {
+			opt = strings.TrimSpace(opt)
+			if opt == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(opt, "=")
+			options[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+		return strings.TrimSpace(parts[0]), options, true
+	}
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		return name, map[string]string{}, true
+	}
+	return "", nil, false
+}
+
+// splitTagOptions splits a tag on commas that are not inside parentheses,
+// so options like type=DECIMAL(10,2) stay in one piece.
+func splitTagOptions(tag string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tag[start:])
+}
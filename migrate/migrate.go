@@ -0,0 +1,521 @@
+// Package migrate applies versioned SQL migrations to an orm.Database.
+//
+// Migrations are read from an fs.FS, so they can be embedded into the binary:
+//
+//	//go:embed migrations/*.sql
+//	var migrationFiles embed.FS
+//
+//	files, _ := fs.Sub(migrationFiles, "migrations")
+//	migrator, err := migrate.New(db, files)
+//	applied, err := migrator.Up()
+//
+// Each migration is a pair of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql (the down file is optional), e.g. 0001_create_users.up.sql.
+// Applied versions are recorded with the checksum of their up file in the
+// _migrations table, which GetSchema hides together with the other internal tables.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	orm "github.com/medatechnology/simpleorm"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+const (
+	DEFAULT_MIGRATIONS_TABLE = "_migrations"
+)
+
+var (
+	ErrInvalidMigration   medaerror.MedaError = medaerror.MedaError{Message: "invalid migration file: expected <version>_<name>.up.sql or <version>_<name>.down.sql with a version above 0"}
+	ErrDuplicateMigration medaerror.MedaError = medaerror.MedaError{Message: "duplicate migration version"}
+	ErrChecksumMismatch   medaerror.MedaError = medaerror.MedaError{Message: "applied migration was modified: checksum differs from the recorded one"}
+	ErrMissingMigration   medaerror.MedaError = medaerror.MedaError{Message: "applied migration not found in the migration files"}
+	ErrNoDownMigration    medaerror.MedaError = medaerror.MedaError{Message: "migration has no down file"}
+	ErrUnknownVersion     medaerror.MedaError = medaerror.MedaError{Message: "unknown migration version"}
+)
+
+// migrationFileName matches <version>_<name>.up.sql and <version>_<name>.down.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_\-]+)\.(up|down)\.sql$`)
+
+// Migration is one version read from the migration files
+type Migration struct {
+	Version  int64  `json:"version"`
+	Name     string `json:"name"`
+	Up       string `json:"up"`             // Content of the .up.sql file
+	Down     string `json:"down,omitempty"` // Content of the .down.sql file
+	HasDown  bool   `json:"has_down"`       // Whether a .down.sql file exists
+	Checksum string `json:"checksum"`       // Hex SHA-256 of Up
+}
+
+// MigrationStatus is a migration file and/or a version recorded in the migrations table
+type MigrationStatus struct {
+	Version   int64     `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+	Modified  bool      `json:"modified,omitempty"` // Applied, but the up file changed since (checksum drift)
+	Missing   bool      `json:"missing,omitempty"`  // Applied, but there is no file for it anymore
+}
+
+// Migrator applies Migrations to DB and records them in Table.
+//
+// Every migration runs in one transaction (DB.BeginTransaction) together with its
+// bookkeeping statement: a PostgreSQL transaction, a single transactional request on
+// RQLite. A failing migration leaves neither its changes nor its record behind.
+// Statements that cannot run inside a transaction, such as PostgreSQL's
+// CREATE INDEX CONCURRENTLY, are not supported.
+//
+// The gorqlite backend is the exception: it sends the request with gorqlite's
+// connection-wide transaction setting. It is on by default, but a connection set up
+// with SetExecutionWithTransaction(false) applies the statements one by one, and a
+// failing migration can leave part of its changes behind.
+//
+// Two processes migrating at the same time cannot both record a version (it is the
+// primary key), the slower one fails and its migration is rolled back.
+type Migrator struct {
+	DB         orm.Database
+	Table      string      // Bookkeeping table, DEFAULT_MIGRATIONS_TABLE by default
+	Migrations []Migration // Sorted by version
+}
+
+// appliedMigration is a row of the migrations table
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// New loads the migrations from fsys and returns a Migrator for db
+func New(db orm.Database, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Table: DEFAULT_MIGRATIONS_TABLE, Migrations: migrations}, nil
+}
+
+// Load reads the migrations in the root directory of fsys, sorted by version.
+// Files without the .sql extension are ignored, so a README can live next to them.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMigration, entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMigration, entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d (%s and %s)", ErrDuplicateMigration, version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			if migration.Checksum != "" {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateMigration, entry.Name())
+			}
+			migration.Up = string(content)
+			migration.Checksum = Checksum(content)
+		} else {
+			if migration.HasDown {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateMigration, entry.Name())
+			}
+			migration.Down = string(content)
+			migration.HasDown = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("%w: %d_%s has a down file but no up file", ErrInvalidMigration, migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Checksum returns the hex SHA-256 of a migration file, as recorded in the migrations table
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Status lists every migration file and applied version, sorted by version.
+// Like Verify it only reads, the migrations table is created by the first Up.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	return m.StatusContext(context.Background())
+}
+
+// StatusContext is the context-aware variant of Status
+func (m *Migrator) StatusContext(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.status(applied), nil
+}
+
+// Verify returns ErrChecksumMismatch when an applied up file was modified and
+// ErrMissingMigration when an applied version has no file anymore
+func (m *Migrator) Verify() error {
+	return m.VerifyContext(context.Background())
+}
+
+// VerifyContext is the context-aware variant of Verify
+func (m *Migrator) VerifyContext(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	return verify(m.status(applied))
+}
+
+// Up applies every pending migration in version order and returns the applied ones.
+// It refuses to run when Verify fails. When a migration fails, the migrations applied
+// before it are returned together with the error.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.UpContext(context.Background())
+}
+
+// UpContext is the context-aware variant of Up
+func (m *Migrator) UpContext(ctx context.Context) ([]Migration, error) {
+	return m.up(ctx, math.MaxInt64)
+}
+
+// UpTo applies the pending migrations up to and including version
+func (m *Migrator) UpTo(version int64) ([]Migration, error) {
+	return m.UpToContext(context.Background(), version)
+}
+
+// UpToContext is the context-aware variant of UpTo
+func (m *Migrator) UpToContext(ctx context.Context, version int64) ([]Migration, error) {
+	if _, ok := m.find(version); !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return m.up(ctx, version)
+}
+
+// Down reverts the latest applied migration and returns it, nil when nothing is applied
+func (m *Migrator) Down() ([]Migration, error) {
+	return m.DownContext(context.Background())
+}
+
+// DownContext is the context-aware variant of Down
+func (m *Migrator) DownContext(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	// applied is sorted by version, revert everything above the previous one
+	target := int64(0)
+	if len(applied) > 1 {
+		target = applied[len(applied)-2].version
+	}
+	return m.down(ctx, applied, target)
+}
+
+// DownTo reverts the applied migrations above version, newest first, and returns them.
+// Version 0 reverts every migration. Every migration to revert must have a down file,
+// this is checked before anything is reverted.
+func (m *Migrator) DownTo(version int64) ([]Migration, error) {
+	return m.DownToContext(context.Background(), version)
+}
+
+// DownToContext is the context-aware variant of DownTo
+func (m *Migrator) DownToContext(ctx context.Context, version int64) ([]Migration, error) {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.down(ctx, applied, version)
+}
+
+// up applies the pending migrations with a version up to limit
+func (m *Migrator) up(ctx context.Context, limit int64) ([]Migration, error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(m.status(applied)); err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.version] = true
+	}
+
+	table := m.tableIdentifier()
+	dialect := m.DB.Dialect()
+	var result []Migration
+	for _, migration := range m.Migrations {
+		if migration.Version > limit {
+			break
+		}
+		if done[migration.Version] {
+			continue
+		}
+		sqls, err := m.splitStatements(migration.Up)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		sqls = append(sqls, fmt.Sprintf("INSERT INTO %s (version, name, checksum, applied_at) VALUES (%d, %s, %s, %s)",
+			table, migration.Version, orm.FormatLiteral(dialect, migration.Name),
+			orm.FormatLiteral(dialect, migration.Checksum), orm.FormatLiteral(dialect, time.Now().UTC())))
+		if err := m.exec(ctx, sqls); err != nil {
+			return result, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		result = append(result, migration)
+	}
+	return result, nil
+}
+
+// down reverts the applied migrations above target, newest first
+func (m *Migrator) down(ctx context.Context, applied []appliedMigration, target int64) ([]Migration, error) {
+	var pending []Migration
+	for i := len(applied) - 1; i >= 0 && applied[i].version > target; i-- {
+		migration, ok := m.find(applied[i].version)
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingMigration, applied[i].version, applied[i].name)
+		}
+		if !migration.HasDown {
+			return nil, fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+		}
+		pending = append(pending, migration)
+	}
+
+	table := m.tableIdentifier()
+	var result []Migration
+	for _, migration := range pending {
+		sqls, err := m.splitStatements(migration.Down)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		sqls = append(sqls, fmt.Sprintf("DELETE FROM %s WHERE version = %d", table, migration.Version))
+		if err := m.exec(ctx, sqls); err != nil {
+			return result, fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		result = append(result, migration)
+	}
+	return result, nil
+}
+
+// status merges the migration files with the applied versions
+func (m *Migrator) status(applied []appliedMigration) []MigrationStatus {
+	byVersion := make(map[int64]appliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.version] = a
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations)+len(applied))
+	for _, migration := range m.Migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := byVersion[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.appliedAt
+			status.Modified = a.checksum != migration.Checksum
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range byVersion {
+		statuses = append(statuses, MigrationStatus{Version: a.version, Name: a.name, Applied: true, AppliedAt: a.appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// verify returns the first drift found in statuses
+func verify(statuses []MigrationStatus) error {
+	for _, status := range statuses {
+		switch {
+		case status.Missing:
+			return fmt.Errorf("%w: %d_%s", ErrMissingMigration, status.Version, status.Name)
+		case status.Modified:
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// find returns the migration file with version
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// createTable creates the migrations table if it does not exist yet
+func (m *Migrator) createTable(ctx context.Context) error {
+	if err := orm.ValidateTableName(m.table()); err != nil {
+		return err
+	}
+
+	appliedAtType := "TIMESTAMP"
+	if m.DB.Dialect().Name() == (orm.PostgreSQLDialect{}).Name() {
+		appliedAtType = "TIMESTAMPTZ"
+	}
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL PRIMARY KEY, name TEXT NOT NULL, checksum TEXT NOT NULL, applied_at %s NOT NULL)",
+		m.tableIdentifier(), appliedAtType)
+	if err := m.exec(ctx, []string{create}); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return nil
+}
+
+// applied reads the migrations table, sorted by version. It only reads: a missing
+// table means nothing is applied yet.
+func (m *Migrator) applied(ctx context.Context) ([]appliedMigration, error) {
+	if err := orm.ValidateTableName(m.table()); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if db, ok := m.DB.(orm.DatabaseContext); ok {
+		_, err = db.DescribeTableContext(ctx, m.table())
+	} else {
+		_, err = m.DB.DescribeTable(m.table())
+	}
+	if errors.Is(err, orm.ErrTableNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations table: %w", err)
+	}
+
+	records, err := m.query(ctx, fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %s ORDER BY version", m.tableIdentifier()))
+	if errors.Is(err, orm.ErrSQLNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations table: %w", err)
+	}
+
+	applied := make([]appliedMigration, 0, len(records))
+	for _, record := range records {
+		var a appliedMigration
+		if a.version, err = record.Int64("version"); err != nil {
+			return nil, fmt.Errorf("failed to read migrations table: %w", err)
+		}
+		a.name, _ = record.String("name")
+		a.checksum, _ = record.String("checksum")
+		a.appliedAt, _ = record.Time("applied_at")
+		applied = append(applied, a)
+	}
+	return applied, nil
+}
+
+// table returns the bookkeeping table name
+func (m *Migrator) table() string {
+	if m.Table == "" {
+		return DEFAULT_MIGRATIONS_TABLE
+	}
+	return m.Table
+}
+
+// tableIdentifier returns the bookkeeping table name rendered for the database
+func (m *Migrator) tableIdentifier() string {
+	return orm.FormatIdentifier(m.DB.Dialect(), m.table())
+}
+
+// exec runs sqls in one transaction, rolled back when any statement fails
+func (m *Migrator) exec(ctx context.Context, sqls []string) error {
+	var tx orm.Transaction
+	var err error
+	if db, ok := m.DB.(orm.DatabaseContext); ok {
+		tx, err = db.BeginTransactionContext(ctx)
+	} else {
+		tx, err = m.DB.BeginTransaction()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var results []orm.BasicSQLResult
+	if txc, ok := tx.(orm.TransactionContext); ok {
+		results, err = txc.ExecManySQLContext(ctx, sqls)
+	} else {
+		results, err = tx.ExecManySQL(sqls)
+	}
+	if err == nil {
+		for _, result := range results {
+			if result.Error != nil {
+				err = result.Error
+				break
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// query runs a single SELECT
+func (m *Migrator) query(ctx context.Context, sql string) (orm.DBRecords, error) {
+	if db, ok := m.DB.(orm.DatabaseContext); ok {
+		return db.SelectOneSQLContext(ctx, sql)
+	}
+	return m.DB.SelectOneSQL(sql)
+}
+
+// splitStatements splits a migration file into statements with the quoting rules of the database
+func (m *Migrator) splitStatements(content string) ([]string, error) {
+	statements, err := orm.SplitSQL(m.DB.Dialect(), content)
+	if err != nil {
+		return nil, err
+	}
+	sqls := make([]string, len(statements))
+	for i, statement := range statements {
+		sqls[i] = statement.SQL
+	}
+	return sqls, nil
+}
@@ -0,0 +1,559 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	orm "github.com/medatechnology/simpleorm"
+)
+
+// errNotStubbed is returned by the fakeDB methods the migrator is not expected to call
+var errNotStubbed = errors.New("fakeDB: method not stubbed")
+
+// fakeDB keeps the migrations table in memory and records the committed batches.
+// It implements the whole orm.Database, the methods the migrator does not use return
+// errNotStubbed so an unexpected call fails the test instead of panicking.
+type fakeDB struct {
+	applied   map[int64][2]string // version -> name, checksum
+	batches   [][]string
+	failOn    string // executing this statement fails, like a PostgreSQL transaction
+	rollbacks int
+	created   bool // the migrations table was created
+}
+
+var (
+	insertPattern = regexp.MustCompile(`^INSERT INTO _migrations \(version, name, checksum, applied_at\) VALUES \((\d+), '([^']*)', '([^']*)', '[^']*'\)$`)
+	deletePattern = regexp.MustCompile(`^DELETE FROM _migrations WHERE version = (\d+)$`)
+)
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{applied: map[int64][2]string{}}
+}
+
+func (db *fakeDB) Dialect() orm.Dialect { return orm.SQLiteDialect{} }
+
+// commit applies a batch of a committed transaction
+func (db *fakeDB) commit(sqls []string) error {
+	if len(sqls) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(sqls[0], "CREATE TABLE IF NOT EXISTS _migrations") {
+		db.created = true
+		return nil
+	}
+	db.batches = append(db.batches, sqls)
+	last := sqls[len(sqls)-1]
+	if match := insertPattern.FindStringSubmatch(last); match != nil {
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		db.applied[version] = [2]string{match[2], match[3]}
+	} else if match := deletePattern.FindStringSubmatch(last); match != nil {
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		delete(db.applied, version)
+	}
+	return nil
+}
+
+func (db *fakeDB) SelectOneSQL(sql string) (orm.DBRecords, error) {
+	if len(db.applied) == 0 {
+		return nil, orm.ErrSQLNoRows
+	}
+	var records orm.DBRecords
+	for version, row := range db.applied {
+		records = append(records, orm.DBRecord{Data: map[string]interface{}{
+			"version": version, "name": row[0], "checksum": row[1], "applied_at": "2024-01-02 03:04:05",
+		}})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Data["version"].(int64) < records[j].Data["version"].(int64) })
+	return records, nil
+}
+
+func (db *fakeDB) GetSchema(bool, bool) []orm.SchemaStruct { return nil }
+
+func (db *fakeDB) ListTables() ([]string, error) { return nil, errNotStubbed }
+
+// DescribeTable reports the migrations table once it was created or seeded by a test
+func (db *fakeDB) DescribeTable(table string) (orm.TableInfo, error) {
+	if table != DEFAULT_MIGRATIONS_TABLE {
+		return orm.TableInfo{}, errNotStubbed
+	}
+	if !db.created && len(db.applied) == 0 {
+		return orm.TableInfo{}, orm.ErrTableNotFound
+	}
+	return orm.TableInfo{Name: table}, nil
+}
+
+func (db *fakeDB) Status() (orm.NodeStatusStruct, error) {
+	return orm.NodeStatusStruct{}, errNotStubbed
+}
+
+func (db *fakeDB) SelectOne(string) (orm.DBRecord, error) { return orm.DBRecord{}, errNotStubbed }
+
+func (db *fakeDB) SelectMany(string) (orm.DBRecords, error) { return nil, errNotStubbed }
+
+func (db *fakeDB) SelectOneWithCondition(string, *orm.Condition) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (db *fakeDB) SelectManyWithCondition(string, *orm.Condition) ([]orm.DBRecord, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) SelectManyComplex(*orm.ComplexQuery) ([]orm.DBRecord, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) SelectOneComplex(*orm.ComplexQuery) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (db *fakeDB) SelectPage(string, *orm.Condition, orm.PageOptions) (orm.Page, error) {
+	return orm.Page{}, errNotStubbed
+}
+
+func (db *fakeDB) SelectPageComplex(*orm.ComplexQuery, orm.PageOptions) (orm.Page, error) {
+	return orm.Page{}, errNotStubbed
+}
+
+func (db *fakeDB) StreamWithCondition(string, *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	return fakeStream()
+}
+
+func (db *fakeDB) StreamComplex(*orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	return fakeStream()
+}
+
+func (db *fakeDB) StreamSQLParameterized(orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return fakeStream()
+}
+
+func (db *fakeDB) SelectManySQL([]string) ([]orm.DBRecords, error) { return nil, errNotStubbed }
+
+func (db *fakeDB) SelectOnlyOneSQL(string) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (db *fakeDB) SelectOneSQLParameterized(orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) SelectManySQLParameterized([]orm.ParametereizedSQL) ([]orm.DBRecords, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) SelectOnlyOneSQLParameterized(orm.ParametereizedSQL) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (db *fakeDB) ExecOneSQL(string) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) ExecOneSQLParameterized(orm.ParametereizedSQL) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) ExecManySQL([]string) ([]orm.BasicSQLResult, error) { return nil, errNotStubbed }
+
+func (db *fakeDB) ExecManySQLParameterized([]orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) InsertOneDBRecord(orm.DBRecord, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) InsertManyDBRecords([]orm.DBRecord, bool) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) InsertManyDBRecordsSameTable([]orm.DBRecord, bool) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) InsertOneTableStruct(orm.TableStruct, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) InsertManyTableStructs([]orm.TableStruct, bool) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) UpsertOneDBRecord(orm.DBRecord, orm.UpsertOptions) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) UpsertManyDBRecordsSameTable([]orm.DBRecord, orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (db *fakeDB) IsConnected() bool { return true }
+
+func (db *fakeDB) Leader() (string, error) { return "", errNotStubbed }
+
+func (db *fakeDB) Peers() ([]string, error) { return nil, errNotStubbed }
+
+func (db *fakeDB) UpdateWithCondition(string, map[string]interface{}, *orm.Condition, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) DeleteWithCondition(string, *orm.Condition, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (db *fakeDB) BeginTransaction() (orm.Transaction, error) { return &fakeTx{db: db}, nil }
+
+// fakeTx buffers the statements of ExecManySQL and hands them to the fakeDB on Commit
+type fakeTx struct {
+	db   *fakeDB
+	sqls []string
+}
+
+func (tx *fakeTx) Commit() error { return tx.db.commit(tx.sqls) }
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.rollbacks++
+	tx.sqls = nil
+	return nil
+}
+
+func (tx *fakeTx) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
+	results := make([]orm.BasicSQLResult, len(sqls))
+	for i, sql := range sqls {
+		if sql == tx.db.failOn {
+			results[i].Error = fmt.Errorf("no such table: missing")
+		}
+	}
+	tx.sqls = append(tx.sqls, sqls...)
+	return results, nil
+}
+
+func (tx *fakeTx) ExecOneSQL(string) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) ExecOneSQLParameterized(orm.ParametereizedSQL) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) ExecManySQLParameterized([]orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) SelectOneSQL(string) (orm.DBRecords, error) { return nil, errNotStubbed }
+
+func (tx *fakeTx) SelectOnlyOneSQL(string) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (tx *fakeTx) SelectOneSQLParameterized(orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) SelectOnlyOneSQLParameterized(orm.ParametereizedSQL) (orm.DBRecord, error) {
+	return orm.DBRecord{}, errNotStubbed
+}
+
+func (tx *fakeTx) InsertOneDBRecord(orm.DBRecord) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) InsertManyDBRecords([]orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) InsertManyDBRecordsSameTable([]orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) InsertOneTableStruct(orm.TableStruct) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) InsertManyTableStructs([]orm.TableStruct) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) UpsertOneDBRecord(orm.DBRecord, orm.UpsertOptions) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) UpsertManyDBRecordsSameTable([]orm.DBRecord, orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (tx *fakeTx) UpdateWithCondition(string, map[string]interface{}, *orm.Condition, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+func (tx *fakeTx) DeleteWithCondition(string, *orm.Condition, bool) orm.BasicSQLResult {
+	return orm.BasicSQLResult{Error: errNotStubbed}
+}
+
+// fakeStream yields errNotStubbed once
+func fakeStream() iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		yield(orm.DBRecord{}, errNotStubbed)
+	}
+}
+
+var (
+	_ orm.Database    = (*fakeDB)(nil)
+	_ orm.Transaction = (*fakeTx)(nil)
+)
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY);\nCREATE INDEX users_id ON users (id); -- lookup\n")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;")},
+		"0003_seed.up.sql":           {Data: []byte("INSERT INTO users (id) VALUES (1);")},
+		"0003_seed.down.sql":         {Data: []byte("DELETE FROM users;")},
+		"README.md":                  {Data: []byte("migrations")},
+	}
+}
+
+func versions(migrations []Migration) []int64 {
+	var result []int64
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+	return result
+}
+
+// TestLoad checks migration files are paired, sorted and validated
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := versions(migrations); !reflect.DeepEqual(got, []int64{1, 2, 3}) {
+		t.Fatalf("Expected versions [1 2 3], got %v", got)
+	}
+	if migrations[0].Name != "create_users" || !migrations[0].HasDown || migrations[0].Down != "DROP TABLE users;" {
+		t.Errorf("Unexpected migration: %+v", migrations[0])
+	}
+	if migrations[1].HasDown || migrations[1].Checksum != Checksum([]byte("ALTER TABLE users ADD COLUMN email TEXT;")) {
+		t.Errorf("Unexpected migration: %+v", migrations[1])
+	}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr error
+	}{
+		{"invalid name", fstest.MapFS{"create_users.sql": {}}, ErrInvalidMigration},
+		{"version zero", fstest.MapFS{"0_init.up.sql": {}}, ErrInvalidMigration},
+		{"down without up", fstest.MapFS{"0001_init.down.sql": {}}, ErrInvalidMigration},
+		{"same version", fstest.MapFS{"0001_a.up.sql": {}, "1_b.up.sql": {}}, ErrDuplicateMigration},
+		{"same version different name", fstest.MapFS{"0001_a.up.sql": {}, "0001_b.down.sql": {}}, ErrDuplicateMigration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.files); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestStatusReadOnly checks inspecting a fresh database does not create the migrations table
+func TestStatusReadOnly(t *testing.T) {
+	db := newFakeDB()
+	migrator, err := New(db, testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 3 || statuses[0].Applied {
+		t.Errorf("Expected 3 pending migrations, got %+v", statuses)
+	}
+	if err := migrator.Verify(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if reverted, err := migrator.Down(); err != nil || reverted != nil {
+		t.Errorf("Expected nothing to revert, got %v, %v", reverted, err)
+	}
+	if db.created {
+		t.Errorf("Expected the migrations table not to be created")
+	}
+
+	if _, err := migrator.UpTo(1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !db.created {
+		t.Errorf("Expected Up to create the migrations table")
+	}
+}
+
+// TestUp checks pending migrations run one batch each, ending with the bookkeeping insert
+func TestUp(t *testing.T) {
+	db := newFakeDB()
+	migrator, err := New(db, testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	applied, err := migrator.UpTo(2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := versions(applied); !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Errorf("Expected versions [1 2], got %v", got)
+	}
+	if len(db.batches) != 2 || len(db.batches[0]) != 3 {
+		t.Fatalf("Expected 2 batches, the first with 3 statements, got %v", db.batches)
+	}
+	if db.batches[0][0] != "CREATE TABLE users (id INTEGER PRIMARY KEY)" || db.batches[0][1] != "CREATE INDEX users_id ON users (id)" {
+		t.Errorf("Unexpected statements: %v", db.batches[0])
+	}
+	if !insertPattern.MatchString(db.batches[0][2]) || db.applied[1][1] != migrator.Migrations[0].Checksum {
+		t.Errorf("Unexpected bookkeeping statement: %s", db.batches[0][2])
+	}
+
+	applied, err = migrator.Up()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := versions(applied); !reflect.DeepEqual(got, []int64{3}) {
+		t.Errorf("Expected versions [3], got %v", got)
+	}
+
+	applied, err = migrator.Up()
+	if err != nil || len(applied) != 0 || len(db.batches) != 3 {
+		t.Errorf("Expected nothing to apply, got %v, %v", applied, err)
+	}
+
+	if _, err := migrator.UpTo(7); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected %v, got %v", ErrUnknownVersion, err)
+	}
+}
+
+// TestUpFailure checks a failing migration stops the run and is not recorded
+func TestUpFailure(t *testing.T) {
+	db := newFakeDB()
+	db.failOn = "ALTER TABLE users ADD COLUMN email TEXT"
+	migrator, err := New(db, testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	applied, err := migrator.Up()
+	if err == nil || !strings.Contains(err.Error(), "2_add_email") {
+		t.Errorf("Expected the failing migration in the error, got %v", err)
+	}
+	if got := versions(applied); !reflect.DeepEqual(got, []int64{1}) {
+		t.Errorf("Expected versions [1], got %v", got)
+	}
+	if _, ok := db.applied[2]; ok || len(db.applied) != 1 {
+		t.Errorf("Expected only version 1 recorded, got %v", db.applied)
+	}
+	if len(db.batches) != 1 || db.rollbacks != 1 {
+		t.Errorf("Expected the failing migration rolled back, got %d rollbacks and batches %v", db.rollbacks, db.batches)
+	}
+}
+
+// TestDrift checks modified and missing migrations are reported and block Up
+func TestDrift(t *testing.T) {
+	db := newFakeDB()
+	migrator, err := New(db, testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	db.applied[1] = [2]string{"create_users", "0000"}
+	db.applied[9] = [2]string{"removed", "1111"}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []MigrationStatus{
+		{Version: 1, Name: "create_users", Applied: true, Modified: true},
+		{Version: 2, Name: "add_email"},
+		{Version: 3, Name: "seed"},
+		{Version: 9, Name: "removed", Applied: true, Missing: true},
+	}
+	for i := range statuses {
+		statuses[i].AppliedAt = statuses[i].AppliedAt.UTC()
+		if statuses[i].Applied {
+			want[i].AppliedAt = statuses[i].AppliedAt
+		}
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("Expected %+v, got %+v", want, statuses)
+	}
+	if statuses[0].AppliedAt.IsZero() {
+		t.Errorf("Expected AppliedAt to be read")
+	}
+
+	if err := migrator.Verify(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected %v, got %v", ErrChecksumMismatch, err)
+	}
+	delete(db.applied, 1)
+	if _, err := migrator.Up(); !errors.Is(err, ErrMissingMigration) {
+		t.Errorf("Expected %v, got %v", ErrMissingMigration, err)
+	}
+	if len(db.batches) != 0 {
+		t.Errorf("Expected nothing executed, got %v", db.batches)
+	}
+}
+
+// TestDown checks migrations are reverted newest first and need a down file
+func TestDown(t *testing.T) {
+	db := newFakeDB()
+	migrator, err := New(db, testFiles())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	db.batches = nil
+
+	reverted, err := migrator.Down()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := versions(reverted); !reflect.DeepEqual(got, []int64{3}) {
+		t.Errorf("Expected versions [3], got %v", got)
+	}
+	if want := []string{"DELETE FROM users", "DELETE FROM _migrations WHERE version = 3"}; !reflect.DeepEqual(db.batches[0], want) {
+		t.Errorf("Expected %v, got %v", want, db.batches[0])
+	}
+
+	// version 2 has no down file, nothing may be reverted
+	if _, err := migrator.DownTo(0); !errors.Is(err, ErrNoDownMigration) {
+		t.Errorf("Expected %v, got %v", ErrNoDownMigration, err)
+	}
+	if len(db.batches) != 1 || len(db.applied) != 2 {
+		t.Errorf("Expected nothing reverted, got %v", db.batches)
+	}
+
+	delete(db.applied, 2)
+	reverted, err = migrator.DownTo(0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := versions(reverted); !reflect.DeepEqual(got, []int64{1}) || len(db.applied) != 0 {
+		t.Errorf("Expected versions [1] and nothing applied, got %v, %v", got, db.applied)
+	}
+
+	reverted, err = migrator.Down()
+	if err != nil || reverted != nil {
+		t.Errorf("Expected nothing to revert, got %v, %v", reverted, err)
+	}
+	if _, err := migrator.DownTo(5); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected %v, got %v", ErrUnknownVersion, err)
+	}
+}
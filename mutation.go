@@ -0,0 +1,119 @@
+package orm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrEmptyUpdateSet   medaerror.MedaError = medaerror.MedaError{Message: "update requires at least one column to set"}
+	ErrMissingCondition medaerror.MedaError = medaerror.MedaError{Message: "refusing to update/delete without a WHERE condition: pass allRows=true to affect every row"}
+)
+
+// ToUpdateSQL builds a parameterized UPDATE statement from a column/value map
+// and a Condition for the WHERE clause. Columns are sorted so the generated SQL
+// (and its placeholders) is deterministic.
+// An empty condition is refused with ErrMissingCondition unless allRows is true,
+// so a forgotten filter cannot silently rewrite the whole table.
+//
+// Usage:
+//
+//	query, values, err := orm.ToUpdateSQL("users",
+//	    map[string]interface{}{"status": "inactive"},
+//	    &orm.Condition{Field: "last_login", Operator: "<", Value: "2024-01-01"},
+//	    false)
+//	// UPDATE users SET status = ? WHERE last_login < ?
+func ToUpdateSQL(tableName string, set map[string]interface{}, where *Condition, allRows bool) (string, []interface{}, error) {
+	return ToUpdateSQLDialect(defaultDialect, tableName, set, where, allRows)
+}
+
+// ToUpdateSQLDialect is ToUpdateSQL with placeholders rendered for the given dialect
+func ToUpdateSQLDialect(d Dialect, tableName string, set map[string]interface{}, where *Condition, allRows bool) (string, []interface{}, error) {
+	if err := ValidateTableName(tableName); err != nil {
+		return "", nil, err
+	}
+	if len(set) == 0 {
+		return "", nil, ErrEmptyUpdateSet
+	}
+
+	columns := make([]string, 0, len(set))
+	for col := range set {
+		if col == "" {
+			return "", nil, ErrInvalidFieldName
+		}
+		if err := ValidateFieldName(col); err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	b := newSQLBuilder(d)
+	setClauses := make([]string, 0, len(columns))
+	for _, col := range columns {
+		setClauses = append(setClauses, b.ident(col)+" = "+b.bind(set[col]))
+	}
+
+	whereClause, err := whereForMutation(b, where, allRows)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s", b.ident(tableName), strings.Join(setClauses, ", "))
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	return query, b.args, nil
+}
+
+// ToDeleteSQL builds a parameterized DELETE statement with the Condition as WHERE clause.
+// Like ToUpdateSQL it refuses an empty condition unless allRows is true.
+//
+// Usage:
+//
+//	query, values, err := orm.ToDeleteSQL("sessions",
+//	    &orm.Condition{Field: "expires_at", Operator: "<", Value: now}, false)
+//	// DELETE FROM sessions WHERE expires_at < ?
+func ToDeleteSQL(tableName string, where *Condition, allRows bool) (string, []interface{}, error) {
+	return ToDeleteSQLDialect(defaultDialect, tableName, where, allRows)
+}
+
+// ToDeleteSQLDialect is ToDeleteSQL with placeholders rendered for the given dialect
+func ToDeleteSQLDialect(d Dialect, tableName string, where *Condition, allRows bool) (string, []interface{}, error) {
+	if err := ValidateTableName(tableName); err != nil {
+		return "", nil, err
+	}
+
+	b := newSQLBuilder(d)
+	whereClause, err := whereForMutation(b, where, allRows)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := "DELETE FROM " + b.ident(tableName)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	return query, b.args, nil
+}
+
+// whereForMutation renders the WHERE clause for UPDATE/DELETE and enforces the allRows guard
+func whereForMutation(b *sqlBuilder, where *Condition, allRows bool) (string, error) {
+	if where == nil {
+		if !allRows {
+			return "", ErrMissingCondition
+		}
+		return "", nil
+	}
+	whereClause, err := where.writeWhere(b)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(whereClause) == "" && !allRows {
+		return "", ErrMissingCondition
+	}
+	return whereClause, nil
+}
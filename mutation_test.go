@@ -0,0 +1,196 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestToUpdateSQL tests UPDATE rendering and the all-rows guard
+func TestToUpdateSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    Dialect
+		table      string
+		set        map[string]interface{}
+		where      *Condition
+		allRows    bool
+		wantSQL    string
+		wantValues []interface{}
+		wantErr    error
+	}{
+		{
+			name:       "sorted columns then where values",
+			table:      "users",
+			set:        map[string]interface{}{"status": "inactive", "age": 30},
+			where:      &Condition{Field: "id", Operator: "=", Value: 7},
+			wantSQL:    "UPDATE users SET age = ?, status = ? WHERE id = ?",
+			wantValues: []interface{}{30, "inactive", 7},
+		},
+		{
+			name:    "postgres placeholders",
+			dialect: PostgreSQLDialect{},
+			table:   "users",
+			set:     map[string]interface{}{"status": "inactive"},
+			where: &Condition{Logic: "AND", Nested: []Condition{
+				{Field: "age", Operator: ">", Value: 18},
+				{Field: "role", Operator: "=", Value: "guest"},
+			}},
+			wantSQL:    "UPDATE users SET status = $1 WHERE (age > $2) AND (role = $3)",
+			wantValues: []interface{}{"inactive", 18, "guest"},
+		},
+		{
+			name:    "nil condition is refused",
+			table:   "users",
+			set:     map[string]interface{}{"status": "inactive"},
+			wantErr: ErrMissingCondition,
+		},
+		{
+			name:    "empty condition is refused",
+			table:   "users",
+			set:     map[string]interface{}{"status": "inactive"},
+			where:   &Condition{},
+			wantErr: ErrMissingCondition,
+		},
+		{
+			name:       "allRows updates every row",
+			table:      "users",
+			set:        map[string]interface{}{"status": "inactive"},
+			allRows:    true,
+			wantSQL:    "UPDATE users SET status = ?",
+			wantValues: []interface{}{"inactive"},
+		},
+		{
+			name:       "allRows with an empty condition",
+			table:      "users",
+			set:        map[string]interface{}{"status": "inactive"},
+			where:      &Condition{},
+			allRows:    true,
+			wantSQL:    "UPDATE users SET status = ?",
+			wantValues: []interface{}{"inactive"},
+		},
+		{
+			name:    "empty set",
+			table:   "users",
+			where:   &Condition{Field: "id", Operator: "=", Value: 7},
+			wantErr: ErrEmptyUpdateSet,
+		},
+		{
+			name:    "invalid column",
+			table:   "users",
+			set:     map[string]interface{}{"status; DROP TABLE users": "x"},
+			allRows: true,
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name:    "invalid table",
+			table:   "users; --",
+			set:     map[string]interface{}{"status": "x"},
+			allRows: true,
+			wantErr: ErrInvalidTableName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.dialect
+			if d == nil {
+				d = defaultDialect
+			}
+			sql, values, err := ToUpdateSQLDialect(d, tt.table, tt.set, tt.where, tt.allRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Expected %q, got %q", tt.wantSQL, sql)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Expected %v, got %v", tt.wantValues, values)
+			}
+		})
+	}
+}
+
+// TestToDeleteSQL tests DELETE rendering and the all-rows guard
+func TestToDeleteSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    Dialect
+		table      string
+		where      *Condition
+		allRows    bool
+		wantSQL    string
+		wantValues []interface{}
+		wantErr    error
+	}{
+		{
+			name:       "with condition",
+			table:      "sessions",
+			where:      &Condition{Field: "expires_at", Operator: "<", Value: "2024-01-01"},
+			wantSQL:    "DELETE FROM sessions WHERE expires_at < ?",
+			wantValues: []interface{}{"2024-01-01"},
+		},
+		{
+			name:       "postgres placeholders",
+			dialect:    PostgreSQLDialect{},
+			table:      "sessions",
+			where:      &Condition{Field: "user_id", Operator: "IN", Value: []interface{}{1, 2}},
+			wantSQL:    "DELETE FROM sessions WHERE user_id IN ($1, $2)",
+			wantValues: []interface{}{1, 2},
+		},
+		{
+			name:    "nil condition is refused",
+			table:   "sessions",
+			wantErr: ErrMissingCondition,
+		},
+		{
+			name:    "empty nested condition is refused",
+			table:   "sessions",
+			where:   &Condition{Logic: "AND", Nested: []Condition{}},
+			wantErr: ErrMissingCondition,
+		},
+		{
+			name:    "allRows deletes every row",
+			table:   "sessions",
+			allRows: true,
+			wantSQL: "DELETE FROM sessions",
+		},
+		{
+			name:    "invalid table",
+			table:   "sessions s",
+			allRows: true,
+			wantErr: ErrInvalidTableName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.dialect
+			if d == nil {
+				d = defaultDialect
+			}
+			sql, values, err := ToDeleteSQLDialect(d, tt.table, tt.where, tt.allRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Expected %q, got %q", tt.wantSQL, sql)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Expected %v, got %v", tt.wantValues, values)
+			}
+		})
+	}
+}
@@ -1,15 +1,31 @@
 package orm
 
+import (
+	"context"
+	"iter"
+)
+
 type Database interface {
 	GetSchema(bool, bool) []SchemaStruct
+	ListTables() ([]string, error)           // Names of the user tables
+	DescribeTable(string) (TableInfo, error) // Columns and constraints of a table, ErrTableNotFound if it does not exist
 	Status() (NodeStatusStruct, error)
+	Dialect() Dialect // SQL flavour used to render Condition / ComplexQuery for this backend
 
 	SelectOne(string) (DBRecord, error)   // This is almost unusable, very rare case
 	SelectMany(string) (DBRecords, error) // This is almost unusable, very rare case (this is like select ALL rows from the table)
 	SelectOneWithCondition(string, *Condition) (DBRecord, error)
 	SelectManyWithCondition(string, *Condition) ([]DBRecord, error)
-	SelectManyComplex(*ComplexQuery) ([]DBRecord, error) // Complex queries with JOINs, custom fields, GROUP BY, etc.
-	SelectOneComplex(*ComplexQuery) (DBRecord, error)    // Complex query that must return exactly one row
+	SelectManyComplex(*ComplexQuery) ([]DBRecord, error)        // Complex queries with JOINs, custom fields, GROUP BY, etc.
+	SelectOneComplex(*ComplexQuery) (DBRecord, error)           // Complex query that must return exactly one row
+	SelectPage(string, *Condition, PageOptions) (Page, error)   // One page (Limit/Offset) plus the total count of matching rows
+	SelectPageComplex(*ComplexQuery, PageOptions) (Page, error) // SelectPage for a ComplexQuery
+
+	// Streaming variants: rows are yielded one at a time instead of being collected in memory.
+	// An empty result yields nothing (no ErrSQLNoRows), a failure is yielded once as the error.
+	StreamWithCondition(string, *Condition) iter.Seq2[DBRecord, error]
+	StreamComplex(*ComplexQuery) iter.Seq2[DBRecord, error]
+	StreamSQLParameterized(ParametereizedSQL) iter.Seq2[DBRecord, error]
 
 	SelectOneSQL(string) (DBRecords, error)                              // select using one sql statement
 	SelectManySQL([]string) ([]DBRecords, error)                         // select using many sql statements
@@ -31,6 +47,10 @@ type Database interface {
 	InsertOneTableStruct(TableStruct, bool) BasicSQLResult
 	InsertManyTableStructs([]TableStruct, bool) ([]BasicSQLResult, error)
 
+	// Insert or update on conflict (INSERT ... ON CONFLICT), see UpsertOptions. Upserting no records is a no-op
+	UpsertOneDBRecord(DBRecord, UpsertOptions) BasicSQLResult
+	UpsertManyDBRecordsSameTable([]DBRecord, UpsertOptions) ([]BasicSQLResult, error)
+
 	// Status and Health check
 	IsConnected() bool
 	Leader() (string, error)  // this was originally for RQLite, if not then just return empty string or "not implemented"
@@ -43,8 +63,10 @@ type Database interface {
 	// we can't tell: update [table] set name=somename, age=10 where id=X
 	//           or : update [table] set id=x where name=somename AND age=10
 	// and there are still more other possibilities. Same with delete.
-	// What we can actually do is DeleteOneWithCondition and DeleteManyWithCondition
-	// but might as well do it with ExecRawSQL at this moment.
+	// So instead the WHERE part is always an explicit Condition. An empty condition is
+	// refused (ErrMissingCondition) unless the last argument (allRows) is true.
+	UpdateWithCondition(string, map[string]interface{}, *Condition, bool) BasicSQLResult // table, column->value, where, allRows
+	DeleteWithCondition(string, *Condition, bool) BasicSQLResult                         // table, where, allRows
 
 	// Transaction management
 	BeginTransaction() (Transaction, error) // Begin a new transaction
@@ -75,4 +97,88 @@ type Transaction interface {
 	InsertManyDBRecordsSameTable([]DBRecord) ([]BasicSQLResult, error)
 	InsertOneTableStruct(TableStruct) BasicSQLResult
 	InsertManyTableStructs([]TableStruct) ([]BasicSQLResult, error)
+	UpsertOneDBRecord(DBRecord, UpsertOptions) BasicSQLResult
+	UpsertManyDBRecordsSameTable([]DBRecord, UpsertOptions) ([]BasicSQLResult, error)
+
+	// Update and delete operations (see Database for the allRows guard)
+	UpdateWithCondition(string, map[string]interface{}, *Condition, bool) BasicSQLResult
+	DeleteWithCondition(string, *Condition, bool) BasicSQLResult
+}
+
+// DatabaseContext is the context-aware counterpart of Database. Every method takes a
+// context.Context as its first argument so callers can cancel an in-flight request or
+// attach a deadline to it (e.g. when the HTTP request that triggered the query goes away).
+// The plain Database methods behave like their Context variant called with context.Background().
+type DatabaseContext interface {
+	Database
+
+	StatusContext(context.Context) (NodeStatusStruct, error)
+	ListTablesContext(context.Context) ([]string, error)
+	DescribeTableContext(context.Context, string) (TableInfo, error)
+
+	SelectOneContext(context.Context, string) (DBRecord, error)
+	SelectManyContext(context.Context, string) (DBRecords, error)
+	SelectOneWithConditionContext(context.Context, string, *Condition) (DBRecord, error)
+	SelectManyWithConditionContext(context.Context, string, *Condition) ([]DBRecord, error)
+	SelectManyComplexContext(context.Context, *ComplexQuery) ([]DBRecord, error)
+	SelectOneComplexContext(context.Context, *ComplexQuery) (DBRecord, error)
+	SelectPageContext(context.Context, string, *Condition, PageOptions) (Page, error)
+	SelectPageComplexContext(context.Context, *ComplexQuery, PageOptions) (Page, error)
+	StreamWithConditionContext(context.Context, string, *Condition) iter.Seq2[DBRecord, error]
+	StreamComplexContext(context.Context, *ComplexQuery) iter.Seq2[DBRecord, error]
+	StreamSQLParameterizedContext(context.Context, ParametereizedSQL) iter.Seq2[DBRecord, error]
+
+	SelectOneSQLContext(context.Context, string) (DBRecords, error)
+	SelectManySQLContext(context.Context, []string) ([]DBRecords, error)
+	SelectOnlyOneSQLContext(context.Context, string) (DBRecord, error)
+	SelectOneSQLParameterizedContext(context.Context, ParametereizedSQL) (DBRecords, error)
+	SelectManySQLParameterizedContext(context.Context, []ParametereizedSQL) ([]DBRecords, error)
+	SelectOnlyOneSQLParameterizedContext(context.Context, ParametereizedSQL) (DBRecord, error)
+
+	ExecOneSQLContext(context.Context, string) BasicSQLResult
+	ExecOneSQLParameterizedContext(context.Context, ParametereizedSQL) BasicSQLResult
+	ExecManySQLContext(context.Context, []string) ([]BasicSQLResult, error)
+	ExecManySQLParameterizedContext(context.Context, []ParametereizedSQL) ([]BasicSQLResult, error)
+
+	InsertOneDBRecordContext(context.Context, DBRecord, bool) BasicSQLResult
+	InsertManyDBRecordsContext(context.Context, []DBRecord, bool) ([]BasicSQLResult, error)
+	InsertManyDBRecordsSameTableContext(context.Context, []DBRecord, bool) ([]BasicSQLResult, error)
+	InsertOneTableStructContext(context.Context, TableStruct, bool) BasicSQLResult
+	InsertManyTableStructsContext(context.Context, []TableStruct, bool) ([]BasicSQLResult, error)
+	UpsertOneDBRecordContext(context.Context, DBRecord, UpsertOptions) BasicSQLResult
+	UpsertManyDBRecordsSameTableContext(context.Context, []DBRecord, UpsertOptions) ([]BasicSQLResult, error)
+
+	UpdateWithConditionContext(context.Context, string, map[string]interface{}, *Condition, bool) BasicSQLResult
+	DeleteWithConditionContext(context.Context, string, *Condition, bool) BasicSQLResult
+
+	// The context passed here is bound to the whole transaction, like sql.DB.BeginTx
+	BeginTransactionContext(context.Context) (TransactionContext, error)
+}
+
+// TransactionContext is the context-aware counterpart of Transaction.
+// Commit and Rollback stay context-free (same as database/sql), the context given to
+// BeginTransactionContext governs the lifetime of the whole transaction.
+type TransactionContext interface {
+	Transaction
+
+	ExecOneSQLContext(context.Context, string) BasicSQLResult
+	ExecOneSQLParameterizedContext(context.Context, ParametereizedSQL) BasicSQLResult
+	ExecManySQLContext(context.Context, []string) ([]BasicSQLResult, error)
+	ExecManySQLParameterizedContext(context.Context, []ParametereizedSQL) ([]BasicSQLResult, error)
+
+	SelectOneSQLContext(context.Context, string) (DBRecords, error)
+	SelectOnlyOneSQLContext(context.Context, string) (DBRecord, error)
+	SelectOneSQLParameterizedContext(context.Context, ParametereizedSQL) (DBRecords, error)
+	SelectOnlyOneSQLParameterizedContext(context.Context, ParametereizedSQL) (DBRecord, error)
+
+	InsertOneDBRecordContext(context.Context, DBRecord) BasicSQLResult
+	InsertManyDBRecordsContext(context.Context, []DBRecord) ([]BasicSQLResult, error)
+	InsertManyDBRecordsSameTableContext(context.Context, []DBRecord) ([]BasicSQLResult, error)
+	InsertOneTableStructContext(context.Context, TableStruct) BasicSQLResult
+	InsertManyTableStructsContext(context.Context, []TableStruct) ([]BasicSQLResult, error)
+	UpsertOneDBRecordContext(context.Context, DBRecord, UpsertOptions) BasicSQLResult
+	UpsertManyDBRecordsSameTableContext(context.Context, []DBRecord, UpsertOptions) ([]BasicSQLResult, error)
+
+	UpdateWithConditionContext(context.Context, string, map[string]interface{}, *Condition, bool) BasicSQLResult
+	DeleteWithConditionContext(context.Context, string, *Condition, bool) BasicSQLResult
 }
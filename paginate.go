@@ -0,0 +1,361 @@
+package orm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrInvalidCursor      medaerror.MedaError = medaerror.MedaError{Message: "invalid pagination cursor: malformed, or created for a different sort order"}
+	ErrMissingSortKey     medaerror.MedaError = medaerror.MedaError{Message: "pagination requires PageRequest.OrderBy, ending with a unique column"}
+	ErrPaginationConflict medaerror.MedaError = medaerror.MedaError{Message: "paginated query must not set its own ORDER BY, LIMIT, OFFSET or Compound, use PageRequest instead"}
+	ErrNullSortKey        medaerror.MedaError = medaerror.MedaError{Message: "sort key column is NULL or missing from the result: keyset pagination needs NOT NULL key columns that are selected"}
+	ErrPaginationGroupBy  medaerror.MedaError = medaerror.MedaError{Message: "Paginate does not support Condition.GroupBy, use PaginateComplex"}
+)
+
+// PageRequest asks for one page of a keyset (cursor) paginated select.
+// OrderBy is the sort key: it must produce a total order, so end it with a unique
+// column (usually the primary key), and its columns must be NOT NULL and present in
+// the result under their unqualified name.
+// Leave both cursors empty for the first page, set After to a Page.NextCursor to move
+// forward or Before to a Page.PrevCursor to move back.
+type PageRequest struct {
+	After   string      `json:"after,omitempty"`  // Cursor of the last row already seen
+	Before  string      `json:"before,omitempty"` // Cursor of the first row already seen
+	Size    int         `json:"size,omitempty"`   // Rows per page (default: DEFAULT_PAGINATION_LIMIT)
+	OrderBy []OrderTerm `json:"order_by"`         // Sort key (required)
+}
+
+// Page is one page of a paginated select, either keyset (Paginate, with cursors) or
+// offset based (SelectPage, with Total, Limit and Offset). The cursors are opaque
+// strings, only set when there is a page in that direction.
+// Total is nil for Paginate, which does not count, so it is left out of the JSON
+// instead of reading as zero rows; SelectPage always sets it, 0 included.
+type Page struct {
+	Records    []DBRecord `json:"records"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	PrevCursor string     `json:"prev_cursor,omitempty"`
+	HasMore    bool       `json:"has_more"`         // More rows after this page
+	HasPrev    bool       `json:"has_prev"`         // Rows before this page
+	Total      *int64     `json:"total,omitempty"`  // Rows matching the query (SelectPage)
+	Limit      int        `json:"limit,omitempty"`  // Page size (SelectPage)
+	Offset     int        `json:"offset,omitempty"` // Rows skipped before this page (SelectPage)
+}
+
+// Paginate selects one page from table with keyset pagination: instead of OFFSET it
+// seeks past the cursor row with a predicate on the sort key, so every page costs the
+// same and concurrent inserts do not shift rows between pages.
+// The condition must not set OrderBy, OrderTerms, GroupBy, Limit or Offset.
+//
+// Usage:
+//
+//	page, err := orm.Paginate(db, "users", &orm.Condition{Field: "status", Operator: "=", Value: "active"},
+//	    orm.PageRequest{After: cursor, Size: 20, OrderBy: []orm.OrderTerm{{Field: "created_at", Desc: true}, {Field: "id"}}})
+//	// WHERE (status = ?) AND ((created_at < ?) OR ((created_at = ?) AND (id > ?))) ORDER BY created_at DESC, id ASC LIMIT 21
+//	// next request: orm.PageRequest{After: page.NextCursor, ...}
+func Paginate(db Database, table string, condition *Condition, req PageRequest) (Page, error) {
+	paged, state, err := req.pageCondition(condition)
+	if err != nil {
+		return Page{}, err
+	}
+	records, err := db.SelectManyWithCondition(table, paged)
+	if err != nil && !errors.Is(err, ErrSQLNoRows) {
+		return Page{}, err
+	}
+	return state.page(records)
+}
+
+// PaginateComplex is Paginate for a ComplexQuery. The query must not set OrderBy,
+// OrderTerms, OrderByExprs, Limit, Offset or Compound. Key fields may be qualified
+// (orders.created_at) but must be selected under their column name.
+func PaginateComplex(db Database, query *ComplexQuery, req PageRequest) (Page, error) {
+	paged, state, err := req.pageQuery(query)
+	if err != nil {
+		return Page{}, err
+	}
+	records, err := db.SelectManyComplex(paged)
+	if err != nil && !errors.Is(err, ErrSQLNoRows) {
+		return Page{}, err
+	}
+	return state.page(records)
+}
+
+// pageState is what is needed to turn the selected rows into a Page
+type pageState struct {
+	key      []OrderTerm
+	size     int
+	forward  bool
+	hasAfter bool
+}
+
+// prepare validates the request and returns the seek predicate (nil on the first page)
+// and the ORDER BY for the select, reversed when moving backwards
+func (req PageRequest) prepare() (pageState, *Condition, []OrderTerm, error) {
+	if len(req.OrderBy) == 0 {
+		return pageState{}, nil, nil, ErrMissingSortKey
+	}
+	if req.After != "" && req.Before != "" {
+		return pageState{}, nil, nil, ErrInvalidCursor
+	}
+	state := pageState{key: req.OrderBy, size: req.Size, forward: req.Before == "", hasAfter: req.After != ""}
+	if state.size < 1 {
+		state.size = DEFAULT_PAGINATION_LIMIT
+	}
+
+	// moving backwards reverses the whole order, explicit NULLS placement included
+	// (the database default already follows the direction)
+	order := make([]OrderTerm, len(req.OrderBy))
+	for i, term := range req.OrderBy {
+		order[i] = term
+		if state.forward {
+			continue
+		}
+		order[i].Desc = !term.Desc
+		switch NullsOrder(strings.ToUpper(string(term.Nulls))) {
+		case NullsFirst:
+			order[i].Nulls = NullsLast
+		case NullsLast:
+			order[i].Nulls = NullsFirst
+		}
+	}
+
+	cursor := req.After + req.Before
+	if cursor == "" {
+		return state, nil, order, nil
+	}
+	values, err := decodeCursor(cursor, req.OrderBy)
+	if err != nil {
+		return pageState{}, nil, nil, err
+	}
+	return state, seekCondition(order, values), order, nil
+}
+
+// pageCondition wraps the condition for Paginate. The wrapper would drop a GROUP BY,
+// which changes the rows selected, so it is refused.
+func (req PageRequest) pageCondition(condition *Condition) (*Condition, pageState, error) {
+	var where Condition
+	if condition != nil {
+		where = *condition
+	}
+	if len(where.OrderBy) > 0 || len(where.OrderTerms) > 0 || where.Limit != 0 || where.Offset != 0 {
+		return nil, pageState{}, ErrPaginationConflict
+	}
+	if len(where.GroupBy) > 0 {
+		return nil, pageState{}, ErrPaginationGroupBy
+	}
+	state, seek, order, err := req.prepare()
+	if err != nil {
+		return nil, pageState{}, err
+	}
+	paged := &Condition{Logic: "AND", Nested: []Condition{where}, OrderTerms: order, Limit: state.size + 1}
+	if seek != nil {
+		paged.Nested = append(paged.Nested, *seek)
+	}
+	return paged, state, nil
+}
+
+// pageQuery copies the query for PaginateComplex
+func (req PageRequest) pageQuery(query *ComplexQuery) (*ComplexQuery, pageState, error) {
+	if query == nil || query.Compound != nil || len(query.OrderBy) > 0 || len(query.OrderTerms) > 0 ||
+		len(query.OrderByExprs) > 0 || query.Limit != 0 || query.Offset != 0 {
+		return nil, pageState{}, ErrPaginationConflict
+	}
+	state, seek, order, err := req.prepare()
+	if err != nil {
+		return nil, pageState{}, err
+	}
+	paged := *query
+	paged.OrderTerms = order
+	paged.Limit = state.size + 1
+	if seek != nil {
+		where := &Condition{Logic: "AND", Nested: []Condition{*seek}}
+		if query.Where != nil {
+			where.Nested = []Condition{*query.Where, *seek}
+		}
+		paged.Where = where
+	}
+	return &paged, state, nil
+}
+
+// seekCondition builds the predicate selecting the rows after values in the given order:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with < for descending terms
+func seekCondition(order []OrderTerm, values []interface{}) *Condition {
+	seek := &Condition{Logic: "OR"}
+	for i, term := range order {
+		branch := Condition{Logic: "AND"}
+		for j := 0; j < i; j++ {
+			branch.Nested = append(branch.Nested, Condition{Field: order[j].Field, Operator: "=", Value: values[j]})
+		}
+		op := ">"
+		if term.Desc {
+			op = "<"
+		}
+		leaf := Condition{Field: term.Field, Operator: op, Value: values[i]}
+		if i == 0 {
+			seek.Nested = append(seek.Nested, leaf)
+			continue
+		}
+		branch.Nested = append(branch.Nested, leaf)
+		seek.Nested = append(seek.Nested, branch)
+	}
+	if len(seek.Nested) == 1 {
+		return &seek.Nested[0]
+	}
+	return seek
+}
+
+// page trims the extra row used to detect a further page and builds the cursors
+func (s pageState) page(records []DBRecord) (Page, error) {
+	hasMore := len(records) > s.size
+	if hasMore {
+		records = records[:s.size]
+	}
+	if !s.forward {
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+	}
+
+	page := Page{Records: records}
+	if s.forward {
+		page.HasMore, page.HasPrev = hasMore, s.hasAfter
+	} else {
+		// we came back from the page after this one
+		page.HasMore, page.HasPrev = true, hasMore
+	}
+	if len(records) == 0 {
+		return page, nil
+	}
+
+	var err error
+	if page.HasMore {
+		if page.NextCursor, err = encodeCursor(records[len(records)-1], s.key); err != nil {
+			return Page{}, err
+		}
+	}
+	if page.HasPrev {
+		if page.PrevCursor, err = encodeCursor(records[0], s.key); err != nil {
+			return Page{}, err
+		}
+	}
+	return page, nil
+}
+
+// cursorPayload is the JSON inside a cursor
+type cursorPayload struct {
+	Key    string        `json:"k"` // fingerprint of the sort key
+	Values []cursorValue `json:"v"`
+}
+
+// cursorValue keeps the Go type of values JSON cannot tell apart from strings
+type cursorValue struct {
+	Type  string      `json:"t,omitempty"` // "time" or "bytes"
+	Value interface{} `json:"v"`
+}
+
+// keyFingerprint identifies the sort key, so a cursor cannot be used with another order
+func keyFingerprint(key []OrderTerm) string {
+	h := fnv.New32a()
+	for _, term := range key {
+		fmt.Fprintf(h, "%s:%t,", identifierKey(term.Field), term.Desc)
+	}
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// keyValue finds the value of a sort key field in a result row
+func keyValue(record DBRecord, field string) (interface{}, bool) {
+	parts := splitIdentifier(field)
+	column := parts[len(parts)-1]
+	if strings.HasPrefix(column, `"`) {
+		value, ok := record.Data[strings.Trim(column, `"`)]
+		return value, ok
+	}
+	if value, ok := record.Data[column]; ok {
+		return value, true
+	}
+	for name, value := range record.Data {
+		if strings.EqualFold(name, column) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// encodeCursor encodes the sort key values of a row as an opaque cursor
+func encodeCursor(record DBRecord, key []OrderTerm) (string, error) {
+	payload := cursorPayload{Key: keyFingerprint(key), Values: make([]cursorValue, len(key))}
+	for i, term := range key {
+		value, ok := keyValue(record, term.Field)
+		if !ok || value == nil {
+			return "", fmt.Errorf("%w: %s", ErrNullSortKey, term.Field)
+		}
+		switch v := value.(type) {
+		case time.Time:
+			payload.Values[i] = cursorValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+		case []byte:
+			payload.Values[i] = cursorValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(v)}
+		default:
+			payload.Values[i] = cursorValue{Value: v}
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes a cursor created by encodeCursor for the same sort key
+func decodeCursor(cursor string, key []OrderTerm) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Key != keyFingerprint(key) || len(payload.Values) != len(key) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(key))
+	for i, cv := range payload.Values {
+		switch v := cv.Value.(type) {
+		case json.Number:
+			values[i] = JSONNumberValue(v)
+		case string:
+			switch cv.Type {
+			case "time":
+				t, err := time.Parse(time.RFC3339Nano, v)
+				if err != nil {
+					return nil, ErrInvalidCursor
+				}
+				values[i] = t
+			case "bytes":
+				b, err := base64.StdEncoding.DecodeString(v)
+				if err != nil {
+					return nil, ErrInvalidCursor
+				}
+				values[i] = b
+			default:
+				values[i] = v
+			}
+		case bool:
+			values[i] = v
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,278 @@
+package orm
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func pageRows(ids ...int64) []DBRecord {
+	records := make([]DBRecord, len(ids))
+	for i, id := range ids {
+		records[i] = DBRecord{TableName: "users", Data: map[string]interface{}{"id": id, "name": "user"}}
+	}
+	return records
+}
+
+func pageIDs(records []DBRecord) []int64 {
+	ids := make([]int64, len(records))
+	for i, r := range records {
+		ids[i] = r.Data["id"].(int64)
+	}
+	return ids
+}
+
+// TestPaginateBackwardNulls checks moving backwards also swaps explicit NULLS placement
+func TestPaginateBackwardNulls(t *testing.T) {
+	key := []OrderTerm{{Field: "score", Desc: true, Nulls: NullsLast}, {Field: "rank", Nulls: "first"}, {Field: "id"}}
+	cursor, err := encodeCursor(DBRecord{Data: map[string]interface{}{"score": int64(7), "rank": int64(1), "id": int64(42)}}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  PageRequest
+		want []OrderTerm
+	}{
+		{
+			name: "after keeps the order",
+			req:  PageRequest{After: cursor, OrderBy: key},
+			want: key,
+		},
+		{
+			name: "before reverses direction and nulls",
+			req:  PageRequest{Before: cursor, OrderBy: key},
+			want: []OrderTerm{{Field: "score", Nulls: NullsFirst}, {Field: "rank", Desc: true, Nulls: NullsLast}, {Field: "id", Desc: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paged, _, err := tt.req.pageCondition(nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(paged.OrderTerms, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, paged.OrderTerms)
+			}
+		})
+	}
+}
+
+// TestPaginateSeekSQL checks the seek predicate and ORDER BY for each direction
+func TestPaginateSeekSQL(t *testing.T) {
+	key := []OrderTerm{{Field: "created_at", Desc: true}, {Field: "id"}}
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123000000, time.UTC)
+	cursor, err := encodeCursor(DBRecord{Data: map[string]interface{}{"created_at": created, "ID": int64(42)}}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	where := &Condition{Field: "status", Operator: "=", Value: "active"}
+
+	tests := []struct {
+		name       string
+		req        PageRequest
+		want       string
+		wantValues []interface{}
+	}{
+		{
+			name:       "first page",
+			req:        PageRequest{Size: 10, OrderBy: key},
+			want:       "SELECT * FROM users WHERE (status = $1)  ORDER BY created_at DESC, id ASC LIMIT 11",
+			wantValues: []interface{}{"active"},
+		},
+		{
+			name: "after",
+			req:  PageRequest{After: cursor, Size: 10, OrderBy: key},
+			want: "SELECT * FROM users WHERE (status = $1) AND ((created_at < $2) OR ((created_at = $3) AND (id > $4)))  " +
+				"ORDER BY created_at DESC, id ASC LIMIT 11",
+			wantValues: []interface{}{"active", created, created, int64(42)},
+		},
+		{
+			name: "before",
+			req:  PageRequest{Before: cursor, Size: 10, OrderBy: key},
+			want: "SELECT * FROM users WHERE (status = $1) AND ((created_at > $2) OR ((created_at = $3) AND (id < $4)))  " +
+				"ORDER BY created_at ASC, id DESC LIMIT 11",
+			wantValues: []interface{}{"active", created, created, int64(42)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paged, _, err := tt.req.pageCondition(where)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got, values, err := paged.ToSelectStringDialect(PostgreSQLDialect{}, "users")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+			}
+		})
+	}
+}
+
+// TestPaginate checks page trimming, direction and cursors through a stub database
+func TestPaginate(t *testing.T) {
+	key := []OrderTerm{{Field: "users.id"}}
+
+	db := &stubDB{rows: pageRows(1, 2, 3)}
+	first, err := Paginate(db, "users", nil, PageRequest{Size: 2, OrderBy: key})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := pageIDs(first.Records); !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Errorf("Expected ids [1 2], got %v", ids)
+	}
+	if !first.HasMore || first.HasPrev || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Errorf("Unexpected first page navigation: %+v", first)
+	}
+
+	// keyset pages are not counted, a "total":0 would read as no rows
+	data, _ := json.Marshal(first)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := decoded["total"]; ok || decoded["has_more"] != true {
+		t.Errorf("Expected has_more and no total in %s", data)
+	}
+
+	// the database answers the reversed order for a backwards page: 3, 2, 1
+	db.rows = pageRows(3, 2, 1)
+	back, err := Paginate(db, "users", nil, PageRequest{Before: first.NextCursor, Size: 2, OrderBy: key})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ids := pageIDs(back.Records); !reflect.DeepEqual(ids, []int64{2, 3}) {
+		t.Errorf("Expected ids [2 3], got %v", ids)
+	}
+	if !back.HasMore || !back.HasPrev || back.PrevCursor == "" {
+		t.Errorf("Unexpected backwards page navigation: %+v", back)
+	}
+	if got := db.condition.OrderTerms; !reflect.DeepEqual(got, []OrderTerm{{Field: "users.id", Desc: true}}) {
+		t.Errorf("Expected reversed order, got %v", got)
+	}
+
+	db.rows = nil
+	last, err := Paginate(db, "users", nil, PageRequest{After: first.NextCursor, Size: 2, OrderBy: key})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(last.Records) != 0 || last.HasMore || !last.HasPrev || last.NextCursor != "" {
+		t.Errorf("Unexpected empty page: %+v", last)
+	}
+}
+
+// TestPaginateComplex checks the seek predicate is ANDed with the query WHERE
+func TestPaginateComplex(t *testing.T) {
+	key := []OrderTerm{{Field: "o.id"}}
+	cursor, err := encodeCursor(DBRecord{Data: map[string]interface{}{"id": int64(7)}}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query := &ComplexQuery{
+		Select:    []string{"o.id", "o.total"},
+		From:      "orders",
+		FromAlias: "o",
+		Where:     &Condition{Field: "o.status", Operator: "=", Value: "paid"},
+	}
+
+	db := &stubDB{rows: pageRows(8)}
+	page, err := PaginateComplex(db, query, PageRequest{After: cursor, Size: 5, OrderBy: key})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if page.HasMore || !page.HasPrev {
+		t.Errorf("Unexpected navigation: %+v", page)
+	}
+
+	want := "SELECT o.id, o.total FROM orders AS o WHERE (o.status = ?) AND (o.id > ?) ORDER BY o.id ASC LIMIT 6"
+	got, values, err := db.query.ToSQL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"paid", int64(7)}) {
+		t.Errorf("Expected values [paid 7], got %v", values)
+	}
+	if query.Limit != 0 || len(query.OrderTerms) != 0 {
+		t.Errorf("Expected the original query to be left untouched, got %+v", query)
+	}
+}
+
+// TestPaginateErrors checks invalid requests and cursors are rejected
+func TestPaginateErrors(t *testing.T) {
+	key := []OrderTerm{{Field: "id"}}
+	cursor, err := encodeCursor(DBRecord{Data: map[string]interface{}{"id": "a"}}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		condition *Condition
+		req       PageRequest
+		wantErr   error
+	}{
+		{name: "missing sort key", req: PageRequest{}, wantErr: ErrMissingSortKey},
+		{name: "both cursors", req: PageRequest{After: cursor, Before: cursor, OrderBy: key}, wantErr: ErrInvalidCursor},
+		{name: "garbage cursor", req: PageRequest{After: "not a cursor!", OrderBy: key}, wantErr: ErrInvalidCursor},
+		{name: "cursor for another order", req: PageRequest{After: cursor, OrderBy: []OrderTerm{{Field: "id", Desc: true}}}, wantErr: ErrInvalidCursor},
+		{name: "condition with offset", condition: &Condition{Offset: 10}, req: PageRequest{OrderBy: key}, wantErr: ErrPaginationConflict},
+		{name: "condition with order", condition: &Condition{OrderBy: []string{"name"}}, req: PageRequest{OrderBy: key}, wantErr: ErrPaginationConflict},
+		{name: "condition with group by", condition: &Condition{GroupBy: []string{"country"}}, req: PageRequest{OrderBy: key}, wantErr: ErrPaginationGroupBy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Paginate(&stubDB{}, "users", tt.condition, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("null sort key", func(t *testing.T) {
+		db := &stubDB{rows: []DBRecord{{Data: map[string]interface{}{"id": nil}}, {Data: map[string]interface{}{"id": nil}}}}
+		if _, err := Paginate(db, "users", nil, PageRequest{Size: 1, OrderBy: key}); !errors.Is(err, ErrNullSortKey) {
+			t.Errorf("Expected %v, got %v", ErrNullSortKey, err)
+		}
+	})
+
+	t.Run("compound query", func(t *testing.T) {
+		query := Combine(Union, &ComplexQuery{From: "a"}, &ComplexQuery{From: "b"})
+		if _, err := PaginateComplex(&stubDB{}, query, PageRequest{OrderBy: key}); !errors.Is(err, ErrPaginationConflict) {
+			t.Errorf("Expected %v, got %v", ErrPaginationConflict, err)
+		}
+	})
+}
+
+// TestCursorRoundTrip checks cursor values keep their Go type
+func TestCursorRoundTrip(t *testing.T) {
+	key := []OrderTerm{{Field: "a"}, {Field: "b"}, {Field: "c"}, {Field: "d"}, {Field: "e"}}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	record := DBRecord{Data: map[string]interface{}{"a": int64(9007199254740993), "b": 1.5, "c": "x", "d": created, "e": []byte{0, 1}}}
+
+	cursor, err := encodeCursor(record, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	values, err := decodeCursor(cursor, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []interface{}{int64(9007199254740993), 1.5, "x", created, []byte{0, 1}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Expected %v, got %v", want, values)
+	}
+}
@@ -46,6 +46,9 @@ type PostgresConfig struct {
 	SearchPath      string            // Schema search path (optional)
 	Timezone        string            // Timezone (optional, e.g., "UTC")
 	ExtraParams     map[string]string // Additional connection parameters (optional)
+
+	// Query building
+	QuoteMixedCaseIdentifiers bool // Quote identifiers with upper case letters, e.g. "createdAt" (default: false)
 }
 
 // NewDefaultConfig creates a new PostgresConfig with default values
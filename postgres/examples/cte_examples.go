@@ -172,25 +172,36 @@ func ExampleRecursiveCTE() {
 	}
 
 	// Example 3: Recursive CTE - organizational hierarchy
-	// Note: For complex recursive CTEs, you might want to use RawSQL
+	// The anchor and recursive members are combined with UNION ALL
 	query := &orm.ComplexQuery{
 		CTEs: []orm.CommonTableExpression{
 			{
 				Name:      "org_hierarchy",
 				Recursive: true,
-				RawSQL: `
-					SELECT id, name, manager_id, 1 as level
-					FROM employees
-					WHERE manager_id IS NULL
-					UNION ALL
-					SELECT e.id, e.name, e.manager_id, oh.level + 1
-					FROM employees e
-					INNER JOIN org_hierarchy oh ON e.manager_id = oh.id
-				`,
+				Query: orm.Combine(orm.UnionAll,
+					&orm.ComplexQuery{
+						Select: []string{"id", "name", "manager_id", "1 as level"},
+						From:   "employees",
+						Where:  &orm.Condition{Field: "manager_id", Operator: "IS NULL"},
+					},
+					&orm.ComplexQuery{
+						Select:    []string{"e.id", "e.name", "e.manager_id", "oh.level + 1"},
+						From:      "employees",
+						FromAlias: "e",
+						Joins: []orm.Join{
+							{
+								Type:      orm.InnerJoin,
+								Table:     "org_hierarchy",
+								Alias:     "oh",
+								Condition: "e.manager_id = oh.id",
+							},
+						},
+					},
+				),
 			},
 		},
-		Select: []string{"id", "name", "level"},
-		From:   "org_hierarchy",
+		Select:  []string{"id", "name", "level"},
+		From:    "org_hierarchy",
 		OrderBy: []string{"level", "name"},
 	}
 
@@ -207,8 +218,8 @@ func ExampleRecursiveCTE() {
 	//   WHERE manager_id IS NULL
 	//   UNION ALL
 	//   SELECT e.id, e.name, e.manager_id, oh.level + 1
-	//   FROM employees e
-	//   INNER JOIN org_hierarchy oh ON e.manager_id = oh.id
+	//   FROM employees AS e
+	//   INNER JOIN org_hierarchy AS oh ON e.manager_id = oh.id
 	// )
 	// SELECT id, name, level FROM org_hierarchy ORDER BY level, name
 
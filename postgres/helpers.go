@@ -9,45 +9,72 @@ import (
 	orm "github.com/medatechnology/simpleorm"
 )
 
-// scanRowToDBRecord converts a single sql.Rows to a DBRecord
-func scanRowToDBRecord(rows *sql.Rows, tableName string) (orm.DBRecord, error) {
+// rowScanner converts the rows of one result to DBRecords, reading the columns and their types once
+type rowScanner struct {
+	tableName string
+	columns   []string
+	types     map[string]string // shared by every record of the result
+}
+
+// newRowScanner reads the columns and column types of rows
+func newRowScanner(rows *sql.Rows, tableName string) (*rowScanner, error) {
 	columns, err := rows.Columns()
 	if err != nil {
-		return orm.DBRecord{}, fmt.Errorf("failed to get columns: %w", err)
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	types := make(map[string]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		types[columns[i]] = columnType.DatabaseTypeName()
 	}
 
+	return &rowScanner{tableName: tableName, columns: columns, types: types}, nil
+}
+
+// scan converts the current row to a DBRecord
+func (s *rowScanner) scan(rows *sql.Rows) (orm.DBRecord, error) {
 	// Create slice to hold column values
-	values := make([]interface{}, len(columns))
-	valuePtrs := make([]interface{}, len(columns))
+	values := make([]interface{}, len(s.columns))
+	valuePtrs := make([]interface{}, len(s.columns))
 
-	for i := range columns {
+	for i := range s.columns {
 		valuePtrs[i] = &values[i]
 	}
 
 	// Scan the row
-	err = rows.Scan(valuePtrs...)
+	err := rows.Scan(valuePtrs...)
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to scan row: %w", err)
 	}
 
 	// Convert to map
 	data := make(map[string]interface{})
-	for i, col := range columns {
+	for i, col := range s.columns {
 		data[col] = convertPostgreSQLValue(values[i])
 	}
 
 	return orm.DBRecord{
-		TableName: tableName,
+		TableName: s.tableName,
 		Data:      data,
+		Types:     s.types,
 	}, nil
 }
 
 // scanRowsToDBRecords converts sql.Rows to DBRecords
 func scanRowsToDBRecords(rows *sql.Rows, tableName string) (orm.DBRecords, error) {
+	scanner, err := newRowScanner(rows, tableName)
+	if err != nil {
+		return nil, err
+	}
+
 	var records orm.DBRecords
 
 	for rows.Next() {
-		record, err := scanRowToDBRecord(rows, tableName)
+		record, err := scanner.scan(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -318,44 +345,6 @@ func validatePostgreSQLConnection(db *sql.DB) error {
 	return nil
 }
 
-// convertToPostgreSQLPlaceholders converts ? placeholders to PostgreSQL $N placeholders
-func convertToPostgreSQLPlaceholders(query string) string {
-	paramIndex := 1
-	result := ""
-	inQuote := false
-	quoteChar := byte(0)
-
-	for i := 0; i < len(query); i++ {
-		ch := query[i]
-
-		// Track if we're inside a quoted string
-		if ch == '\'' || ch == '"' {
-			if !inQuote {
-				inQuote = true
-				quoteChar = ch
-			} else if ch == quoteChar {
-				// Check if it's an escaped quote
-				if i+1 < len(query) && query[i+1] == ch {
-					result += string(ch)
-					i++ // Skip next character
-					continue
-				}
-				inQuote = false
-			}
-		}
-
-		// Replace ? with $N if not in a quote
-		if ch == '?' && !inQuote {
-			result += fmt.Sprintf("$%d", paramIndex)
-			paramIndex++
-		} else {
-			result += string(ch)
-		}
-	}
-
-	return result
-}
-
 // getPostgreSQLStats retrieves PostgreSQL database statistics
 func getPostgreSQLStats(db *sql.DB, dbName string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -2,14 +2,20 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"iter"
 	"strings"
 
 	_ "github.com/lib/pq"
 	orm "github.com/medatechnology/simpleorm"
 )
 
+// Ensure postgres satisfies both the plain and the context-aware interfaces
+var _ orm.DatabaseContext = (*postgres)(nil)
+
 // postgres implements the orm.Database interface for PostgreSQL.
 type postgres struct {
 	db     *sql.DB        // The underlying database connection pool
@@ -67,11 +73,21 @@ func (pdb *postgres) Close() error {
 	return pdb.db.Close()
 }
 
+// Dialect returns the PostgreSQL dialect, used to render every generated query.
+func (pdb *postgres) Dialect() orm.Dialect {
+	return orm.PostgreSQLDialect{QuoteMixedCase: pdb.config.QuoteMixedCaseIdentifiers}
+}
+
 // SelectOne retrieves a single record from the specified table.
 // It returns a orm.DBRecord or an error if no record is found.
 func (pdb *postgres) SelectOne(tableName string) (orm.DBRecord, error) {
+	return pdb.SelectOneContext(context.Background(), tableName)
+}
+
+// SelectOneContext is the context-aware variant of SelectOne
+func (pdb *postgres) SelectOneContext(ctx context.Context, tableName string) (orm.DBRecord, error) {
 	query := fmt.Sprintf("SELECT * FROM %s LIMIT 1", tableName)
-	rows, err := pdb.db.Query(query)
+	rows, err := pdb.db.QueryContext(ctx, query)
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to execute SelectOne query: %w", err)
 	}
@@ -92,8 +108,13 @@ func (pdb *postgres) SelectOne(tableName string) (orm.DBRecord, error) {
 // SelectMany retrieves multiple records from the specified table.
 // It returns a slice of orm.DBRecord or an error.
 func (pdb *postgres) SelectMany(tableName string) (orm.DBRecords, error) {
+	return pdb.SelectManyContext(context.Background(), tableName)
+}
+
+// SelectManyContext is the context-aware variant of SelectMany
+func (pdb *postgres) SelectManyContext(ctx context.Context, tableName string) (orm.DBRecords, error) {
 	query := fmt.Sprintf("SELECT * FROM %s", tableName)
-	rows, err := pdb.db.Query(query)
+	rows, err := pdb.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute SelectMany query: %w", err)
 	}
@@ -114,6 +135,11 @@ func (pdb *postgres) SelectMany(tableName string) (orm.DBRecords, error) {
 // InsertOneDBRecord inserts a single DBRecord into the specified table.
 // It returns the last insert ID (if applicable) and the number of rows affected.
 func (pdb *postgres) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.BasicSQLResult {
+	return pdb.InsertOneDBRecordContext(context.Background(), record, queue)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (pdb *postgres) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, queue bool) orm.BasicSQLResult {
 	// Validate table name
 	if err := orm.ValidateTableName(record.TableName); err != nil {
 		return orm.BasicSQLResult{Error: err}
@@ -139,7 +165,7 @@ func (pdb *postgres) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.Basi
 	)
 
 	var lastInsertID int64
-	err := pdb.db.QueryRow(query, values...).Scan(&lastInsertID)
+	err := pdb.db.QueryRowContext(ctx, query, values...).Scan(&lastInsertID)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -150,6 +176,11 @@ func (pdb *postgres) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.Basi
 
 // InsertManyDBRecords inserts multiple DBRecords into the database.
 func (pdb *postgres) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return pdb.InsertManyDBRecordsContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (pdb *postgres) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	if len(records) == 0 {
 		return nil, nil
 	}
@@ -157,7 +188,7 @@ func (pdb *postgres) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]
 	results := make([]orm.BasicSQLResult, 0, len(records))
 
 	for _, record := range records {
-		result := pdb.InsertOneDBRecord(record, queue)
+		result := pdb.InsertOneDBRecordContext(ctx, record, queue)
 		results = append(results, result)
 		if result.Error != nil {
 			return results, result.Error
@@ -170,6 +201,11 @@ func (pdb *postgres) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]
 // InsertManyDBRecordsSameTable inserts multiple DBRecords from the same table efficiently.
 // Uses PostgreSQL-specific multi-row INSERT for better performance.
 func (pdb *postgres) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return pdb.InsertManyDBRecordsSameTableContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (pdb *postgres) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	if len(records) == 0 {
 		return nil, nil
 	}
@@ -199,7 +235,7 @@ func (pdb *postgres) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue
 	}
 
 	// Execute the batch INSERT
-	result, err := pdb.db.Exec(batchSQL, values...)
+	result, err := pdb.db.ExecContext(ctx, batchSQL, values...)
 	if err != nil {
 		wrappedErr := WrapPostgreSQLError(err, "INSERT", records[0].TableName, batchSQL)
 		return []orm.BasicSQLResult{{Error: wrappedErr}}, wrappedErr
@@ -218,84 +254,134 @@ func (pdb *postgres) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue
 
 // InsertOneTableStruct inserts a single TableStruct into the database.
 func (pdb *postgres) InsertOneTableStruct(obj orm.TableStruct, queue bool) orm.BasicSQLResult {
-	record, err := orm.TableStructToDBRecord(obj)
+	return pdb.InsertOneTableStructContext(context.Background(), obj, queue)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct
+// The BeforeInsert and Validate hooks of obj run first, AfterInsert once the row is inserted.
+func (pdb *postgres) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct, queue bool) orm.BasicSQLResult {
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
 	if err != nil {
-		return orm.BasicSQLResult{Error: err}
+		return orm.BasicSQLResult{Error: orm.WrapInsertError(err, obj.TableName())}
+	}
+	result := pdb.InsertOneDBRecordContext(ctx, record, queue)
+	if result.Error == nil {
+		result.Error = orm.RunAfterInsert(ctx, obj)
 	}
-	return pdb.InsertOneDBRecord(record, queue)
+	return result
 }
 
 // InsertManyTableStructs inserts multiple TableStructs into the database.
 func (pdb *postgres) InsertManyTableStructs(objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
+	return pdb.InsertManyTableStructsContext(context.Background(), objs, queue)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs.
+// The before-insert hooks of every struct run before anything is inserted.
+func (pdb *postgres) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
 	if len(objs) == 0 {
 		return nil, nil
 	}
 
 	records := make([]orm.DBRecord, 0, len(objs))
 	for _, obj := range objs {
-		record, err := orm.TableStructToDBRecord(obj)
+		record, err := orm.TableStructToInsertRecord(ctx, obj)
 		if err != nil {
-			return nil, err
+			return nil, orm.WrapInsertError(err, obj.TableName())
 		}
 		records = append(records, record)
 	}
 
-	return pdb.InsertManyDBRecords(records, queue)
+	results, err := pdb.InsertManyDBRecordsContext(ctx, records, queue)
+	if err != nil {
+		return results, err
+	}
+	return results, orm.RunAfterInsertAll(ctx, objs)
 }
 
-// NOTE: Update and Delete methods are not part of the orm.Database interface
-// They are commented out as they're not required and the interface deliberately
-// excludes them (see comment in orm.go:37-45)
+// UpsertOneDBRecord inserts a record or resolves the conflict according to opts
+func (pdb *postgres) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return pdb.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
 
-// UpdateOneDBRecord - NOT IN INTERFACE - Commented out
-/*
-func (pdb *postgres) UpdateOneDBRecord(record orm.DBRecord, ignoreKeyOnUpdate bool) (orm.BasicSQLResult, error) {
-	// Assumes 'id' is the primary key and is present in record.Data.
-	id, ok := record.Data["id"]
-	if !ok {
-		return orm.BasicSQLResult{Error: orm.ErrMissingPrimaryKey}, orm.ErrMissingPrimaryKey
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (pdb *postgres) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	query, values, err := record.ToUpsertSQLParameterizedDialect(pdb.Dialect(), opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: orm.WrapInsertError(err, record.TableName)}
 	}
+	result := pdb.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+	if result.Error != nil {
+		result.Error = WrapPostgreSQLError(result.Error, "UPSERT", record.TableName, query)
+	}
+	return result
+}
 
-	// Pre-allocate slices with known capacity to reduce allocations
-	numFields := len(record.Data)
-	setClauses := make([]string, 0, numFields)
-	values := make([]interface{}, 0, numFields+1) // +1 for WHERE id parameter
-	paramCounter := 1
+// UpsertManyDBRecordsSameTable upserts records of one table, batched by MAX_MULTIPLE_INSERTS.
+// The batches are sent through ExecManySQLParameterized, so a failing batch rolls back the
+// earlier ones. No records is a no-op and returns nil, nil.
+func (pdb *postgres) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return pdb.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
 
-	for k, v := range record.Data {
-		if k == "id" && ignoreKeyOnUpdate {
-			continue
-		}
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", k, paramCounter))
-		values = append(values, v)
-		paramCounter++
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (pdb *postgres) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
 	}
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterizedDialect(pdb.Dialect(), opts)
+	if err != nil {
+		return nil, orm.WrapInsertError(err, records[0].TableName)
+	}
+	return pdb.ExecManySQLParameterizedContext(ctx, paramSQLs)
+}
 
-	values = append(values, id)
-
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d",
-		record.TableName,
-		strings.Join(setClauses, ", "),
-		paramCounter,
-	)
+// UpdateWithCondition updates the columns in set on every row matching where.
+// An empty condition is refused unless allRows is true.
+func (pdb *postgres) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return pdb.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
 
-	result, err := pdb.db.Exec(query, values...)
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (pdb *postgres) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQLDialect(pdb.Dialect(), tableName, set, where, allRows)
 	if err != nil {
-		return orm.BasicSQLResult{Error: err}, fmt.Errorf("failed to update record: %w", err)
+		return orm.BasicSQLResult{Error: orm.WrapUpdateError(err, tableName)}
 	}
+	result := pdb.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+	if result.Error != nil {
+		result.Error = orm.WrapErrorWithQuery(result.Error, "UPDATE", tableName, query)
+	}
+	return result
+}
 
-	rowsAffected, err := result.RowsAffected()
+// DeleteWithCondition deletes every row matching where.
+// An empty condition is refused unless allRows is true.
+func (pdb *postgres) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return pdb.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (pdb *postgres) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQLDialect(pdb.Dialect(), tableName, where, allRows)
 	if err != nil {
-		return orm.BasicSQLResult{Error: err}, fmt.Errorf("failed to get rows affected after update: %w", err)
+		return orm.BasicSQLResult{Error: orm.WrapDeleteError(err, tableName)}
 	}
-
-	return orm.BasicSQLResult{RowsAffected: int(rowsAffected)}, nil
+	result := pdb.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+	if result.Error != nil {
+		result.Error = orm.WrapErrorWithQuery(result.Error, "DELETE", tableName, query)
+	}
+	return result
 }
-*/
 
 // ExecOneSQLParameterized executes a single parameterized SQL query that does not return rows.
 func (pdb *postgres) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
-	result, err := pdb.db.Exec(paramSQL.Query, paramSQL.Values...)
+	return pdb.ExecOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (pdb *postgres) ExecOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
+	result, err := pdb.db.ExecContext(ctx, paramSQL.Query, paramSQL.Values...)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -308,7 +394,12 @@ func (pdb *postgres) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL) orm
 
 // ExecManySQLParameterized executes multiple parameterized SQL queries in a batch.
 func (pdb *postgres) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
-	tx, err := pdb.db.Begin()
+	return pdb.ExecManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (pdb *postgres) ExecManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	tx, err := pdb.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -316,7 +407,7 @@ func (pdb *postgres) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL)
 
 	results := make([]orm.BasicSQLResult, 0, len(paramSQLs))
 	for _, ps := range paramSQLs {
-		result, err := tx.Exec(ps.Query, ps.Values...)
+		result, err := tx.ExecContext(ctx, ps.Query, ps.Values...)
 		if err != nil {
 			results = append(results, orm.BasicSQLResult{Error: err})
 			return results, fmt.Errorf("failed to execute SQL: %w", err)
@@ -333,7 +424,12 @@ func (pdb *postgres) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL)
 
 // SelectOneSQLParameterized executes a single parameterized SQL query that returns rows.
 func (pdb *postgres) SelectOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
-	rows, err := pdb.db.Query(paramSQL.Query, paramSQL.Values...)
+	return pdb.SelectOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (pdb *postgres) SelectOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
+	rows, err := pdb.db.QueryContext(ctx, paramSQL.Query, paramSQL.Values...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute SelectOneSQLParameterized query: %w", err)
 	}
@@ -349,9 +445,14 @@ func (pdb *postgres) SelectOneSQLParameterized(paramSQL orm.ParametereizedSQL) (
 
 // SelectManySQLParameterized executes multiple parameterized SQL queries that return multiple rows.
 func (pdb *postgres) SelectManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
+	return pdb.SelectManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// SelectManySQLParameterizedContext is the context-aware variant of SelectManySQLParameterized
+func (pdb *postgres) SelectManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
 	allResults := make([]orm.DBRecords, 0, len(paramSQLs))
 	for _, ps := range paramSQLs {
-		rows, err := pdb.db.Query(ps.Query, ps.Values...)
+		rows, err := pdb.db.QueryContext(ctx, ps.Query, ps.Values...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute SelectManySQLParameterized query: %w", err)
 		}
@@ -366,7 +467,6 @@ func (pdb *postgres) SelectManySQLParameterized(paramSQLs []orm.ParametereizedSQ
 	return allResults, nil
 }
 
-
 // GetSchema retrieves schema information from PostgreSQL.
 // hideSQL and hideSureSQL parameters are currently ignored as PostgreSQL's information_schema
 // doesn't directly map to these concepts from RQLite.
@@ -416,6 +516,11 @@ func (pdb *postgres) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruct {
 
 // Status retrieves the status of the PostgreSQL database.
 func (pdb *postgres) Status() (orm.NodeStatusStruct, error) {
+	return pdb.StatusContext(context.Background())
+}
+
+// StatusContext is the context-aware variant of Status
+func (pdb *postgres) StatusContext(ctx context.Context) (orm.NodeStatusStruct, error) {
 	var status orm.NodeStatusStruct
 	status.DBMS = "postgresql"
 	status.DBMSDriver = "lib/pq"
@@ -430,7 +535,7 @@ func (pdb *postgres) Status() (orm.NodeStatusStruct, error) {
 
 	// Get PostgreSQL version
 	var version string
-	err := pdb.db.QueryRow("SELECT version()").Scan(&version)
+	err := pdb.db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
 	if err != nil {
 		return status, fmt.Errorf("failed to get PostgreSQL version: %w", err)
 	}
@@ -483,97 +588,20 @@ func (pdb *postgres) Status() (orm.NodeStatusStruct, error) {
 
 // --- Helper Functions ---
 
-// buildSelectQuery constructs a SELECT query string and its arguments from conditions.
-func buildSelectQuery(tableName string, conditions []orm.Condition, limit int) (string, []interface{}) {
-	var whereClauses []string
-	var args []interface{}
-	paramCounter := 1
-
-	for _, cond := range conditions {
-		clause, vals, count := buildConditionClause(cond, paramCounter)
-		whereClauses = append(whereClauses, clause)
-		args = append(args, vals...)
-		paramCounter += count
-	}
-
-	query := fmt.Sprintf("SELECT * FROM %s", tableName)
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ") // Simple AND for top-level
-	}
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
-	}
-
-	return query, args
-}
-
-// buildConditionClause recursively builds a SQL WHERE clause from a Condition struct.
-func buildConditionClause(cond orm.Condition, paramCounter int) (string, []interface{}, int) {
-	var clause string
-	var args []interface{}
-	initialParamCounter := paramCounter
-
-	if len(cond.Nested) > 0 {
-		// Pre-allocate with known capacity
-		nestedClauses := make([]string, 0, len(cond.Nested))
-		for _, nestedCond := range cond.Nested {
-			nestedClause, nestedArgs, count := buildConditionClause(nestedCond, paramCounter)
-			nestedClauses = append(nestedClauses, nestedClause)
-			args = append(args, nestedArgs...)
-			paramCounter += count
-		}
-		logic := " AND "
-		if cond.Logic != "" {
-			logic = " " + strings.ToUpper(cond.Logic) + " "
-		}
-		clause = fmt.Sprintf("(%s)", strings.Join(nestedClauses, logic))
-	} else if cond.Field != "" && cond.Operator != "" {
-		// Handle specific operators for PostgreSQL
-		operator := cond.Operator
-		switch strings.ToUpper(operator) {
-		case "LIKE", "ILIKE": // ILIKE for case-insensitive LIKE in PostgreSQL
-			clause = fmt.Sprintf("%s %s $%d", cond.Field, operator, paramCounter)
-			args = append(args, cond.Value)
-			paramCounter++
-		case "IN", "NOT IN":
-			// For IN/NOT IN, the value should be a slice.
-			// PostgreSQL requires unnesting arrays for IN clauses or using ANY/ALL.
-			// For simplicity, we'll assume a comma-separated string or a slice of values
-			// and build placeholders accordingly.
-			if vals, ok := cond.Value.([]interface{}); ok {
-				// Pre-allocate with known capacity
-				valuePlaceholders := make([]string, 0, len(vals))
-				for range vals {
-					valuePlaceholders = append(valuePlaceholders, fmt.Sprintf("$%d", paramCounter))
-					args = append(args, vals...)
-					paramCounter++
-				}
-				clause = fmt.Sprintf("%s %s (%s)", cond.Field, operator, strings.Join(valuePlaceholders, ", "))
-			} else {
-				// Fallback or error for unsupported IN value type
-				clause = fmt.Sprintf("%s %s ($%d)", cond.Field, operator, paramCounter)
-				args = append(args, cond.Value)
-				paramCounter++
-			}
-		default:
-			clause = fmt.Sprintf("%s %s $%d", cond.Field, operator, paramCounter)
-			args = append(args, cond.Value)
-			paramCounter++
-		}
-	}
-
-	return clause, args, paramCounter - initialParamCounter
-}
-
 // --- Additional orm.Database interface methods ---
 
 // SelectOneWithCondition retrieves a single record with conditions.
 func (pdb *postgres) SelectOneWithCondition(tableName string, condition *orm.Condition) (orm.DBRecord, error) {
+	return pdb.SelectOneWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectOneWithConditionContext is the context-aware variant of SelectOneWithCondition
+func (pdb *postgres) SelectOneWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) (orm.DBRecord, error) {
 	if condition == nil {
-		return pdb.SelectOne(tableName)
+		return pdb.SelectOneContext(ctx, tableName)
 	}
 
-	query, params, err := condition.ToSelectString(tableName)
+	query, params, err := condition.ToSelectStringDialect(pdb.Dialect(), tableName)
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to build query: %w", err)
 	}
@@ -583,7 +611,7 @@ func (pdb *postgres) SelectOneWithCondition(tableName string, condition *orm.Con
 		query += " LIMIT 1"
 	}
 
-	rows, err := pdb.db.Query(query, params...)
+	rows, err := pdb.db.QueryContext(ctx, query, params...)
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -603,16 +631,21 @@ func (pdb *postgres) SelectOneWithCondition(tableName string, condition *orm.Con
 
 // SelectManyWithCondition retrieves multiple records with conditions.
 func (pdb *postgres) SelectManyWithCondition(tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
+	return pdb.SelectManyWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectManyWithConditionContext is the context-aware variant of SelectManyWithCondition
+func (pdb *postgres) SelectManyWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
 	if condition == nil {
-		return pdb.SelectMany(tableName)
+		return pdb.SelectManyContext(ctx, tableName)
 	}
 
-	query, params, err := condition.ToSelectString(tableName)
+	query, params, err := condition.ToSelectStringDialect(pdb.Dialect(), tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := pdb.db.Query(query, params...)
+	rows, err := pdb.db.QueryContext(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -649,18 +682,23 @@ func (pdb *postgres) SelectManyWithCondition(tableName string, condition *orm.Co
 //	}
 //	records, err := db.SelectManyComplex(query)
 func (pdb *postgres) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRecord, error) {
+	return pdb.SelectManyComplexContext(context.Background(), query)
+}
+
+// SelectManyComplexContext is the context-aware variant of SelectManyComplex
+func (pdb *postgres) SelectManyComplexContext(ctx context.Context, query *orm.ComplexQuery) ([]orm.DBRecord, error) {
 	if query == nil {
 		return nil, fmt.Errorf("query cannot be nil")
 	}
 
 	// Build the SQL query from the ComplexQuery struct
-	sql, params, err := query.ToSQL()
+	sql, params, err := query.ToSQLDialect(pdb.Dialect())
 	if err != nil {
 		return nil, fmt.Errorf("failed to build complex query: %w", err)
 	}
 
 	// Execute the query
-	rows, err := pdb.db.Query(sql, params...)
+	rows, err := pdb.db.QueryContext(ctx, sql, params...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute complex query: %w", err)
 	}
@@ -694,18 +732,23 @@ func (pdb *postgres) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRecord,
 //	}
 //	record, err := db.SelectOneComplex(query)
 func (pdb *postgres) SelectOneComplex(query *orm.ComplexQuery) (orm.DBRecord, error) {
+	return pdb.SelectOneComplexContext(context.Background(), query)
+}
+
+// SelectOneComplexContext is the context-aware variant of SelectOneComplex
+func (pdb *postgres) SelectOneComplexContext(ctx context.Context, query *orm.ComplexQuery) (orm.DBRecord, error) {
 	if query == nil {
 		return orm.DBRecord{}, fmt.Errorf("query cannot be nil")
 	}
 
 	// Build the SQL query from the ComplexQuery struct
-	sql, params, err := query.ToSQL()
+	sql, params, err := query.ToSQLDialect(pdb.Dialect())
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to build complex query: %w", err)
 	}
 
 	// Execute the query
-	rows, err := pdb.db.Query(sql, params...)
+	rows, err := pdb.db.QueryContext(ctx, sql, params...)
 	if err != nil {
 		return orm.DBRecord{}, fmt.Errorf("failed to execute complex query: %w", err)
 	}
@@ -728,9 +771,182 @@ func (pdb *postgres) SelectOneComplex(query *orm.ComplexQuery) (orm.DBRecord, er
 	return records[0], nil
 }
 
+// SelectPage returns one page of the rows matching the condition (condition.Limit,
+// default orm.DEFAULT_PAGINATION_LIMIT, and condition.Offset) together with the total
+// number of matching rows. With opts.Consistent both statements run in one read-only
+// REPEATABLE READ transaction, so the total matches the page.
+//
+// Example:
+//
+//	page, err := db.SelectPage("users", &orm.Condition{
+//	    Field: "status", Operator: "=", Value: "active",
+//	    OrderBy: []string{"id"}, Limit: 20, Offset: 40,
+//	}, orm.PageOptions{Consistent: true})
+//	// page.Records, *page.Total, page.HasMore
+func (pdb *postgres) SelectPage(tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	return pdb.SelectPageContext(context.Background(), tableName, condition, opts)
+}
+
+// SelectPageContext is the context-aware variant of SelectPage
+func (pdb *postgres) SelectPageContext(ctx context.Context, tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	pageQuery, err := condition.ToPageQueryDialect(pdb.Dialect(), tableName)
+	if err != nil {
+		return orm.Page{}, fmt.Errorf("failed to build query: %w", err)
+	}
+	return pdb.selectPage(ctx, pageQuery, tableName, opts)
+}
+
+// SelectPageComplex is SelectPage for a ComplexQuery, the count reuses its FROM, JOINs,
+// WHERE and GROUP BY (see orm.ComplexQuery.ToCountSQL)
+func (pdb *postgres) SelectPageComplex(query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	return pdb.SelectPageComplexContext(context.Background(), query, opts)
+}
+
+// SelectPageComplexContext is the context-aware variant of SelectPageComplex
+func (pdb *postgres) SelectPageComplexContext(ctx context.Context, query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	if query == nil {
+		return orm.Page{}, fmt.Errorf("query cannot be nil")
+	}
+
+	pageQuery, err := query.ToPageQueryDialect(pdb.Dialect())
+	if err != nil {
+		return orm.Page{}, fmt.Errorf("failed to build complex query: %w", err)
+	}
+	return pdb.selectPage(ctx, pageQuery, query.From, opts)
+}
+
+// selectPage runs the page and count statements, in one snapshot when consistent
+func (pdb *postgres) selectPage(ctx context.Context, pageQuery orm.PageQuery, tableName string, opts orm.PageOptions) (orm.Page, error) {
+	var q interface {
+		QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	} = pdb.db
+	if opts.Consistent {
+		tx, err := pdb.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			return orm.Page{}, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+		q = tx
+	}
+
+	var results [2]orm.DBRecords
+	for i, paramSQL := range []orm.ParametereizedSQL{pageQuery.Select, pageQuery.Count} {
+		rows, err := q.QueryContext(ctx, paramSQL.Query, paramSQL.Values...)
+		if err != nil {
+			return orm.Page{}, fmt.Errorf("failed to execute query: %w", err)
+		}
+		results[i], err = scanRowsToDBRecords(rows, tableName)
+		rows.Close()
+		// An empty page (no match, or an offset past the end) is not an error
+		if err != nil && !errors.Is(err, orm.ErrSQLNoRows) {
+			return orm.Page{}, err
+		}
+	}
+	return pageQuery.Page(results[0], results[1])
+}
+
+// StreamWithCondition streams the rows matching condition one at a time, without
+// collecting them in memory. The rows are read from sql.Rows while the caller ranges
+// over the sequence and the connection is released when the loop ends or breaks.
+//
+// Example:
+//
+//	for record, err := range db.StreamWithCondition("events", &orm.Condition{OrderBy: []string{"id"}}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    writer.Write(record)
+//	}
+func (pdb *postgres) StreamWithCondition(tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	return pdb.StreamWithConditionContext(context.Background(), tableName, condition)
+}
+
+// StreamWithConditionContext is the context-aware variant of StreamWithCondition
+func (pdb *postgres) StreamWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	if condition == nil {
+		condition = &orm.Condition{}
+	}
+	query, params, err := condition.ToSelectStringDialect(pdb.Dialect(), tableName)
+	if err != nil {
+		return streamError(fmt.Errorf("failed to build query: %w", err))
+	}
+	return pdb.stream(ctx, query, params, tableName)
+}
+
+// StreamComplex is the streaming variant of SelectManyComplex
+func (pdb *postgres) StreamComplex(query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	return pdb.StreamComplexContext(context.Background(), query)
+}
+
+// StreamComplexContext is the context-aware variant of StreamComplex
+func (pdb *postgres) StreamComplexContext(ctx context.Context, query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	if query == nil {
+		return streamError(fmt.Errorf("query cannot be nil"))
+	}
+
+	sql, params, err := query.ToSQLDialect(pdb.Dialect())
+	if err != nil {
+		return streamError(fmt.Errorf("failed to build complex query: %w", err))
+	}
+	return pdb.stream(ctx, sql, params, query.From)
+}
+
+// StreamSQLParameterized is the streaming variant of SelectOneSQLParameterized
+func (pdb *postgres) StreamSQLParameterized(paramSQL orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return pdb.StreamSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// StreamSQLParameterizedContext is the context-aware variant of StreamSQLParameterized
+func (pdb *postgres) StreamSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return pdb.stream(ctx, paramSQL.Query, paramSQL.Values, "") // Table name can be empty if not directly from a table
+}
+
+// stream runs the query each time the sequence is ranged over and yields its rows
+func (pdb *postgres) stream(ctx context.Context, query string, params []interface{}, tableName string) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		rows, err := pdb.db.QueryContext(ctx, query, params...)
+		if err != nil {
+			yield(orm.DBRecord{}, fmt.Errorf("failed to execute query: %w", err))
+			return
+		}
+		defer rows.Close()
+
+		scanner, err := newRowScanner(rows, tableName)
+		if err != nil {
+			yield(orm.DBRecord{}, err)
+			return
+		}
+		for rows.Next() {
+			record, err := scanner.scan(rows)
+			if err != nil {
+				yield(orm.DBRecord{}, err)
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(orm.DBRecord{}, fmt.Errorf("error iterating rows: %w", err))
+		}
+	}
+}
+
+// streamError returns a sequence yielding only err
+func streamError(err error) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		yield(orm.DBRecord{}, err)
+	}
+}
+
 // SelectOneSQL executes a raw SQL query and returns the results.
 func (pdb *postgres) SelectOneSQL(sql string) (orm.DBRecords, error) {
-	rows, err := pdb.db.Query(sql)
+	return pdb.SelectOneSQLContext(context.Background(), sql)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (pdb *postgres) SelectOneSQLContext(ctx context.Context, sql string) (orm.DBRecords, error) {
+	rows, err := pdb.db.QueryContext(ctx, sql)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -746,10 +962,15 @@ func (pdb *postgres) SelectOneSQL(sql string) (orm.DBRecords, error) {
 
 // SelectManySQL executes multiple SQL queries and returns results.
 func (pdb *postgres) SelectManySQL(sqls []string) ([]orm.DBRecords, error) {
+	return pdb.SelectManySQLContext(context.Background(), sqls)
+}
+
+// SelectManySQLContext is the context-aware variant of SelectManySQL
+func (pdb *postgres) SelectManySQLContext(ctx context.Context, sqls []string) ([]orm.DBRecords, error) {
 	results := make([]orm.DBRecords, 0, len(sqls))
 
 	for _, sql := range sqls {
-		rows, err := pdb.db.Query(sql)
+		rows, err := pdb.db.QueryContext(ctx, sql)
 		if err != nil {
 			return results, fmt.Errorf("failed to execute query: %w", err)
 		}
@@ -772,7 +993,12 @@ func (pdb *postgres) SelectManySQL(sqls []string) ([]orm.DBRecords, error) {
 
 // SelectOnlyOneSQL executes a SQL query and ensures exactly one row is returned.
 func (pdb *postgres) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
-	records, err := pdb.SelectOneSQL(sql)
+	return pdb.SelectOnlyOneSQLContext(context.Background(), sql)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (pdb *postgres) SelectOnlyOneSQLContext(ctx context.Context, sql string) (orm.DBRecord, error) {
+	records, err := pdb.SelectOneSQLContext(ctx, sql)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -790,7 +1016,12 @@ func (pdb *postgres) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
 
 // SelectOnlyOneSQLParameterized executes a parameterized query ensuring exactly one row.
 func (pdb *postgres) SelectOnlyOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
-	records, err := pdb.SelectOneSQLParameterized(paramSQL)
+	return pdb.SelectOnlyOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (pdb *postgres) SelectOnlyOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
+	records, err := pdb.SelectOneSQLParameterizedContext(ctx, paramSQL)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -808,7 +1039,12 @@ func (pdb *postgres) SelectOnlyOneSQLParameterized(paramSQL orm.ParametereizedSQ
 
 // ExecOneSQL executes a raw SQL query that does not return rows.
 func (pdb *postgres) ExecOneSQL(sql string) orm.BasicSQLResult {
-	result, err := pdb.db.Exec(sql)
+	return pdb.ExecOneSQLContext(context.Background(), sql)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (pdb *postgres) ExecOneSQLContext(ctx context.Context, sql string) orm.BasicSQLResult {
+	result, err := pdb.db.ExecContext(ctx, sql)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -825,16 +1061,21 @@ func (pdb *postgres) ExecOneSQL(sql string) orm.BasicSQLResult {
 
 // ExecManySQL executes multiple raw SQL queries in a batch.
 func (pdb *postgres) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
+	return pdb.ExecManySQLContext(context.Background(), sqls)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (pdb *postgres) ExecManySQLContext(ctx context.Context, sqls []string) ([]orm.BasicSQLResult, error) {
 	results := make([]orm.BasicSQLResult, 0, len(sqls))
 
-	tx, err := pdb.db.Begin()
+	tx, err := pdb.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	for _, sql := range sqls {
-		result, err := tx.Exec(sql)
+		result, err := tx.ExecContext(ctx, sql)
 		if err != nil {
 			results = append(results, orm.BasicSQLResult{Error: err})
 			return results, fmt.Errorf("failed to execute SQL: %w", err)
@@ -1,8 +1,18 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	orm "github.com/medatechnology/simpleorm"
 )
 
 // TestNewDefaultConfig tests the creation of a default PostgreSQL configuration
@@ -345,50 +355,6 @@ func TestParseDSN(t *testing.T) {
 	}
 }
 
-// TestConvertToPostgreSQLPlaceholders tests placeholder conversion
-func TestConvertToPostgreSQLPlaceholders(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Single placeholder",
-			input:    "SELECT * FROM users WHERE id = ?",
-			expected: "SELECT * FROM users WHERE id = $1",
-		},
-		{
-			name:     "Multiple placeholders",
-			input:    "INSERT INTO users (name, email, age) VALUES (?, ?, ?)",
-			expected: "INSERT INTO users (name, email, age) VALUES ($1, $2, $3)",
-		},
-		{
-			name:     "Placeholder in string literal (single quote)",
-			input:    "SELECT * FROM users WHERE name = 'What?' AND id = ?",
-			expected: "SELECT * FROM users WHERE name = 'What?' AND id = $1",
-		},
-		{
-			name:     "No placeholders",
-			input:    "SELECT * FROM users",
-			expected: "SELECT * FROM users",
-		},
-		{
-			name:     "Complex query",
-			input:    "UPDATE users SET name = ?, email = ? WHERE id = ? AND status = ?",
-			expected: "UPDATE users SET name = $1, email = $2 WHERE id = $3 AND status = $4",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := convertToPostgreSQLPlaceholders(tt.input)
-			if result != tt.expected {
-				t.Errorf("Expected: %s\nGot:      %s", tt.expected, result)
-			}
-		})
-	}
-}
-
 // TestExtractTableNameFromSQL tests table name extraction
 func TestExtractTableNameFromSQL(t *testing.T) {
 	tests := []struct {
@@ -473,3 +439,150 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+// TestApplyConstraints tests grouping the pg_constraint rows into a TableInfo
+func TestApplyConstraints(t *testing.T) {
+	valid := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	rows := []constraintRow{
+		{name: "orders_total_check", kind: "c", column: valid("total"), definition: "CHECK ((total > (0)::numeric))"},
+		{name: "orders_customer_fkey", kind: "f", column: valid("tenant_id"), refColumn: valid("tenant_id"), refTable: valid("customers"), onUpdate: "a", onDelete: "c"},
+		{name: "orders_customer_fkey", kind: "f", column: valid("customer_id"), refColumn: valid("id"), refTable: valid("customers"), onUpdate: "a", onDelete: "c"},
+		{name: "orders_pkey", kind: "p", column: valid("id")},
+		{name: "orders_number_key", kind: "u", column: valid("tenant_id")},
+		{name: "orders_number_key", kind: "u", column: valid("number")},
+	}
+	info := orm.TableInfo{Name: "orders", Columns: []orm.ColumnInfo{{Name: "id", Type: "integer"}, {Name: "total", Type: "numeric(10,2)", Nullable: true}}}
+
+	applyConstraints(&info, rows)
+
+	want := orm.TableInfo{
+		Name:       "orders",
+		Columns:    []orm.ColumnInfo{{Name: "id", Type: "integer", PrimaryKey: true}, {Name: "total", Type: "numeric(10,2)", Nullable: true}},
+		PrimaryKey: []string{"id"},
+		ForeignKeys: []orm.ForeignKeyInfo{{
+			Name:       "orders_customer_fkey",
+			Columns:    []string{"tenant_id", "customer_id"},
+			RefTable:   "customers",
+			RefColumns: []string{"tenant_id", "id"},
+			OnUpdate:   "NO ACTION",
+			OnDelete:   "CASCADE",
+		}},
+		UniqueConstraints: []orm.UniqueConstraint{{Name: "orders_number_key", Columns: []string{"tenant_id", "number"}}},
+		Checks:            []orm.CheckConstraint{{Name: "orders_total_check", Expression: "(total > (0)::numeric)"}},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+}
+
+// TestCheckExpression tests extracting the expression of a CHECK definition
+func TestCheckExpression(t *testing.T) {
+	tests := map[string]string{
+		"CHECK ((price > 0))":           "(price > 0)",
+		"CHECK ((price > 0)) NOT VALID": "(price > 0)",
+		"CHECK (true)":                  "true",
+		"something else":                "something else",
+	}
+	for definition, want := range tests {
+		if got := checkExpression(definition); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
+
+// fakeRows is a canned result for queries containing a substring
+type fakeRows struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeConnector is a minimal database/sql driver answering queries with canned results
+type fakeConnector struct {
+	results []fakeRows
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ connector *fakeConnector }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for _, result := range c.connector.results {
+		if strings.Contains(query, result.match) {
+			return &fakeRowsIter{result: result}, nil
+		}
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeRowsIter struct {
+	result fakeRows
+	next   int
+}
+
+func (r *fakeRowsIter) Columns() []string { return r.result.columns }
+func (r *fakeRowsIter) Close() error      { return nil }
+
+func (r *fakeRowsIter) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+// TestSelectPageEmpty checks an empty page is returned as Total 0 instead of ErrSQLNoRows
+func TestSelectPageEmpty(t *testing.T) {
+	pdb := &postgres{db: sql.OpenDB(&fakeConnector{results: []fakeRows{
+		{match: "COUNT(*)", columns: []string{"total"}, rows: [][]driver.Value{{int64(0)}}},
+		{match: "SELECT", columns: []string{"id", "name"}},
+	}})}
+	defer pdb.db.Close()
+
+	condition := &orm.Condition{Field: "name", Operator: "=", Value: "nobody", Limit: 10}
+	page, err := pdb.SelectPage("users", condition, orm.PageOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total == nil || *page.Total != 0 || len(page.Records) != 0 || page.HasMore {
+		t.Errorf("Expected an empty page, got %+v", page)
+	}
+
+	page, err = pdb.SelectPageComplex(&orm.ComplexQuery{From: "users", Limit: 10, Offset: 50}, orm.PageOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total == nil || *page.Total != 0 || len(page.Records) != 0 || !page.HasPrev {
+		t.Errorf("Expected an empty page past the end, got %+v", page)
+	}
+}
+
+// TestSelectUint64AboveMaxInt64 checks a NUMERIC(20) value above math.MaxInt64 is read back into a uint64 field
+func TestSelectUint64AboveMaxInt64(t *testing.T) {
+	pdb := &postgres{db: sql.OpenDB(&fakeConnector{results: []fakeRows{
+		{match: "SELECT", columns: []string{"code", "hits"}, rows: [][]driver.Value{{"a", []byte("18446744073709551615")}}},
+	}})}
+	defer pdb.db.Close()
+
+	records, err := pdb.SelectOneSQL("SELECT code, hits FROM tokens")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var token struct {
+		Code string `db:"code"`
+		Hits uint64 `db:"hits"`
+	}
+	if err := orm.ScanRecord(records[0], &token); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if token.Hits != math.MaxUint64 {
+		t.Errorf("Expected %d, got %d", uint64(math.MaxUint64), token.Hits)
+	}
+}
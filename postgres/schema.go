@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	orm "github.com/medatechnology/simpleorm"
+)
+
+// Catalog queries used by DescribeTable. The table is resolved with to_regclass, which
+// follows the search_path for unqualified names and returns NULL (no rows) if it does not exist.
+const (
+	describeColumnsQuery = `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull, pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`
+
+	// One row per constraint column; CHECK constraints without columns are kept by the LEFT JOIN
+	describeConstraintsQuery = `
+		SELECT c.conname, c.contype, a.attname, fa.attname, c.confrelid::regclass::text,
+			c.confupdtype, c.confdeltype, pg_get_constraintdef(c.oid)
+		FROM pg_constraint c
+		LEFT JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		LEFT JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+		LEFT JOIN pg_attribute fa ON fa.attrelid = c.confrelid AND fa.attnum = c.confkey[k.ord::int]
+		WHERE c.conrelid = to_regclass($1) AND c.contype IN ('p', 'u', 'f', 'c')
+		ORDER BY c.contype, c.conname, k.ord`
+
+	// Indexes that do not back a PRIMARY KEY, UNIQUE or EXCLUDE constraint, one row per column
+	describeIndexesQuery = `
+		SELECT i.relname, ix.indisunique, a.attname
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+		WHERE ix.indrelid = to_regclass($1)
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = ix.indexrelid AND c.conrelid = ix.indrelid)
+		ORDER BY i.relname, k.ord`
+)
+
+// referentialActions maps pg_constraint.confupdtype / confdeltype to their SQL spelling
+var referentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// ListTables returns the names of the base tables in the current schema
+func (pdb *postgres) ListTables() ([]string, error) {
+	return pdb.ListTablesContext(context.Background())
+}
+
+// ListTablesContext is the context-aware variant of ListTables
+func (pdb *postgres) ListTablesContext(ctx context.Context) ([]string, error) {
+	query := `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`
+	rows, err := pdb.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return tables, nil
+}
+
+// DescribeTable returns the columns and constraints of a table from pg_catalog.
+// Unqualified names are resolved through the search_path, like in a query.
+//
+// Example:
+//
+//	info, err := db.DescribeTable("public.orders")
+//	// info.Columns, info.PrimaryKey, info.Indexes, info.ForeignKeys, ...
+func (pdb *postgres) DescribeTable(tableName string) (orm.TableInfo, error) {
+	return pdb.DescribeTableContext(context.Background(), tableName)
+}
+
+// DescribeTableContext is the context-aware variant of DescribeTable
+func (pdb *postgres) DescribeTableContext(ctx context.Context, tableName string) (orm.TableInfo, error) {
+	if err := orm.ValidateTableName(tableName); err != nil {
+		return orm.TableInfo{}, err
+	}
+	info := orm.TableInfo{Name: tableName}
+
+	rows, err := pdb.db.QueryContext(ctx, describeColumnsQuery, tableName)
+	if err != nil {
+		return orm.TableInfo{}, fmt.Errorf("failed to describe columns: %w", err)
+	}
+	err = scanAll(rows, func() error {
+		var column orm.ColumnInfo
+		var notNull bool
+		var defaultValue sql.NullString
+		if err := rows.Scan(&column.Name, &column.Type, &notNull, &defaultValue); err != nil {
+			return err
+		}
+		column.Nullable = !notNull
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		info.Columns = append(info.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return orm.TableInfo{}, err
+	}
+	if len(info.Columns) == 0 {
+		return orm.TableInfo{}, fmt.Errorf("%w: %s", orm.ErrTableNotFound, tableName)
+	}
+
+	rows, err = pdb.db.QueryContext(ctx, describeConstraintsQuery, tableName)
+	if err != nil {
+		return orm.TableInfo{}, fmt.Errorf("failed to describe constraints: %w", err)
+	}
+	var constraints []constraintRow
+	err = scanAll(rows, func() error {
+		var row constraintRow
+		if err := rows.Scan(&row.name, &row.kind, &row.column, &row.refColumn, &row.refTable,
+			&row.onUpdate, &row.onDelete, &row.definition); err != nil {
+			return err
+		}
+		constraints = append(constraints, row)
+		return nil
+	})
+	if err != nil {
+		return orm.TableInfo{}, err
+	}
+	applyConstraints(&info, constraints)
+
+	rows, err = pdb.db.QueryContext(ctx, describeIndexesQuery, tableName)
+	if err != nil {
+		return orm.TableInfo{}, fmt.Errorf("failed to describe indexes: %w", err)
+	}
+	err = scanAll(rows, func() error {
+		var name string
+		var unique bool
+		var column sql.NullString
+		if err := rows.Scan(&name, &unique, &column); err != nil {
+			return err
+		}
+		n := len(info.Indexes)
+		if n == 0 || info.Indexes[n-1].Name != name {
+			info.Indexes = append(info.Indexes, orm.IndexInfo{Name: name, Unique: unique})
+			n++
+		}
+		// expression columns have no attribute
+		if column.Valid {
+			info.Indexes[n-1].Columns = append(info.Indexes[n-1].Columns, column.String)
+		}
+		return nil
+	})
+	if err != nil {
+		return orm.TableInfo{}, err
+	}
+
+	return info, nil
+}
+
+// constraintRow is one row of describeConstraintsQuery
+type constraintRow struct {
+	name       string
+	kind       string // p, u, f or c
+	column     sql.NullString
+	refColumn  sql.NullString
+	refTable   sql.NullString
+	onUpdate   string
+	onDelete   string
+	definition string
+}
+
+// applyConstraints groups the constraint rows (ordered by constraint) into info
+func applyConstraints(info *orm.TableInfo, rows []constraintRow) {
+	for i, row := range rows {
+		first := i == 0 || rows[i-1].name != row.name || rows[i-1].kind != row.kind
+		switch row.kind {
+		case "p":
+			if row.column.Valid {
+				info.PrimaryKey = append(info.PrimaryKey, row.column.String)
+				for j := range info.Columns {
+					if info.Columns[j].Name == row.column.String {
+						info.Columns[j].PrimaryKey = true
+					}
+				}
+			}
+		case "u":
+			if first {
+				info.UniqueConstraints = append(info.UniqueConstraints, orm.UniqueConstraint{Name: row.name})
+			}
+			if row.column.Valid {
+				unique := &info.UniqueConstraints[len(info.UniqueConstraints)-1]
+				unique.Columns = append(unique.Columns, row.column.String)
+			}
+		case "f":
+			if first {
+				info.ForeignKeys = append(info.ForeignKeys, orm.ForeignKeyInfo{
+					Name:     row.name,
+					RefTable: row.refTable.String,
+					OnUpdate: referentialActions[row.onUpdate],
+					OnDelete: referentialActions[row.onDelete],
+				})
+			}
+			fk := &info.ForeignKeys[len(info.ForeignKeys)-1]
+			if row.column.Valid {
+				fk.Columns = append(fk.Columns, row.column.String)
+			}
+			if row.refColumn.Valid {
+				fk.RefColumns = append(fk.RefColumns, row.refColumn.String)
+			}
+		case "c":
+			if first {
+				info.Checks = append(info.Checks, orm.CheckConstraint{Name: row.name, Expression: checkExpression(row.definition)})
+			}
+		}
+	}
+}
+
+// checkExpression extracts the expression of a pg_get_constraintdef CHECK definition:
+// CHECK ((price > 0)) NOT VALID -> (price > 0)
+func checkExpression(definition string) string {
+	expr := strings.TrimSpace(definition)
+	expr = strings.TrimSuffix(expr, " NOT VALID")
+	expr = strings.TrimSuffix(expr, " NO INHERIT")
+	if strings.HasPrefix(expr, "CHECK (") && strings.HasSuffix(expr, ")") {
+		expr = expr[len("CHECK (") : len(expr)-1]
+	}
+	return expr
+}
+
+// scanAll calls scan for every row and closes rows
+func scanAll(rows *sql.Rows, scan func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -10,18 +11,26 @@ import (
 
 // postgresTransaction implements the orm.Transaction interface
 type postgresTransaction struct {
-	tx *sql.Tx // The underlying database/sql transaction
+	tx      *sql.Tx               // The underlying database/sql transaction
+	dialect orm.PostgreSQLDialect // Dialect of the owning database, used by the query builders
 }
 
 // BeginTransaction starts a new database transaction
 func (pdb *postgres) BeginTransaction() (orm.Transaction, error) {
-	tx, err := pdb.db.Begin()
+	return pdb.BeginTransactionContext(context.Background())
+}
+
+// BeginTransactionContext starts a new database transaction bound to ctx.
+// If ctx is cancelled before Commit, database/sql rolls the transaction back.
+func (pdb *postgres) BeginTransactionContext(ctx context.Context) (orm.TransactionContext, error) {
+	tx, err := pdb.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	return &postgresTransaction{
-		tx: tx,
+		tx:      tx,
+		dialect: pdb.Dialect().(orm.PostgreSQLDialect),
 	}, nil
 }
 
@@ -51,11 +60,16 @@ func (ptx *postgresTransaction) Rollback() error {
 
 // ExecOneSQL executes a single SQL statement within the transaction
 func (ptx *postgresTransaction) ExecOneSQL(sqlStmt string) orm.BasicSQLResult {
+	return ptx.ExecOneSQLContext(context.Background(), sqlStmt)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (ptx *postgresTransaction) ExecOneSQLContext(ctx context.Context, sqlStmt string) orm.BasicSQLResult {
 	if ptx.tx == nil {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction is nil or already closed")}
 	}
 
-	result, err := ptx.tx.Exec(sqlStmt)
+	result, err := ptx.tx.ExecContext(ctx, sqlStmt)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -75,11 +89,16 @@ func (ptx *postgresTransaction) ExecOneSQL(sqlStmt string) orm.BasicSQLResult {
 
 // ExecOneSQLParameterized executes a parameterized SQL statement within the transaction
 func (ptx *postgresTransaction) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
+	return ptx.ExecOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (ptx *postgresTransaction) ExecOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
 	if ptx.tx == nil {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction is nil or already closed")}
 	}
 
-	result, err := ptx.tx.Exec(paramSQL.Query, paramSQL.Values...)
+	result, err := ptx.tx.ExecContext(ctx, paramSQL.Query, paramSQL.Values...)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -99,6 +118,11 @@ func (ptx *postgresTransaction) ExecOneSQLParameterized(paramSQL orm.Parameterei
 
 // ExecManySQL executes multiple SQL statements within the transaction
 func (ptx *postgresTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
+	return ptx.ExecManySQLContext(context.Background(), sqls)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (ptx *postgresTransaction) ExecManySQLContext(ctx context.Context, sqls []string) ([]orm.BasicSQLResult, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
@@ -106,7 +130,7 @@ func (ptx *postgresTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult
 	results := make([]orm.BasicSQLResult, 0, len(sqls))
 
 	for _, sqlStmt := range sqls {
-		result, err := ptx.tx.Exec(sqlStmt)
+		result, err := ptx.tx.ExecContext(ctx, sqlStmt)
 		if err != nil {
 			results = append(results, orm.BasicSQLResult{Error: err})
 			return results, fmt.Errorf("failed to execute SQL: %w", err)
@@ -126,6 +150,11 @@ func (ptx *postgresTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult
 
 // ExecManySQLParameterized executes multiple parameterized SQL statements within the transaction
 func (ptx *postgresTransaction) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return ptx.ExecManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (ptx *postgresTransaction) ExecManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
@@ -133,7 +162,7 @@ func (ptx *postgresTransaction) ExecManySQLParameterized(paramSQLs []orm.Paramet
 	results := make([]orm.BasicSQLResult, 0, len(paramSQLs))
 
 	for _, paramSQL := range paramSQLs {
-		result, err := ptx.tx.Exec(paramSQL.Query, paramSQL.Values...)
+		result, err := ptx.tx.ExecContext(ctx, paramSQL.Query, paramSQL.Values...)
 		if err != nil {
 			results = append(results, orm.BasicSQLResult{Error: err})
 			return results, fmt.Errorf("failed to execute parameterized SQL: %w", err)
@@ -153,11 +182,16 @@ func (ptx *postgresTransaction) ExecManySQLParameterized(paramSQLs []orm.Paramet
 
 // SelectOneSQL executes a SELECT query within the transaction
 func (ptx *postgresTransaction) SelectOneSQL(sqlStmt string) (orm.DBRecords, error) {
+	return ptx.SelectOneSQLContext(context.Background(), sqlStmt)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (ptx *postgresTransaction) SelectOneSQLContext(ctx context.Context, sqlStmt string) (orm.DBRecords, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
 
-	rows, err := ptx.tx.Query(sqlStmt)
+	rows, err := ptx.tx.QueryContext(ctx, sqlStmt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -173,7 +207,12 @@ func (ptx *postgresTransaction) SelectOneSQL(sqlStmt string) (orm.DBRecords, err
 
 // SelectOnlyOneSQL executes a SELECT query that must return exactly one row
 func (ptx *postgresTransaction) SelectOnlyOneSQL(sqlStmt string) (orm.DBRecord, error) {
-	records, err := ptx.SelectOneSQL(sqlStmt)
+	return ptx.SelectOnlyOneSQLContext(context.Background(), sqlStmt)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (ptx *postgresTransaction) SelectOnlyOneSQLContext(ctx context.Context, sqlStmt string) (orm.DBRecord, error) {
+	records, err := ptx.SelectOneSQLContext(ctx, sqlStmt)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -191,11 +230,16 @@ func (ptx *postgresTransaction) SelectOnlyOneSQL(sqlStmt string) (orm.DBRecord,
 
 // SelectOneSQLParameterized executes a parameterized SELECT query within the transaction
 func (ptx *postgresTransaction) SelectOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return ptx.SelectOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (ptx *postgresTransaction) SelectOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
 
-	rows, err := ptx.tx.Query(paramSQL.Query, paramSQL.Values...)
+	rows, err := ptx.tx.QueryContext(ctx, paramSQL.Query, paramSQL.Values...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute parameterized query: %w", err)
 	}
@@ -211,7 +255,12 @@ func (ptx *postgresTransaction) SelectOneSQLParameterized(paramSQL orm.Parameter
 
 // SelectOnlyOneSQLParameterized executes a parameterized SELECT query that must return exactly one row
 func (ptx *postgresTransaction) SelectOnlyOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
-	records, err := ptx.SelectOneSQLParameterized(paramSQL)
+	return ptx.SelectOnlyOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (ptx *postgresTransaction) SelectOnlyOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
+	records, err := ptx.SelectOneSQLParameterizedContext(ctx, paramSQL)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -229,6 +278,11 @@ func (ptx *postgresTransaction) SelectOnlyOneSQLParameterized(paramSQL orm.Param
 
 // InsertOneDBRecord inserts a single DBRecord within the transaction
 func (ptx *postgresTransaction) InsertOneDBRecord(record orm.DBRecord) orm.BasicSQLResult {
+	return ptx.InsertOneDBRecordContext(context.Background(), record)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (ptx *postgresTransaction) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord) orm.BasicSQLResult {
 	if ptx.tx == nil {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction is nil or already closed")}
 	}
@@ -257,7 +311,7 @@ func (ptx *postgresTransaction) InsertOneDBRecord(record orm.DBRecord) orm.Basic
 		strings.Join(placeholders, ", "),
 	)
 
-	result, err := ptx.tx.Exec(query, values...)
+	result, err := ptx.tx.ExecContext(ctx, query, values...)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
@@ -273,6 +327,11 @@ func (ptx *postgresTransaction) InsertOneDBRecord(record orm.DBRecord) orm.Basic
 
 // InsertManyDBRecords inserts multiple DBRecords within the transaction
 func (ptx *postgresTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return ptx.InsertManyDBRecordsContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (ptx *postgresTransaction) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
@@ -284,7 +343,7 @@ func (ptx *postgresTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]o
 	results := make([]orm.BasicSQLResult, 0, len(records))
 
 	for _, record := range records {
-		result := ptx.InsertOneDBRecord(record)
+		result := ptx.InsertOneDBRecordContext(ctx, record)
 		results = append(results, result)
 		if result.Error != nil {
 			return results, result.Error
@@ -296,6 +355,11 @@ func (ptx *postgresTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]o
 
 // InsertManyDBRecordsSameTable inserts multiple DBRecords from the same table efficiently
 func (ptx *postgresTransaction) InsertManyDBRecordsSameTable(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return ptx.InsertManyDBRecordsSameTableContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (ptx *postgresTransaction) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
 	if ptx.tx == nil {
 		return nil, fmt.Errorf("transaction is nil or already closed")
 	}
@@ -322,7 +386,7 @@ func (ptx *postgresTransaction) InsertManyDBRecordsSameTable(records []orm.DBRec
 	}
 
 	// Execute the batch INSERT
-	result, err := ptx.tx.Exec(batchSQL, values...)
+	result, err := ptx.tx.ExecContext(ctx, batchSQL, values...)
 	if err != nil {
 		wrappedErr := WrapPostgreSQLError(err, "INSERT", records[0].TableName, batchSQL)
 		return []orm.BasicSQLResult{{Error: wrappedErr}}, wrappedErr
@@ -340,27 +404,106 @@ func (ptx *postgresTransaction) InsertManyDBRecordsSameTable(records []orm.DBRec
 
 // InsertOneTableStruct inserts a single TableStruct within the transaction
 func (ptx *postgresTransaction) InsertOneTableStruct(obj orm.TableStruct) orm.BasicSQLResult {
-	record, err := orm.TableStructToDBRecord(obj)
+	return ptx.InsertOneTableStructContext(context.Background(), obj)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct
+// The hooks of obj run as in InsertOneTableStruct of the database; AfterInsert runs inside
+// the transaction, so its error can be used to decide on a Rollback.
+func (ptx *postgresTransaction) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct) orm.BasicSQLResult {
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
 	if err != nil {
-		return orm.BasicSQLResult{Error: err}
+		return orm.BasicSQLResult{Error: orm.WrapInsertError(err, obj.TableName())}
+	}
+	result := ptx.InsertOneDBRecordContext(ctx, record)
+	if result.Error == nil {
+		result.Error = orm.RunAfterInsert(ctx, obj)
 	}
-	return ptx.InsertOneDBRecord(record)
+	return result
 }
 
 // InsertManyTableStructs inserts multiple TableStructs within the transaction
 func (ptx *postgresTransaction) InsertManyTableStructs(objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
+	return ptx.InsertManyTableStructsContext(context.Background(), objs)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs
+func (ptx *postgresTransaction) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
 	if len(objs) == 0 {
 		return nil, nil
 	}
 
 	records := make([]orm.DBRecord, 0, len(objs))
 	for _, obj := range objs {
-		record, err := orm.TableStructToDBRecord(obj)
+		record, err := orm.TableStructToInsertRecord(ctx, obj)
 		if err != nil {
-			return nil, err
+			return nil, orm.WrapInsertError(err, obj.TableName())
 		}
 		records = append(records, record)
 	}
 
-	return ptx.InsertManyDBRecords(records)
+	results, err := ptx.InsertManyDBRecordsContext(ctx, records)
+	if err != nil {
+		return results, err
+	}
+	return results, orm.RunAfterInsertAll(ctx, objs)
+}
+
+// UpdateWithCondition updates the columns in set on every row matching where within the transaction
+func (ptx *postgresTransaction) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return ptx.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
+
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (ptx *postgresTransaction) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQLDialect(ptx.dialect, tableName, set, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return ptx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// DeleteWithCondition deletes every row matching where within the transaction
+func (ptx *postgresTransaction) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return ptx.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (ptx *postgresTransaction) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQLDialect(ptx.dialect, tableName, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return ptx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// UpsertOneDBRecord upserts a single record within the transaction
+func (ptx *postgresTransaction) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return ptx.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
+
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (ptx *postgresTransaction) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	query, values, err := record.ToUpsertSQLParameterizedDialect(ptx.dialect, opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return ptx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// UpsertManyDBRecordsSameTable upserts records of one table within the transaction
+func (ptx *postgresTransaction) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return ptx.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
+
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (ptx *postgresTransaction) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterizedDialect(ptx.dialect, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ptx.ExecManySQLParameterizedContext(ctx, paramSQLs)
 }
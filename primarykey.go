@@ -0,0 +1,232 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrNoPrimaryKey        medaerror.MedaError = medaerror.MedaError{Message: "table struct has no primary key: implement PrimaryKeyer or tag a field with db:\"column,pk\""}
+	ErrPrimaryKeyArgsCount medaerror.MedaError = medaerror.MedaError{Message: "number of key values does not match the number of primary key columns"}
+)
+
+// PrimaryKeyer is an optional interface for TableStruct types that want to declare
+// their primary key explicitly. It takes precedence over `pk` tag options.
+// Composite keys are simply multiple columns:
+//
+//	func (oi *OrderItem) PrimaryKey() []string { return []string{"order_id", "product_id"} }
+//
+// Without PrimaryKeyer, every field tagged with the pk option is part of the key:
+//
+//	type User struct {
+//	    ID   int    `json:"id" db:"id,pk"`
+//	    Name string `json:"name" db:"name"`
+//	}
+type PrimaryKeyer interface {
+	PrimaryKey() []string
+}
+
+// PrimaryKeyColumns returns the primary key columns of a TableStruct,
+// either from PrimaryKeyer or from the fields tagged with `pk`.
+func PrimaryKeyColumns(obj TableStruct) ([]string, error) {
+	if pker, ok := obj.(PrimaryKeyer); ok {
+		columns := pker.PrimaryKey()
+		if len(columns) == 0 {
+			return nil, ErrNoPrimaryKey
+		}
+		return columns, nil
+	}
+
+	info := getStructInfo(reflect.TypeOf(obj))
+	var columns []string
+	for _, f := range info.Fields {
+		if f.HasOption("pk") {
+			columns = append(columns, f.Column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, ErrNoPrimaryKey
+	}
+	return columns, nil
+}
+
+// PrimaryKeyCondition builds the WHERE condition matching obj by its primary key.
+// Returns ErrMissingPrimaryKey if one of the key fields holds its zero value.
+func PrimaryKeyCondition(obj TableStruct) (*Condition, error) {
+	columns, err := PrimaryKeyColumns(obj)
+	if err != nil {
+		return nil, err
+	}
+	rv, info, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]Condition, 0, len(columns))
+	for _, col := range columns {
+		field, ok := info.FieldByColumn(col)
+		if !ok {
+			return nil, fmt.Errorf("primary key column %s has no matching struct field", col)
+		}
+		fv := fieldByIndexSafe(rv, field.Index)
+		if !fv.IsValid() || fv.IsZero() {
+			return nil, ErrMissingPrimaryKey
+		}
+		conditions = append(conditions, Condition{Field: field.Column, Operator: "=", Value: fieldValue(fv)})
+	}
+	return keyCondition(conditions), nil
+}
+
+// UpdateTableStruct writes every non-key column of obj to the row identified by its primary key.
+// Unlike InsertOneTableStruct, zero values are written too (it is a full-row update).
+// The BeforeUpdate and Validate hooks of obj run first; an error from them aborts the update.
+func UpdateTableStruct(db Database, obj TableStruct) BasicSQLResult {
+	if err := RunBeforeUpdate(context.Background(), obj); err != nil {
+		return BasicSQLResult{Error: WrapUpdateError(err, obj.TableName())}
+	}
+	where, err := PrimaryKeyCondition(obj)
+	if err != nil {
+		return BasicSQLResult{Error: WrapUpdateError(err, obj.TableName())}
+	}
+	columns, _ := PrimaryKeyColumns(obj)
+	isKey := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		isKey[col] = true
+	}
+
+	rv, info, _ := structValue(obj)
+	set := make(map[string]interface{}, len(info.Fields))
+	for _, f := range info.Fields {
+		if isKey[f.Column] {
+			continue
+		}
+		set[f.Column] = fieldValue(fieldByIndexSafe(rv, f.Index))
+	}
+	return db.UpdateWithCondition(obj.TableName(), set, where, false)
+}
+
+// DeleteTableStruct deletes the row identified by the primary key of obj
+func DeleteTableStruct(db Database, obj TableStruct) BasicSQLResult {
+	where, err := PrimaryKeyCondition(obj)
+	if err != nil {
+		return BasicSQLResult{Error: WrapDeleteError(err, obj.TableName())}
+	}
+	return db.DeleteWithCondition(obj.TableName(), where, false)
+}
+
+// FindByPK selects one row of T by its primary key values, given in the same
+// order as the key columns (see PrimaryKeyColumns).
+//
+//	user, err := orm.FindByPK[User](db, 42)
+//	item, err := orm.FindByPK[OrderItem](db, orderID, productID) // composite key
+func FindByPK[T any, PT interface {
+	*T
+	TableStruct
+}](db Database, keys ...interface{}) (T, error) {
+	var result T
+	obj := PT(&result)
+	columns, err := PrimaryKeyColumns(obj)
+	if err != nil {
+		return result, WrapSelectError(err, obj.TableName())
+	}
+	if len(keys) != len(columns) {
+		return result, WrapSelectError(ErrPrimaryKeyArgsCount, obj.TableName())
+	}
+
+	conditions := make([]Condition, len(columns))
+	for i, col := range columns {
+		conditions[i] = Condition{Field: col, Operator: "=", Value: keys[i]}
+	}
+	record, err := db.SelectOneWithCondition(obj.TableName(), keyCondition(conditions))
+	if err != nil {
+		return result, err
+	}
+	if err = ScanRecord(record, &result); err != nil {
+		return result, err
+	}
+	err = RunAfterSelect(context.Background(), obj)
+	return result, err
+}
+
+// ReloadTableStruct re-reads obj from the database using its primary key,
+// overwriting its fields with the stored values. obj must be a pointer.
+func ReloadTableStruct(db Database, obj TableStruct) error {
+	where, err := PrimaryKeyCondition(obj)
+	if err != nil {
+		return WrapSelectError(err, obj.TableName())
+	}
+	record, err := db.SelectOneWithCondition(obj.TableName(), where)
+	if err != nil {
+		return err
+	}
+	if err := ScanRecord(record, obj); err != nil {
+		return err
+	}
+	return RunAfterSelect(context.Background(), obj)
+}
+
+// keyCondition returns a single condition as-is, or ANDs several of them
+func keyCondition(conditions []Condition) *Condition {
+	if len(conditions) == 1 {
+		return &conditions[0]
+	}
+	return &Condition{Logic: "AND", Nested: conditions}
+}
+
+// structValue dereferences obj to its struct value and returns the cached metadata
+func structValue(obj interface{}) (reflect.Value, *structInfo, error) {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, nil, fmt.Errorf("nil %T", obj)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("expected a struct, got %T", obj)
+	}
+	return rv, getStructInfo(rv.Type()), nil
+}
+
+// fieldValue returns the value to send to the database for a struct field:
+// nil for nil pointers, driver.Valuer results, the plain value otherwise
+func fieldValue(fv reflect.Value) interface{} {
+	if !fv.IsValid() {
+		return nil
+	}
+	if valuer, ok := fv.Interface().(driver.Valuer); ok {
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			return nil
+		}
+		v, err := valuer.Value()
+		if err == nil {
+			return v
+		}
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface()
+}
+
+// fieldByIndexSafe is reflect.Value.FieldByIndex that returns an invalid Value
+// instead of panicking when it meets a nil embedded pointer
+func fieldByIndexSafe(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
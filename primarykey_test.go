@@ -0,0 +1,191 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type pkUser struct {
+	ID   int64  `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func (u *pkUser) TableName() string { return "users" }
+
+type pkOrderItem struct {
+	OrderID   int64   `db:"order_id,pk"`
+	ProductID string  `db:"product_id,pk"`
+	Quantity  int     `db:"quantity"`
+	Note      *string `db:"note"`
+}
+
+func (oi *pkOrderItem) TableName() string { return "order_items" }
+
+// pkStock declares its key with PrimaryKeyer, which wins over the pk tag
+type pkStock struct {
+	ID     int64  `db:"id,pk"`
+	SKU    string `db:"sku"`
+	Region string `db:"region"`
+	Count  int    `db:"count"`
+}
+
+func (s *pkStock) TableName() string    { return "stock" }
+func (s *pkStock) PrimaryKey() []string { return []string{"sku", "region"} }
+
+type pkNone struct {
+	Name string `db:"name"`
+}
+
+func (n *pkNone) TableName() string { return "none" }
+
+// TestPrimaryKeyColumns tests reading the key from the pk tags or PrimaryKeyer
+func TestPrimaryKeyColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     TableStruct
+		want    []string
+		wantErr error
+	}{
+		{"single pk tag", &pkUser{}, []string{"id"}, nil},
+		{"composite pk tags in field order", &pkOrderItem{}, []string{"order_id", "product_id"}, nil},
+		{"PrimaryKeyer wins over tags", &pkStock{}, []string{"sku", "region"}, nil},
+		{"no key", &pkNone{}, nil, ErrNoPrimaryKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrimaryKeyColumns(tt.obj)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestFindByPK tests the key condition built from the key values
+func TestFindByPK(t *testing.T) {
+	db := &stubDB{rows: []DBRecord{{TableName: "order_items", Data: map[string]interface{}{
+		"order_id": float64(7), "product_id": "pen", "quantity": float64(3),
+	}}}}
+
+	item, err := FindByPK[pkOrderItem](db, 7, "pen")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if item.OrderID != 7 || item.ProductID != "pen" || item.Quantity != 3 {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	want := &Condition{Logic: "AND", Nested: []Condition{
+		{Field: "order_id", Operator: "=", Value: 7},
+		{Field: "product_id", Operator: "=", Value: "pen"},
+	}}
+	if !reflect.DeepEqual(db.condition, want) {
+		t.Errorf("Expected %+v, got %+v", want, db.condition)
+	}
+
+	db.rows = []DBRecord{{Data: map[string]interface{}{"id": float64(1), "name": "ana"}}}
+	if _, err := FindByPK[pkUser](db, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (&Condition{Field: "id", Operator: "=", Value: 1}); !reflect.DeepEqual(db.condition, want) {
+		t.Errorf("Expected %+v, got %+v", want, db.condition)
+	}
+
+	if _, err := FindByPK[pkOrderItem](db, 7); !errors.Is(err, ErrPrimaryKeyArgsCount) {
+		t.Errorf("Expected %v, got %v", ErrPrimaryKeyArgsCount, err)
+	}
+	if _, err := FindByPK[pkNone](db, 1); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("Expected %v, got %v", ErrNoPrimaryKey, err)
+	}
+	db.rows = nil
+	if _, err := FindByPK[pkUser](db, 1); !errors.Is(err, ErrSQLNoRows) {
+		t.Errorf("Expected %v, got %v", ErrSQLNoRows, err)
+	}
+}
+
+// TestUpdateTableStruct tests the full-row update keyed by a composite primary key
+func TestUpdateTableStruct(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       TableStruct
+		wantSet   map[string]interface{}
+		wantWhere *Condition
+		wantErr   error
+	}{
+		{
+			name:    "composite key",
+			obj:     &pkOrderItem{OrderID: 7, ProductID: "pen", Quantity: 0},
+			wantSet: map[string]interface{}{"quantity": 0, "note": nil},
+			wantWhere: &Condition{Logic: "AND", Nested: []Condition{
+				{Field: "order_id", Operator: "=", Value: int64(7)},
+				{Field: "product_id", Operator: "=", Value: "pen"},
+			}},
+		},
+		{
+			name:    "PrimaryKeyer key",
+			obj:     &pkStock{ID: 1, SKU: "pen", Region: "eu", Count: 4},
+			wantSet: map[string]interface{}{"id": int64(1), "count": 4},
+			wantWhere: &Condition{Logic: "AND", Nested: []Condition{
+				{Field: "sku", Operator: "=", Value: "pen"},
+				{Field: "region", Operator: "=", Value: "eu"},
+			}},
+		},
+		{
+			name:    "missing part of the key",
+			obj:     &pkOrderItem{OrderID: 7},
+			wantErr: ErrMissingPrimaryKey,
+		},
+		{
+			name:    "no key",
+			obj:     &pkNone{Name: "x"},
+			wantErr: ErrNoPrimaryKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &stubDB{}
+			result := UpdateTableStruct(db, tt.obj)
+			if tt.wantErr != nil {
+				if !errors.Is(result.Error, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, result.Error)
+				}
+				if len(db.updates) != 0 {
+					t.Errorf("Expected no update, got %v", db.updates)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("Unexpected error: %v", result.Error)
+			}
+			if len(db.updates) != 1 || !reflect.DeepEqual(db.updates[0], tt.wantSet) {
+				t.Errorf("Expected set %v, got %v", tt.wantSet, db.updates)
+			}
+			if !reflect.DeepEqual(db.condition, tt.wantWhere) {
+				t.Errorf("Expected where %+v, got %+v", tt.wantWhere, db.condition)
+			}
+		})
+	}
+}
+
+// TestDeleteTableStruct tests the delete keyed by the primary key
+func TestDeleteTableStruct(t *testing.T) {
+	db := &stubDB{}
+	if result := DeleteTableStruct(db, &pkUser{ID: 3}); result.Error != nil {
+		t.Fatalf("Unexpected error: %v", result.Error)
+	}
+	if want := (&Condition{Field: "id", Operator: "=", Value: int64(3)}); db.deletes != 1 || !reflect.DeepEqual(db.condition, want) {
+		t.Errorf("Expected one delete with %+v, got %d with %+v", want, db.deletes, db.condition)
+	}
+
+	if result := DeleteTableStruct(db, &pkUser{}); !errors.Is(result.Error, ErrMissingPrimaryKey) {
+		t.Errorf("Expected %v, got %v", ErrMissingPrimaryKey, result.Error)
+	}
+	if db.deletes != 1 {
+		t.Errorf("Expected the delete without a key to be refused, got %d deletes", db.deletes)
+	}
+}
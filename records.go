@@ -7,17 +7,21 @@ import (
 	"github.com/medatechnology/goutil/object"
 )
 
-// TODO: replace the DBRecord.Data to be map[string]DataStruct
-// .     this will be more robust and flexible
+// DataStruct is the typed view of one column of a DBRecord, see DBRecord.Field
 type DataStruct struct {
-	TypeDef string // string | int | bool | etc
-	Value   interface{}
-	Empty   bool // this to replace the nil value if data is not set
+	TypeDef string      // column type reported by the database (rqlite: integer, text, ...; PostgreSQL: INT8, TEXT, ...), empty if unknown
+	Value   interface{} // the value as stored in DBRecord.Data
+	Empty   bool        // true when the value is NULL or the column is not in the record
 }
 
+// DBRecord is one row. Data keeps the values as returned by the backend, the typed
+// accessors (Int64, String, Time, ...) convert them on demand.
+// Types is filled by the backends on select with the column types of the result; it is
+// shared by all records of the same result, so do not modify it.
 type DBRecord struct {
 	TableName string
 	Data      map[string]interface{}
+	Types     map[string]string // column -> database type name, nil if unknown
 }
 
 type DBRecords []DBRecord
@@ -0,0 +1,99 @@
+package rqlite
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	orm "github.com/medatechnology/simpleorm"
+)
+
+// RQLite sends every value as plain JSON, so decoding into interface{} would turn every
+// integer into a float64 (losing precision above 2^53) and every BLOB into a base64 string.
+// Responses are decoded with json.Number instead and each value is converted using the
+// column type RQLite reports (the declared type of the column, empty for expressions),
+// following SQLite's type affinity rules:
+//
+//	INTEGER, INT, BIGINT, ...        -> int64 (float64 if the stored value has a fraction)
+//	REAL, FLOAT, DOUBLE              -> float64
+//	BLOB                             -> []byte (base64 decoded)
+//	DATETIME, TIMESTAMP, DATE        -> time.Time when the text parses as a timestamp
+//	BOOLEAN                          -> bool when the value is 0 or 1
+//	TEXT, NUMERIC, empty and others  -> string, or int64 / float64 for numbers
+//
+// Values that do not match their column type (SQLite allows any value in any column)
+// are kept with the default conversion.
+
+// Layouts tried (in order) for DATETIME columns
+var datetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// UnmarshalJSON decodes a query result, converting the values using the column types
+func (r *QueryResult) UnmarshalJSON(data []byte) error {
+	type queryResult QueryResult // same fields, without this method
+	var raw queryResult
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	*r = QueryResult(raw)
+	for _, row := range r.Values {
+		convertRow(row, r.Types)
+	}
+	return nil
+}
+
+// convertRow converts the JSON values of one row in place
+func convertRow(row []interface{}, types []string) {
+	for i, value := range row {
+		columnType := ""
+		if i < len(types) {
+			columnType = types[i]
+		}
+		row[i] = convertValue(value, columnType)
+	}
+}
+
+// convertValue converts one JSON value using the column type, see the rules above
+func convertValue(value interface{}, columnType string) interface{} {
+	columnType = strings.ToLower(columnType)
+	switch v := value.(type) {
+	case json.Number:
+		switch {
+		case strings.Contains(columnType, "bool"):
+			if s := v.String(); s == "0" || s == "1" {
+				return s == "1"
+			}
+		case strings.Contains(columnType, "real"), strings.Contains(columnType, "floa"), strings.Contains(columnType, "doub"):
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+		return orm.JSONNumberValue(v)
+
+	case string:
+		switch {
+		case strings.Contains(columnType, "blob"):
+			if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return b
+			}
+		case strings.Contains(columnType, "date"), strings.Contains(columnType, "time"):
+			for _, layout := range datetimeLayouts {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package rqlite
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestConvertValue tests the conversion of JSON values using the column type
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      interface{}
+		columnType string
+		want       interface{}
+	}{
+		{"integer", json.Number("9007199254740993"), "integer", int64(9007199254740993)},
+		{"bigint", json.Number("-42"), "BIGINT", int64(-42)},
+		{"fraction in integer column", json.Number("1.5"), "integer", 1.5},
+		{"real", json.Number("3"), "real", float64(3)},
+		{"double", json.Number("2.25"), "DOUBLE PRECISION", 2.25},
+		{"numeric", json.Number("10"), "numeric", int64(10)},
+		{"expression", json.Number("7"), "", int64(7)},
+		{"boolean", json.Number("1"), "boolean", true},
+		{"boolean false", json.Number("0"), "BOOLEAN", false},
+		{"boolean out of range", json.Number("2"), "boolean", int64(2)},
+		{"blob", "iVBORw==", "blob", []byte{0x89, 0x50, 0x4e, 0x47}},
+		{"text in blob column", "not base64!", "blob", "not base64!"},
+		{"datetime", "2024-05-01T10:30:00Z", "datetime", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"sqlite datetime", "2024-05-01 10:30:00", "DATETIME", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"date", "2024-05-01", "date", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"unparsable datetime", "yesterday", "datetime", "yesterday"},
+		{"unix time", json.Number("1714559400"), "timestamp", int64(1714559400)},
+		{"text", "iVBORw==", "text", "iVBORw=="},
+		{"null", nil, "integer", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertValue(tt.value, tt.columnType)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestQueryResultUnmarshalJSON checks a whole response keeps int64 precision and typed values
+func TestQueryResultUnmarshalJSON(t *testing.T) {
+	body := `{"results":[{"columns":["id","name","avatar","score"],"types":["integer","text","blob","real"],` +
+		`"values":[[9223372036854775807,"alice","AQI=",1],[2,null,null,1.5]],"time":0.01}]}`
+
+	var resp QueryResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := [][]interface{}{
+		{int64(9223372036854775807), "alice", []byte{1, 2}, float64(1)},
+		{int64(2), nil, nil, 1.5},
+	}
+	if !reflect.DeepEqual(resp.Results[0].Values, want) {
+		t.Errorf("Expected %v, got %v", want, resp.Results[0].Values)
+	}
+	if resp.Results[0].Time != 0.01 {
+		t.Errorf("Expected time 0.01, got %v", resp.Results[0].Time)
+	}
+}
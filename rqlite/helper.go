@@ -2,6 +2,7 @@ package rqlite
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,12 +38,25 @@ func (db *RQLiteDirectDB) buildURL(endpoint string, params url.Values) string {
 
 // sendRequest sends a HTTP request to the RQLite server with retries
 func (db *RQLiteDirectDB) sendRequest(method, endpoint string, params url.Values, body io.Reader) (*http.Response, error) {
+	return db.sendRequestContext(context.Background(), method, endpoint, params, body)
+}
+
+// sendRequestContext sends a HTTP request to the RQLite server with retries.
+// The request is bound to ctx, and the retry loop stops as soon as ctx is done, returning
+// ctx.Err() as is (context.Canceled or context.DeadlineExceeded) so callers can tell a
+// cancellation from a server timeout.
+func (db *RQLiteDirectDB) sendRequestContext(ctx context.Context, method, endpoint string, params url.Values, body io.Reader) (*http.Response, error) {
 	url := db.buildURL(endpoint, params)
 	var lastErr error
 
 	// Retry logic
 	for attempt := 0; attempt < db.Config.RetryCount; attempt++ {
-		req, err := http.NewRequest(method, url, body)
+		// Do not start another attempt if the caller already gave up
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
 		if err != nil {
 			return nil, fmt.Errorf("%w: failed to create request: %w", ErrRQLiteConnectionFailed, err)
 		}
@@ -78,13 +92,21 @@ func (db *RQLiteDirectDB) sendRequest(method, endpoint string, params url.Values
 			lastErr = fmt.Errorf("HTTP error: %d - %s", resp.StatusCode, string(respBody))
 			// fmt.Printf("Send request error:%s, attemp:%d\n", lastErr, attempt)
 		} else {
+			// Cancelled or timed out by the caller, retrying will not help
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			fmt.Println("Send request error : ", err)
 			lastErr = err
 		}
 
 		// Wait before retrying, but only if this isn't the last attempt
 		if attempt < db.Config.RetryCount-1 {
-			time.Sleep(DEFAULT_RETRY_TIMEOUT)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(DEFAULT_RETRY_TIMEOUT):
+			}
 		}
 	}
 
@@ -92,14 +114,14 @@ func (db *RQLiteDirectDB) sendRequest(method, endpoint string, params url.Values
 }
 
 // execQuery sends a query to the RQLite server
-func (db *RQLiteDirectDB) execQuery(queries []string) (*QueryResponse, error) {
+func (db *RQLiteDirectDB) execQuery(ctx context.Context, queries []string) (*QueryResponse, error) {
 	// RQLite expects a simple JSON array of query strings
 	requestBody, err := json.Marshal(queries)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to marshal query: %w", ErrRQLiteInvalidJSON, err)
 	}
 	// fmt.Println("execQuery RequestBody = ", requestBody)
-	resp, err := db.sendRequest(http.MethodPost, ENDPOINT_QUERY, nil, bytes.NewBuffer(requestBody))
+	resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_QUERY, nil, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -123,14 +145,14 @@ func (db *RQLiteDirectDB) execQuery(queries []string) (*QueryResponse, error) {
 }
 
 // execCommand sends a write command to the RQLite server
-func (db *RQLiteDirectDB) execCommand(commands []string) (*ExecuteResponse, error) {
+func (db *RQLiteDirectDB) execCommand(ctx context.Context, commands []string) (*ExecuteResponse, error) {
 	// RQLite expects a simple JSON array of command strings
 	requestBody, err := json.Marshal(commands)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to marshal commands: %w", ErrRQLiteInvalidJSON, err)
 	}
 
-	resp, err := db.sendRequest(http.MethodPost, ENDPOINT_EXECUTE, nil, bytes.NewBuffer(requestBody))
+	resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_EXECUTE, nil, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +213,7 @@ func checkRQLiteErrors(results []interface{}, errorField func(interface{}) strin
 }
 
 // execCommandParameterized sends a write command with parameters to the RQLite server
-func (db *RQLiteDirectDB) execCommandParameterized(commands []orm.ParametereizedSQL) (*ExecuteResponse, error) {
+func (db *RQLiteDirectDB) execCommandParameterized(ctx context.Context, commands []orm.ParametereizedSQL) (*ExecuteResponse, error) {
 	// Convert to RQLite's expected format
 	requestCommands := convertToRQLiteParameterizedFormat(commands)
 
@@ -201,7 +223,7 @@ func (db *RQLiteDirectDB) execCommandParameterized(commands []orm.Parametereized
 		return nil, fmt.Errorf("%w: failed to marshal parameterized commands: %w", ErrRQLiteInvalidJSON, err)
 	}
 
-	resp, err := db.sendRequest(http.MethodPost, ENDPOINT_EXECUTE, nil, bytes.NewBuffer(requestBody))
+	resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_EXECUTE, nil, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +246,13 @@ func (db *RQLiteDirectDB) execCommandParameterized(commands []orm.Parametereized
 }
 
 // execQueryParameterized sends a query with parameters to the RQLite server
-func (db *RQLiteDirectDB) execQueryParameterized(queries []orm.ParametereizedSQL) (*QueryResponse, error) {
+func (db *RQLiteDirectDB) execQueryParameterized(ctx context.Context, queries []orm.ParametereizedSQL) (*QueryResponse, error) {
+	return db.execQueryParameterizedParams(ctx, queries, nil)
+}
+
+// execQueryParameterizedParams is execQueryParameterized with extra URL parameters,
+// e.g. transaction=true to read all queries from the same snapshot
+func (db *RQLiteDirectDB) execQueryParameterizedParams(ctx context.Context, queries []orm.ParametereizedSQL, params url.Values) (*QueryResponse, error) {
 	// Convert to RQLite's expected format
 	requestQueries := convertToRQLiteParameterizedFormat(queries)
 
@@ -234,7 +262,7 @@ func (db *RQLiteDirectDB) execQueryParameterized(queries []orm.ParametereizedSQL
 		return nil, fmt.Errorf("%w: failed to marshal parameterized queries: %w", ErrRQLiteInvalidJSON, err)
 	}
 
-	resp, err := db.sendRequest(http.MethodPost, ENDPOINT_QUERY, nil, bytes.NewBuffer(requestBody))
+	resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_QUERY, params, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -263,23 +291,199 @@ func queryResultToDBRecord(result QueryResult, tableName string) ([]orm.DBRecord
 	}
 
 	records := make([]orm.DBRecord, len(result.Values))
+	types := columnTypes(result.Columns, result.Types)
 
 	for i, row := range result.Values {
-		record := orm.DBRecord{
-			TableName: tableName,
-			Data:      make(map[string]interface{}),
+		records[i] = rowToDBRecord(result.Columns, types, row, tableName)
+	}
+
+	return records, nil
+}
+
+// rowToDBRecord converts one row of a RQLite query result to a DBRecord
+func rowToDBRecord(columns []string, types map[string]string, row []interface{}, tableName string) orm.DBRecord {
+	record := orm.DBRecord{
+		TableName: tableName,
+		Data:      make(map[string]interface{}),
+		Types:     types,
+	}
+
+	for j, col := range columns {
+		if j < len(row) {
+			record.Data[col] = row[j]
+		}
+	}
+
+	return record
+}
+
+// columnTypes maps the columns of a RQLite query result to their types, nil without types
+func columnTypes(columns, types []string) map[string]string {
+	if len(types) == 0 {
+		return nil
+	}
+	typeMap := make(map[string]string, len(columns))
+	for i, col := range columns {
+		if i < len(types) {
+			typeMap[col] = types[i]
 		}
+	}
+	return typeMap
+}
 
-		for j, col := range result.Columns {
-			if j < len(row) {
-				record.Data[col] = row[j]
+// streamQueryResult decodes the first result of a /db/query response token by token and
+// passes its rows to yield one at a time, so the values array is never held in memory.
+// Values are converted like QueryResult.UnmarshalJSON does. It stops reading as soon as
+// yield returns false.
+func streamQueryResult(r io.Reader, tableName string, yield func(orm.DBRecord) bool) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return decodeError(err)
+		}
+		switch key {
+		case "results":
+			return streamFirstResult(dec, tableName, yield)
+		case "error":
+			if err := decodeResultError(dec); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
 			}
 		}
+	}
+	return nil
+}
 
-		records[i] = record
+// streamFirstResult reads the results array up to the end of its first entry
+func streamFirstResult(dec *json.Decoder, tableName string, yield func(orm.DBRecord) bool) error {
+	if ok, err := expectArray(dec); !ok || !dec.More() {
+		return err
+	}
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
 	}
 
-	return records, nil
+	var columns, types []string
+	var typeMap map[string]string
+	var pending [][]interface{} // rows read before the columns, RQLite sends the columns first
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return decodeError(err)
+		}
+		switch key {
+		case "columns":
+			if err := dec.Decode(&columns); err != nil {
+				return decodeError(err)
+			}
+			typeMap = columnTypes(columns, types)
+		case "types":
+			if err := dec.Decode(&types); err != nil {
+				return decodeError(err)
+			}
+			typeMap = columnTypes(columns, types)
+		case "error":
+			if err := decodeResultError(dec); err != nil {
+				return err
+			}
+		case "values":
+			if ok, err := expectArray(dec); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			for dec.More() {
+				var row []interface{}
+				if err := dec.Decode(&row); err != nil {
+					return decodeError(err)
+				}
+				if columns == nil {
+					pending = append(pending, row)
+					continue
+				}
+				convertRow(row, types)
+				if !yield(rowToDBRecord(columns, typeMap, row, tableName)) {
+					return nil
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range pending {
+		convertRow(row, types)
+		if !yield(rowToDBRecord(columns, typeMap, row, tableName)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// expectDelim reads the next token and checks it is the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+	if token != delim {
+		return decodeError(fmt.Errorf("expected %v, got %v", delim, token))
+	}
+	return nil
+}
+
+// expectArray reads the start of an array, it returns false for null
+func expectArray(dec *json.Decoder) (bool, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return false, decodeError(err)
+	}
+	if token == nil {
+		return false, nil
+	}
+	if token != json.Delim('[') {
+		return false, decodeError(fmt.Errorf("expected [, got %v", token))
+	}
+	return true, nil
+}
+
+// skipValue reads and discards the next value
+func skipValue(dec *json.Decoder) error {
+	var skip json.RawMessage
+	if err := dec.Decode(&skip); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+// decodeResultError reads an "error" value, returning it as ErrRQLiteQueryFailed when not empty
+func decodeResultError(dec *json.Decoder) error {
+	var message string
+	if err := dec.Decode(&message); err != nil {
+		return decodeError(err)
+	}
+	if message != "" {
+		return fmt.Errorf("%w: %s", ErrRQLiteQueryFailed, message)
+	}
+	return nil
+}
+
+// decodeError wraps a JSON decoding failure of a query response
+func decodeError(err error) error {
+	return fmt.Errorf("%w: failed to decode query response: %w", ErrRQLiteInvalidJSON, err)
 }
 
 // executeResultToBasicSQLResult converts a RQLite execute result to a BasicSQLResult
@@ -515,7 +719,7 @@ func GetStatusInfoFromResponse(raw map[string]interface{}) (orm.NodeStatusStruct
 
 // execRequestUnified sends both queries and commands atomically to the /db/request endpoint
 // This is used for transactions to ensure all operations execute together or fail together
-func (db *RQLiteDirectDB) execRequestUnified(statements []string, paramStatements []orm.ParametereizedSQL) error {
+func (db *RQLiteDirectDB) execRequestUnified(ctx context.Context, statements []string, paramStatements []orm.ParametereizedSQL) error {
 	// Build the unified request body
 	// The /db/request endpoint accepts an array where each element can be:
 	// - A simple string for non-parameterized queries
@@ -546,7 +750,7 @@ func (db *RQLiteDirectDB) execRequestUnified(statements []string, paramStatement
 	params := url.Values{}
 	params.Set("transaction", "true") // Ensure atomic execution
 
-	resp, err := db.sendRequest(http.MethodPost, ENDPOINT_UNIFIED, params, bytes.NewBuffer(jsonBody))
+	resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_UNIFIED, params, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return err
 	}
@@ -1,8 +1,16 @@
 package rqlite
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	orm "github.com/medatechnology/simpleorm"
 )
@@ -460,3 +468,196 @@ func TestConvertToRQLiteParameterizedFormatWithMultipleValues(t *testing.T) {
 		t.Errorf("Value[2] = %v; want 'bob@example.com'", slice[3])
 	}
 }
+
+// TestStreamQueryResult tests the incremental decoding of a query response
+func TestStreamQueryResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limit   int // stop after this many rows, 0 reads all
+		wantIDs []interface{}
+		wantErr error
+	}{
+		{
+			name:    "rows",
+			body:    `{"results":[{"columns":["id","name"],"types":["integer","text"],"values":[[1,"a"],[2,"b"],[3,"c"]],"time":0.1}],"time":0.2}`,
+			wantIDs: []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:    "stop early",
+			body:    `{"results":[{"columns":["id","name"],"values":[[1,"a"],[2,"b"],[3,"c"]]}]}`,
+			limit:   2,
+			wantIDs: []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:    "values before columns",
+			body:    `{"results":[{"values":[[1,"a"],[2,"b"]],"columns":["id","name"]}]}`,
+			wantIDs: []interface{}{int64(1), int64(2)},
+		},
+		{
+			name: "empty result",
+			body: `{"results":[{"columns":["id","name"],"types":["integer","text"]}]}`,
+		},
+		{
+			name: "no results",
+			body: `{"results":[],"time":0.1}`,
+		},
+		{
+			name:    "result error",
+			body:    `{"results":[{"error":"no such table: users"}]}`,
+			wantErr: ErrRQLiteQueryFailed,
+		},
+		{
+			name:    "truncated",
+			body:    `{"results":[{"columns":["id","name"],"values":[[1,"a"],[2,`,
+			wantIDs: []interface{}{int64(1)},
+			wantErr: ErrRQLiteInvalidJSON,
+		},
+		{
+			name:    "not an object",
+			body:    `[]`,
+			wantErr: ErrRQLiteInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []interface{}
+			err := streamQueryResult(strings.NewReader(tt.body), "users", func(record orm.DBRecord) bool {
+				if record.TableName != "users" || len(record.Data) != 2 {
+					t.Errorf("Unexpected record: %+v", record)
+				}
+				ids = append(ids, record.Data["id"])
+				return tt.limit == 0 || len(ids) < tt.limit
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Expected ids %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+// TestSendRequestContext tests the retry loop and how it stops when the context is done
+func TestSendRequestContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // status per attempt, the last one repeats
+		retryCount   int
+		ctx          func() (context.Context, context.CancelFunc)
+		slow         bool // the server waits for the client to give up
+		wantErr      error
+		wantAttempts int32
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusOK},
+			retryCount:   3,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries then succeeds",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			retryCount:   2,
+			wantAttempts: 2,
+		},
+		{
+			name:         "gives up after the last attempt",
+			statuses:     []int{http.StatusInternalServerError},
+			retryCount:   1,
+			wantErr:      ErrRQLiteConnectionFailed,
+			wantAttempts: 1,
+		},
+		{
+			name:         "unauthorized is not retried",
+			statuses:     []int{http.StatusUnauthorized},
+			retryCount:   3,
+			wantErr:      ErrRQLiteUnauthorized,
+			wantAttempts: 1,
+		},
+		{
+			name:       "cancelled before the first attempt",
+			statuses:   []int{http.StatusOK},
+			retryCount: 3,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantErr:      context.Canceled,
+			wantAttempts: 0,
+		},
+		{
+			name:       "deadline during the retry wait",
+			statuses:   []int{http.StatusInternalServerError},
+			retryCount: 3,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr:      context.DeadlineExceeded,
+			wantAttempts: 1,
+		},
+		{
+			name:       "deadline during the request",
+			statuses:   []int{http.StatusOK},
+			retryCount: 3,
+			slow:       true,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr:      context.DeadlineExceeded,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if tt.slow {
+					<-r.Context().Done()
+					return
+				}
+				status := tt.statuses[len(tt.statuses)-1]
+				if int(n) <= len(tt.statuses) {
+					status = tt.statuses[n-1]
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			db, err := NewDatabase(RqliteDirectConfig{URL: server.URL, RetryCount: tt.retryCount})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			ctx, cancel := context.Background(), context.CancelFunc(func() {})
+			if tt.ctx != nil {
+				ctx, cancel = tt.ctx()
+			}
+			defer cancel()
+
+			resp, err := db.sendRequestContext(ctx, http.MethodGet, ENDPOINT_STATUS, nil, nil)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if errors.Is(tt.wantErr, context.Canceled) || errors.Is(tt.wantErr, context.DeadlineExceeded) {
+				if errors.Is(err, ErrRQLiteTimeout) {
+					t.Errorf("Expected the context error unwrapped, got %v", err)
+				}
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package rqlite
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 
 	orm "github.com/medatechnology/simpleorm"
@@ -28,6 +33,9 @@ import (
 // join-read-only: user can join a cluster, but only as a read-only node.
 // remove: user can remove a node from a cluster. If a node performs an auto-remove on shutdown, then the -join-as user must have this permission.
 
+// Ensure RQLiteDirectDB satisfies both the plain and the context-aware interfaces
+var _ orm.DatabaseContext = (*RQLiteDirectDB)(nil)
+
 // NewDatabase creates a new RQLiteDirectDB instance
 func NewDatabase(config RqliteDirectConfig) (*RQLiteDirectDB, error) {
 	// Set default timeout if not specified
@@ -74,11 +82,16 @@ func (db *RQLiteDirectDB) IsConnected() bool {
 	return db.HTTPClient != nil
 }
 
+// Dialect returns the SQL dialect of RQLite (SQLite)
+func (db *RQLiteDirectDB) Dialect() orm.Dialect {
+	return orm.SQLiteDialect{}
+}
+
 // GetSchema returns the database schema
 func (db *RQLiteDirectDB) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruct {
 	// Query the sqlite_master table to get schema information
 	query := "SELECT * FROM " + SCHEMA_TABLE + " ORDER BY type, tbl_name, name"
-	resp, err := db.execQuery([]string{query})
+	resp, err := db.execQuery(context.Background(), []string{query})
 	if err != nil {
 		return []orm.SchemaStruct{}
 	}
@@ -109,7 +122,7 @@ func (db *RQLiteDirectDB) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruc
 					schema.TableName = strVal
 				}
 			case "rootpage":
-				if numVal, ok := value.(float64); ok {
+				if numVal, ok := value.(int64); ok {
 					schema.RootPage = int(numVal)
 				}
 			case "sql":
@@ -133,8 +146,13 @@ func (db *RQLiteDirectDB) GetSchema(hideSQL, hideSureSQL bool) []orm.SchemaStruc
 
 // Status returns the status of the RQLite cluster
 func (db *RQLiteDirectDB) Status() (orm.NodeStatusStruct, error) {
+	return db.StatusContext(context.Background())
+}
+
+// StatusContext is the context-aware variant of Status
+func (db *RQLiteDirectDB) StatusContext(ctx context.Context) (orm.NodeStatusStruct, error) {
 	// Use the /status endpoint to get cluster status
-	resp, err := db.sendRequest(http.MethodGet, "/status", nil, nil)
+	resp, err := db.sendRequestContext(ctx, http.MethodGet, "/status", nil, nil)
 	if err != nil {
 		return orm.NodeStatusStruct{}, err
 	}
@@ -238,13 +256,18 @@ func (db *RQLiteDirectDB) Peers() ([]string, error) {
 
 // SelectOne selects a single record from the table
 func (db *RQLiteDirectDB) SelectOne(tableName string) (orm.DBRecord, error) {
+	return db.SelectOneContext(context.Background(), tableName)
+}
+
+// SelectOneContext is the context-aware variant of SelectOne
+func (db *RQLiteDirectDB) SelectOneContext(ctx context.Context, tableName string) (orm.DBRecord, error) {
 	// Security: Validate table name to prevent SQL injection
 	if err := orm.ValidateTableName(tableName); err != nil {
 		return orm.DBRecord{}, orm.WrapSelectError(err, tableName)
 	}
 
 	query := fmt.Sprintf("SELECT * FROM %s LIMIT 1", tableName)
-	resp, err := db.execQuery([]string{query})
+	resp, err := db.execQuery(ctx, []string{query})
 	if err != nil {
 		return orm.DBRecord{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, query)
 	}
@@ -263,13 +286,18 @@ func (db *RQLiteDirectDB) SelectOne(tableName string) (orm.DBRecord, error) {
 
 // SelectMany selects multiple records from the table
 func (db *RQLiteDirectDB) SelectMany(tableName string) (orm.DBRecords, error) {
+	return db.SelectManyContext(context.Background(), tableName)
+}
+
+// SelectManyContext is the context-aware variant of SelectMany
+func (db *RQLiteDirectDB) SelectManyContext(ctx context.Context, tableName string) (orm.DBRecords, error) {
 	// Security: Validate table name to prevent SQL injection
 	if err := orm.ValidateTableName(tableName); err != nil {
 		return nil, orm.WrapSelectError(err, tableName)
 	}
 
 	query := fmt.Sprintf("SELECT * FROM %s", tableName)
-	resp, err := db.execQuery([]string{query})
+	resp, err := db.execQuery(ctx, []string{query})
 	if err != nil {
 		return nil, orm.WrapErrorWithQuery(err, "SELECT", tableName, query)
 	}
@@ -288,11 +316,16 @@ func (db *RQLiteDirectDB) SelectMany(tableName string) (orm.DBRecords, error) {
 
 // SelectOneWithCondition selects a single record with a condition
 func (db *RQLiteDirectDB) SelectOneWithCondition(tableName string, condition *orm.Condition) (orm.DBRecord, error) {
+	return db.SelectOneWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectOneWithConditionContext is the context-aware variant of SelectOneWithCondition
+func (db *RQLiteDirectDB) SelectOneWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) (orm.DBRecord, error) {
 	if condition == nil {
-		return db.SelectOne(tableName)
+		return db.SelectOneContext(ctx, tableName)
 	}
 
-	query, params, err := condition.ToSelectString(tableName)
+	query, params, err := condition.ToSelectStringDialect(db.Dialect(), tableName)
 	if err != nil {
 		return orm.DBRecord{}, orm.WrapSelectError(fmt.Errorf("failed to build query: %w", err), tableName)
 	}
@@ -307,7 +340,7 @@ func (db *RQLiteDirectDB) SelectOneWithCondition(tableName string, condition *or
 		Values: params,
 	}
 
-	resp, err := db.execQueryParameterized([]orm.ParametereizedSQL{paramSQL})
+	resp, err := db.execQueryParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
 	if err != nil {
 		return orm.DBRecord{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, query)
 	}
@@ -326,11 +359,16 @@ func (db *RQLiteDirectDB) SelectOneWithCondition(tableName string, condition *or
 
 // SelectManyWithCondition selects multiple records with a condition
 func (db *RQLiteDirectDB) SelectManyWithCondition(tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
+	return db.SelectManyWithConditionContext(context.Background(), tableName, condition)
+}
+
+// SelectManyWithConditionContext is the context-aware variant of SelectManyWithCondition
+func (db *RQLiteDirectDB) SelectManyWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) ([]orm.DBRecord, error) {
 	if condition == nil {
-		return db.SelectMany(tableName)
+		return db.SelectManyContext(ctx, tableName)
 	}
 
-	query, params, err := condition.ToSelectString(tableName)
+	query, params, err := condition.ToSelectStringDialect(db.Dialect(), tableName)
 	if err != nil {
 		return nil, orm.WrapSelectError(fmt.Errorf("failed to build query: %w", err), tableName)
 	}
@@ -340,7 +378,7 @@ func (db *RQLiteDirectDB) SelectManyWithCondition(tableName string, condition *o
 		Values: params,
 	}
 
-	resp, err := db.execQueryParameterized([]orm.ParametereizedSQL{paramSQL})
+	resp, err := db.execQueryParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
 	if err != nil {
 		return nil, orm.WrapErrorWithQuery(err, "SELECT", tableName, query)
 	}
@@ -376,12 +414,17 @@ func (db *RQLiteDirectDB) SelectManyWithCondition(tableName string, condition *o
 //	}
 //	records, err := db.SelectManyComplex(query)
 func (db *RQLiteDirectDB) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRecord, error) {
+	return db.SelectManyComplexContext(context.Background(), query)
+}
+
+// SelectManyComplexContext is the context-aware variant of SelectManyComplex
+func (db *RQLiteDirectDB) SelectManyComplexContext(ctx context.Context, query *orm.ComplexQuery) ([]orm.DBRecord, error) {
 	if query == nil {
 		return nil, fmt.Errorf("query cannot be nil")
 	}
 
 	// Build the SQL query from the ComplexQuery struct
-	sql, params, err := query.ToSQL()
+	sql, params, err := query.ToSQLDialect(db.Dialect())
 	if err != nil {
 		return nil, orm.WrapSelectError(fmt.Errorf("failed to build complex query: %w", err), query.From)
 	}
@@ -392,7 +435,7 @@ func (db *RQLiteDirectDB) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRe
 	}
 
 	// Execute the query
-	resp, err := db.execQueryParameterized([]orm.ParametereizedSQL{paramSQL})
+	resp, err := db.execQueryParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
 	if err != nil {
 		return nil, orm.WrapErrorWithQuery(err, "SELECT", query.From, sql)
 	}
@@ -424,12 +467,17 @@ func (db *RQLiteDirectDB) SelectManyComplex(query *orm.ComplexQuery) ([]orm.DBRe
 //	}
 //	record, err := db.SelectOneComplex(query)
 func (db *RQLiteDirectDB) SelectOneComplex(query *orm.ComplexQuery) (orm.DBRecord, error) {
+	return db.SelectOneComplexContext(context.Background(), query)
+}
+
+// SelectOneComplexContext is the context-aware variant of SelectOneComplex
+func (db *RQLiteDirectDB) SelectOneComplexContext(ctx context.Context, query *orm.ComplexQuery) (orm.DBRecord, error) {
 	if query == nil {
 		return orm.DBRecord{}, fmt.Errorf("query cannot be nil")
 	}
 
 	// Use SelectManyComplex to get the records
-	records, err := db.SelectManyComplex(query)
+	records, err := db.SelectManyComplexContext(ctx, query)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -445,9 +493,187 @@ func (db *RQLiteDirectDB) SelectOneComplex(query *orm.ComplexQuery) (orm.DBRecor
 	return records[0], nil
 }
 
+// SelectPage returns one page of the rows matching the condition (condition.Limit,
+// default orm.DEFAULT_PAGINATION_LIMIT, and condition.Offset) together with the total
+// number of matching rows. With opts.Consistent both statements are sent in a single
+// transactional request, so the total matches the page.
+//
+// Example:
+//
+//	page, err := db.SelectPage("users", &orm.Condition{
+//	    Field: "status", Operator: "=", Value: "active",
+//	    OrderBy: []string{"id"}, Limit: 20, Offset: 40,
+//	}, orm.PageOptions{Consistent: true})
+//	// page.Records, *page.Total, page.HasMore
+func (db *RQLiteDirectDB) SelectPage(tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	return db.SelectPageContext(context.Background(), tableName, condition, opts)
+}
+
+// SelectPageContext is the context-aware variant of SelectPage
+func (db *RQLiteDirectDB) SelectPageContext(ctx context.Context, tableName string, condition *orm.Condition, opts orm.PageOptions) (orm.Page, error) {
+	pageQuery, err := condition.ToPageQueryDialect(db.Dialect(), tableName)
+	if err != nil {
+		return orm.Page{}, orm.WrapSelectError(fmt.Errorf("failed to build query: %w", err), tableName)
+	}
+	return db.selectPage(ctx, pageQuery, tableName, opts)
+}
+
+// SelectPageComplex is SelectPage for a ComplexQuery, the count reuses its FROM, JOINs,
+// WHERE and GROUP BY (see orm.ComplexQuery.ToCountSQL)
+func (db *RQLiteDirectDB) SelectPageComplex(query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	return db.SelectPageComplexContext(context.Background(), query, opts)
+}
+
+// SelectPageComplexContext is the context-aware variant of SelectPageComplex
+func (db *RQLiteDirectDB) SelectPageComplexContext(ctx context.Context, query *orm.ComplexQuery, opts orm.PageOptions) (orm.Page, error) {
+	if query == nil {
+		return orm.Page{}, fmt.Errorf("query cannot be nil")
+	}
+
+	pageQuery, err := query.ToPageQueryDialect(db.Dialect())
+	if err != nil {
+		return orm.Page{}, orm.WrapSelectError(fmt.Errorf("failed to build complex query: %w", err), query.From)
+	}
+	return db.selectPage(ctx, pageQuery, query.From, opts)
+}
+
+// selectPage runs the page and count statements, in one transactional request when consistent
+func (db *RQLiteDirectDB) selectPage(ctx context.Context, pageQuery orm.PageQuery, tableName string, opts orm.PageOptions) (orm.Page, error) {
+	var results []QueryResult
+	if opts.Consistent {
+		params := url.Values{}
+		params.Set("transaction", "true")
+		resp, err := db.execQueryParameterizedParams(ctx, []orm.ParametereizedSQL{pageQuery.Select, pageQuery.Count}, params)
+		if err != nil {
+			return orm.Page{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, pageQuery.Select.Query)
+		}
+		results = resp.Results
+	} else {
+		for _, paramSQL := range []orm.ParametereizedSQL{pageQuery.Select, pageQuery.Count} {
+			resp, err := db.execQueryParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
+			if err != nil {
+				return orm.Page{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, paramSQL.Query)
+			}
+			results = append(results, resp.Results...)
+		}
+	}
+	if len(results) != 2 {
+		return orm.Page{}, orm.WrapSelectError(fmt.Errorf("expected 2 results, got %d", len(results)), tableName)
+	}
+
+	records, err := queryResultToDBRecord(results[0], tableName)
+	if err != nil && !errors.Is(err, orm.ErrSQLNoRows) {
+		return orm.Page{}, orm.WrapSelectError(err, tableName)
+	}
+	count, err := queryResultToDBRecord(results[1], tableName)
+	if err != nil {
+		return orm.Page{}, orm.WrapSelectError(err, tableName)
+	}
+	page, err := pageQuery.Page(records, count)
+	if err != nil {
+		return orm.Page{}, orm.WrapSelectError(err, tableName)
+	}
+	return page, nil
+}
+
+// StreamWithCondition streams the rows matching condition one at a time. The response body
+// is decoded token by token while the caller ranges over the sequence, so only one row is
+// held in memory; the request ends when the loop ends or breaks. The HTTP client Timeout
+// still bounds the whole response, raise it for long exports.
+//
+// Example:
+//
+//	for record, err := range db.StreamWithCondition("events", &orm.Condition{OrderBy: []string{"id"}}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    writer.Write(record)
+//	}
+func (db *RQLiteDirectDB) StreamWithCondition(tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamWithConditionContext(context.Background(), tableName, condition)
+}
+
+// StreamWithConditionContext is the context-aware variant of StreamWithCondition
+func (db *RQLiteDirectDB) StreamWithConditionContext(ctx context.Context, tableName string, condition *orm.Condition) iter.Seq2[orm.DBRecord, error] {
+	if condition == nil {
+		condition = &orm.Condition{}
+	}
+	query, params, err := condition.ToSelectStringDialect(db.Dialect(), tableName)
+	if err != nil {
+		return streamError(orm.WrapSelectError(fmt.Errorf("failed to build query: %w", err), tableName))
+	}
+	return db.stream(ctx, orm.ParametereizedSQL{Query: query, Values: params}, tableName)
+}
+
+// StreamComplex is the streaming variant of SelectManyComplex
+func (db *RQLiteDirectDB) StreamComplex(query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamComplexContext(context.Background(), query)
+}
+
+// StreamComplexContext is the context-aware variant of StreamComplex
+func (db *RQLiteDirectDB) StreamComplexContext(ctx context.Context, query *orm.ComplexQuery) iter.Seq2[orm.DBRecord, error] {
+	if query == nil {
+		return streamError(fmt.Errorf("query cannot be nil"))
+	}
+
+	sql, params, err := query.ToSQLDialect(db.Dialect())
+	if err != nil {
+		return streamError(orm.WrapSelectError(fmt.Errorf("failed to build complex query: %w", err), query.From))
+	}
+	return db.stream(ctx, orm.ParametereizedSQL{Query: sql, Values: params}, query.From)
+}
+
+// StreamSQLParameterized is the streaming variant of SelectOneSQLParameterized
+func (db *RQLiteDirectDB) StreamSQLParameterized(paramSQL orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return db.StreamSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// StreamSQLParameterizedContext is the context-aware variant of StreamSQLParameterized
+func (db *RQLiteDirectDB) StreamSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) iter.Seq2[orm.DBRecord, error] {
+	return db.stream(ctx, paramSQL, getTableNameFromSQL(paramSQL.Query))
+}
+
+// stream sends the query each time the sequence is ranged over and yields the rows
+// of its result as they are decoded from the response body
+func (db *RQLiteDirectDB) stream(ctx context.Context, paramSQL orm.ParametereizedSQL, tableName string) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		requestBody, err := json.Marshal(convertToRQLiteParameterizedFormat([]orm.ParametereizedSQL{paramSQL}))
+		if err != nil {
+			yield(orm.DBRecord{}, fmt.Errorf("%w: failed to marshal parameterized queries: %w", ErrRQLiteInvalidJSON, err))
+			return
+		}
+
+		resp, err := db.sendRequestContext(ctx, http.MethodPost, ENDPOINT_QUERY, nil, bytes.NewBuffer(requestBody))
+		if err != nil {
+			yield(orm.DBRecord{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, paramSQL.Query))
+			return
+		}
+		defer resp.Body.Close()
+
+		err = streamQueryResult(resp.Body, tableName, func(record orm.DBRecord) bool {
+			return yield(record, nil)
+		})
+		if err != nil {
+			yield(orm.DBRecord{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, paramSQL.Query))
+		}
+	}
+}
+
+// streamError returns a sequence yielding only err
+func streamError(err error) iter.Seq2[orm.DBRecord, error] {
+	return func(yield func(orm.DBRecord, error) bool) {
+		yield(orm.DBRecord{}, err)
+	}
+}
+
 // SelectOneSQL executes a single SQL query and returns the results
 func (db *RQLiteDirectDB) SelectOneSQL(sql string) (orm.DBRecords, error) {
-	resp, err := db.execQuery([]string{sql})
+	return db.SelectOneSQLContext(context.Background(), sql)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (db *RQLiteDirectDB) SelectOneSQLContext(ctx context.Context, sql string) (orm.DBRecords, error) {
+	resp, err := db.execQuery(ctx, []string{sql})
 	if err != nil {
 		tableName := getTableNameFromSQL(sql)
 		return nil, orm.WrapErrorWithQuery(err, "SELECT", tableName, sql)
@@ -471,7 +697,12 @@ func (db *RQLiteDirectDB) SelectOneSQL(sql string) (orm.DBRecords, error) {
 
 // SelectManySQL executes multiple SQL queries and returns the results of each
 func (db *RQLiteDirectDB) SelectManySQL(sqls []string) ([]orm.DBRecords, error) {
-	resp, err := db.execQuery(sqls)
+	return db.SelectManySQLContext(context.Background(), sqls)
+}
+
+// SelectManySQLContext is the context-aware variant of SelectManySQL
+func (db *RQLiteDirectDB) SelectManySQLContext(ctx context.Context, sqls []string) ([]orm.DBRecords, error) {
+	resp, err := db.execQuery(ctx, sqls)
 	if err != nil {
 		return nil, orm.WrapError(err, "SELECT", "")
 	}
@@ -508,7 +739,12 @@ func (db *RQLiteDirectDB) SelectManySQL(sqls []string) ([]orm.DBRecords, error)
 
 // SelectOnlyOneSQL executes a SQL query and ensures exactly one row is returned
 func (db *RQLiteDirectDB) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
-	records, err := db.SelectOneSQL(sql)
+	return db.SelectOnlyOneSQLContext(context.Background(), sql)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (db *RQLiteDirectDB) SelectOnlyOneSQLContext(ctx context.Context, sql string) (orm.DBRecord, error) {
+	records, err := db.SelectOneSQLContext(ctx, sql)
 	if err != nil {
 		return orm.DBRecord{}, err // Already wrapped in SelectOneSQL
 	}
@@ -529,7 +765,12 @@ func (db *RQLiteDirectDB) SelectOnlyOneSQL(sql string) (orm.DBRecord, error) {
 
 // SelectOneSQLParameterized executes a single parameterized SQL query
 func (db *RQLiteDirectDB) SelectOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
-	resp, err := db.execQueryParameterized([]orm.ParametereizedSQL{paramSQL})
+	return db.SelectOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (db *RQLiteDirectDB) SelectOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
+	resp, err := db.execQueryParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
 	if err != nil {
 		tableName := getTableNameFromSQL(paramSQL.Query)
 		return nil, orm.WrapErrorWithQuery(err, "SELECT", tableName, paramSQL.Query)
@@ -551,7 +792,12 @@ func (db *RQLiteDirectDB) SelectOneSQLParameterized(paramSQL orm.ParametereizedS
 
 // SelectManySQLParameterized executes multiple parameterized SQL queries
 func (db *RQLiteDirectDB) SelectManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
-	resp, err := db.execQueryParameterized(paramSQLs)
+	return db.SelectManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// SelectManySQLParameterizedContext is the context-aware variant of SelectManySQLParameterized
+func (db *RQLiteDirectDB) SelectManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.DBRecords, error) {
+	resp, err := db.execQueryParameterized(ctx, paramSQLs)
 	if err != nil {
 		return nil, orm.WrapError(err, "SELECT", "")
 	}
@@ -592,7 +838,12 @@ func (db *RQLiteDirectDB) SelectManySQLParameterized(paramSQLs []orm.Parameterei
 
 // SelectOnlyOneSQLParameterized executes a parameterized SQL query and ensures exactly one row is returned
 func (db *RQLiteDirectDB) SelectOnlyOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
-	records, err := db.SelectOneSQLParameterized(paramSQL)
+	return db.SelectOnlyOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (db *RQLiteDirectDB) SelectOnlyOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
+	records, err := db.SelectOneSQLParameterizedContext(ctx, paramSQL)
 	if err != nil {
 		return orm.DBRecord{}, err // Already wrapped in SelectOneSQLParameterized
 	}
@@ -612,7 +863,12 @@ func (db *RQLiteDirectDB) SelectOnlyOneSQLParameterized(paramSQL orm.Parameterei
 
 // ExecOneSQL executes a single SQL statement
 func (db *RQLiteDirectDB) ExecOneSQL(sql string) orm.BasicSQLResult {
-	resp, err := db.execCommand([]string{sql})
+	return db.ExecOneSQLContext(context.Background(), sql)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (db *RQLiteDirectDB) ExecOneSQLContext(ctx context.Context, sql string) orm.BasicSQLResult {
+	resp, err := db.execCommand(ctx, []string{sql})
 	if err != nil {
 		tableName := getTableNameFromSQL(sql)
 		operation := getOperationFromSQL(sql)
@@ -630,7 +886,12 @@ func (db *RQLiteDirectDB) ExecOneSQL(sql string) orm.BasicSQLResult {
 
 // ExecOneSQLParameterized executes a single parameterized SQL statement
 func (db *RQLiteDirectDB) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
-	resp, err := db.execCommandParameterized([]orm.ParametereizedSQL{paramSQL})
+	return db.ExecOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (db *RQLiteDirectDB) ExecOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
+	resp, err := db.execCommandParameterized(ctx, []orm.ParametereizedSQL{paramSQL})
 	if err != nil {
 		tableName := getTableNameFromSQL(paramSQL.Query)
 		operation := getOperationFromSQL(paramSQL.Query)
@@ -648,7 +909,12 @@ func (db *RQLiteDirectDB) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL
 
 // ExecManySQL executes multiple SQL statements
 func (db *RQLiteDirectDB) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
-	resp, err := db.execCommand(sqls)
+	return db.ExecManySQLContext(context.Background(), sqls)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (db *RQLiteDirectDB) ExecManySQLContext(ctx context.Context, sqls []string) ([]orm.BasicSQLResult, error) {
+	resp, err := db.execCommand(ctx, sqls)
 	if err != nil {
 		return nil, orm.WrapError(err, "EXEC", "")
 	}
@@ -658,7 +924,12 @@ func (db *RQLiteDirectDB) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, erro
 
 // ExecManySQLParameterized executes multiple parameterized SQL statements
 func (db *RQLiteDirectDB) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
-	resp, err := db.execCommandParameterized(paramSQLs)
+	return db.ExecManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (db *RQLiteDirectDB) ExecManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	resp, err := db.execCommandParameterized(ctx, paramSQLs)
 	if err != nil {
 		return nil, orm.WrapError(err, "EXEC", "")
 	}
@@ -668,6 +939,11 @@ func (db *RQLiteDirectDB) ExecManySQLParameterized(paramSQLs []orm.Parametereize
 
 // InsertOneDBRecord inserts a single record
 func (db *RQLiteDirectDB) InsertOneDBRecord(record orm.DBRecord, queue bool) orm.BasicSQLResult {
+	return db.InsertOneDBRecordContext(context.Background(), record, queue)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (db *RQLiteDirectDB) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, queue bool) orm.BasicSQLResult {
 	sql, values := record.ToInsertSQLParameterized()
 	paramSQL := orm.ParametereizedSQL{
 		Query:  sql,
@@ -675,11 +951,16 @@ func (db *RQLiteDirectDB) InsertOneDBRecord(record orm.DBRecord, queue bool) orm
 	}
 
 	// RQLite doesn't have a queue mechanism like gorqlite, so we ignore the queue parameter
-	return db.ExecOneSQLParameterized(paramSQL)
+	return db.ExecOneSQLParameterizedContext(ctx, paramSQL)
 }
 
 // InsertManyDBRecords inserts multiple records
 func (db *RQLiteDirectDB) InsertManyDBRecords(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyDBRecordsContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (db *RQLiteDirectDB) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	paramSQLs := make([]orm.ParametereizedSQL, 0, len(records))
 
 	for _, record := range records {
@@ -692,11 +973,16 @@ func (db *RQLiteDirectDB) InsertManyDBRecords(records []orm.DBRecord, queue bool
 	}
 
 	// RQLite doesn't have a queue mechanism like gorqlite, so we ignore the queue parameter
-	return db.ExecManySQLParameterized(paramSQLs)
+	return db.ExecManySQLParameterizedContext(ctx, paramSQLs)
 }
 
 // InsertManyDBRecordsSameTable inserts multiple records into the same table
 func (db *RQLiteDirectDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyDBRecordsSameTableContext(context.Background(), records, queue)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (db *RQLiteDirectDB) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, queue bool) ([]orm.BasicSQLResult, error) {
 	if len(records) == 0 {
 		return nil, orm.WrapInsertError(fmt.Errorf("no records to insert"), "")
 	}
@@ -706,7 +992,7 @@ func (db *RQLiteDirectDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, q
 	paramSQLs := orm.DBRecords(records).ToInsertSQLParameterized()
 
 	// RQLite doesn't have a queue mechanism like gorqlite, so we ignore the queue parameter
-	results, err := db.ExecManySQLParameterized(paramSQLs)
+	results, err := db.ExecManySQLParameterizedContext(ctx, paramSQLs)
 	if err != nil {
 		return results, orm.WrapInsertError(err, tableName)
 	}
@@ -715,23 +1001,40 @@ func (db *RQLiteDirectDB) InsertManyDBRecordsSameTable(records []orm.DBRecord, q
 
 // InsertOneTableStruct inserts a single table struct
 func (db *RQLiteDirectDB) InsertOneTableStruct(obj orm.TableStruct, queue bool) orm.BasicSQLResult {
-	record, err := orm.TableStructToDBRecord(obj)
+	return db.InsertOneTableStructContext(context.Background(), obj, queue)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct.
+// The BeforeInsert and Validate hooks of obj run first, AfterInsert once the insert
+// succeeded (with queue, once RQLite accepted the queued write).
+func (db *RQLiteDirectDB) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct, queue bool) orm.BasicSQLResult {
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
 	if err != nil {
-		return orm.BasicSQLResult{Error: err}
+		return orm.BasicSQLResult{Error: orm.WrapInsertError(err, obj.TableName())}
 	}
 
-	return db.InsertOneDBRecord(record, queue)
+	result := db.InsertOneDBRecordContext(ctx, record, queue)
+	if result.Error == nil {
+		result.Error = orm.RunAfterInsert(ctx, obj)
+	}
+	return result
 }
 
 // InsertManyTableStructs inserts multiple table structs
 func (db *RQLiteDirectDB) InsertManyTableStructs(objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
+	return db.InsertManyTableStructsContext(context.Background(), objs, queue)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs.
+// The before-insert hooks of every struct run before anything is sent.
+func (db *RQLiteDirectDB) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct, queue bool) ([]orm.BasicSQLResult, error) {
 	if len(objs) == 0 {
 		return nil, orm.WrapInsertError(fmt.Errorf("no objects to insert"), "")
 	}
 
 	records := make([]orm.DBRecord, 0, len(objs))
 	for _, obj := range objs {
-		record, err := orm.TableStructToDBRecord(obj)
+		record, err := orm.TableStructToInsertRecord(ctx, obj)
 		if err != nil {
 			return nil, orm.WrapInsertError(err, obj.TableName())
 		}
@@ -748,9 +1051,84 @@ func (db *RQLiteDirectDB) InsertManyTableStructs(objs []orm.TableStruct, queue b
 		}
 	}
 
+	var results []orm.BasicSQLResult
+	var err error
 	if sameTables {
-		return db.InsertManyDBRecordsSameTable(records, queue)
+		results, err = db.InsertManyDBRecordsSameTableContext(ctx, records, queue)
+	} else {
+		results, err = db.InsertManyDBRecordsContext(ctx, records, queue)
+	}
+	if err != nil {
+		return results, err
+	}
+	return results, orm.RunAfterInsertAll(ctx, objs)
+}
+
+// UpdateWithCondition updates the columns in set on every row matching where.
+// An empty condition is refused unless allRows is true.
+func (db *RQLiteDirectDB) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return db.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
+
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (db *RQLiteDirectDB) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQL(tableName, set, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: orm.WrapUpdateError(err, tableName)}
+	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// DeleteWithCondition deletes every row matching where.
+// An empty condition is refused unless allRows is true.
+func (db *RQLiteDirectDB) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return db.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (db *RQLiteDirectDB) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQL(tableName, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: orm.WrapDeleteError(err, tableName)}
+	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// UpsertOneDBRecord inserts a record or resolves the conflict according to opts
+func (db *RQLiteDirectDB) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return db.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
+
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (db *RQLiteDirectDB) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	sql, values, err := record.ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: orm.WrapInsertError(err, record.TableName)}
 	}
+	return db.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(sql, values))
+}
+
+// UpsertManyDBRecordsSameTable upserts records of one table, batched by MAX_MULTIPLE_INSERTS.
+// No records is a no-op and returns nil, nil.
+func (db *RQLiteDirectDB) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return db.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
 
-	return db.InsertManyDBRecords(records, queue)
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (db *RQLiteDirectDB) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	tableName := records[0].TableName
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return nil, orm.WrapInsertError(err, tableName)
+	}
+
+	results, err := db.ExecManySQLParameterizedContext(ctx, paramSQLs)
+	if err != nil {
+		return results, orm.WrapInsertError(err, tableName)
+	}
+	return results, nil
 }
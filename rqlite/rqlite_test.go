@@ -1,6 +1,12 @@
 package rqlite
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -479,6 +485,11 @@ func TestQueryResultToDBRecord(t *testing.T) {
 				if len(records[0].Data) != len(tt.input.Columns) {
 					t.Errorf("Expected %d columns in data, got %d", len(tt.input.Columns), len(records[0].Data))
 				}
+				for i, col := range tt.input.Columns {
+					if got := records[0].Field(col).TypeDef; got != tt.input.Types[i] {
+						t.Errorf("Expected type %s for column %s, got %s", tt.input.Types[i], col, got)
+					}
+				}
 			}
 		})
 	}
@@ -570,3 +581,243 @@ func TestHTTPClientConfiguration(t *testing.T) {
 		t.Error("Expected HTTP client transport to be configured")
 	}
 }
+
+// TestSelectPage tests the page and count statements are sent together in consistent mode
+func TestSelectPage(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+
+		page := QueryResult{Columns: []string{"id"}, Values: [][]interface{}{{1}, {2}}}
+		count := QueryResult{Columns: []string{"total"}, Values: [][]interface{}{{5}}}
+		results := []QueryResult{page, count}
+		if len(body) == 1 {
+			results = results[len(requests)-1 : len(requests)]
+		}
+		json.NewEncoder(w).Encode(QueryResponse{Results: results})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabase(RqliteDirectConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	condition := &orm.Condition{Field: "status", Operator: "=", Value: "active", Limit: 2}
+
+	page, err := db.SelectPage("users", condition, orm.PageOptions{Consistent: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(requests) != 1 || requests[0].URL.Query().Get("transaction") != "true" || len(bodies[0]) != 2 {
+		t.Fatalf("Expected one transactional request with 2 statements, got %d requests", len(requests))
+	}
+	if len(page.Records) != 2 || page.Total == nil || *page.Total != 5 || !page.HasMore || page.HasPrev {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	requests, bodies = nil, nil
+	if _, err := db.SelectPage("users", condition, orm.PageOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(requests) != 2 || requests[0].URL.Query().Get("transaction") != "" {
+		t.Errorf("Expected two plain requests, got %d", len(requests))
+	}
+}
+
+// TestStreamWithCondition checks rows are streamed from the response and errors are yielded once
+func TestStreamWithCondition(t *testing.T) {
+	var bodies [][]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		values := make([][]interface{}, 1000)
+		for i := range values {
+			values[i] = []interface{}{i, "user"}
+		}
+		result := QueryResult{Columns: []string{"id", "name"}, Types: []string{"integer", "text"}, Values: values}
+		if len(bodies) == 3 {
+			result = QueryResult{Error: "no such table: users"}
+		}
+		json.NewEncoder(w).Encode(QueryResponse{Results: []QueryResult{result}})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabase(RqliteDirectConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	condition := &orm.Condition{Field: "status", Operator: "=", Value: "active"}
+
+	count := 0
+	for record, err := range db.StreamWithCondition("users", condition) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if record.TableName != "users" || record.Data["name"] != "user" {
+			t.Fatalf("Unexpected record: %+v", record)
+		}
+		count++
+	}
+	if count != 1000 {
+		t.Errorf("Expected 1000 rows, got %d", count)
+	}
+	if len(bodies) != 1 || len(bodies[0]) != 1 {
+		t.Fatalf("Expected one request with one statement, got %v", bodies)
+	}
+	if statement, ok := bodies[0][0].([]interface{}); !ok || strings.TrimSpace(statement[0].(string)) != "SELECT * FROM users WHERE status = ?" || statement[1] != "active" {
+		t.Errorf("Unexpected statement: %v", bodies[0][0])
+	}
+
+	count = 0
+	for _, err := range db.StreamWithCondition("users", nil) {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if count != 10 {
+		t.Errorf("Expected to stop after 10 rows, got %d", count)
+	}
+
+	var errs []error
+	for _, err := range db.StreamWithCondition("users", nil) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrRQLiteQueryFailed) {
+		t.Errorf("Expected one ErrRQLiteQueryFailed, got %v", errs)
+	}
+
+	errs = nil
+	for _, err := range db.StreamWithCondition("users;drop", nil) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], orm.ErrInvalidTableName) || len(bodies) != 3 {
+		t.Errorf("Expected one ErrInvalidTableName without a request, got %v", errs)
+	}
+}
+
+// TestExecManySQLTransaction checks ExecManySQL keeps its per-statement results while a
+// transaction is committed in one transactional request
+func TestExecManySQLTransaction(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		json.NewEncoder(w).Encode(ExecuteResponse{Results: []ExecuteResult{{RowsAffected: 1}, {RowsAffected: 2}}})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabase(RqliteDirectConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := db.ExecManySQL([]string{"UPDATE users SET active = 0", "DELETE FROM sessions"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	params := []orm.ParametereizedSQL{{Query: "DELETE FROM users WHERE id = ?", Values: []interface{}{1}}}
+	if _, err := db.ExecManySQLParameterized(params); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := tx.ExecManySQL([]string{"UPDATE users SET active = 0", "DELETE FROM sessions"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(requests))
+	}
+	for _, r := range requests[:2] {
+		if r.URL.Path != ENDPOINT_EXECUTE || r.URL.Query().Has("transaction") {
+			t.Errorf("Expected a plain request to %s, got %s", ENDPOINT_EXECUTE, r.URL)
+		}
+	}
+	if r := requests[2]; r.URL.Path != ENDPOINT_UNIFIED || r.URL.Query().Get("transaction") != "true" {
+		t.Errorf("Expected a transactional request to %s, got %s", ENDPOINT_UNIFIED, r.URL)
+	}
+}
+
+type hookItem struct {
+	ID       int64  `db:"id"`
+	Name     string `db:"name"`
+	inserted *int
+}
+
+func (i *hookItem) TableName() string { return "items" }
+
+func (i *hookItem) BeforeInsert(ctx context.Context) error {
+	if i.Name == "bad" {
+		return errors.New("bad name")
+	}
+	return nil
+}
+
+func (i *hookItem) AfterInsert(ctx context.Context) error {
+	*i.inserted++
+	return nil
+}
+
+// TestTransactionHooks checks a failing BeforeInsert aborts the buffered transaction
+// and AfterInsert only runs once Commit succeeded
+func TestTransactionHooks(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		json.NewEncoder(w).Encode(ExecuteResponse{Results: []ExecuteResult{{RowsAffected: 1}, {RowsAffected: 1}}})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabase(RqliteDirectConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	requests = nil
+
+	inserted := 0
+	tx, err := db.BeginTransaction()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result := tx.InsertOneTableStruct(&hookItem{Name: "good", inserted: &inserted}); result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+	if result := tx.InsertOneTableStruct(&hookItem{Name: "bad", inserted: &inserted}); !errors.Is(result.Error, orm.ErrHookFailed) {
+		t.Fatalf("Expected %v, got %v", orm.ErrHookFailed, result.Error)
+	}
+	if err := tx.Commit(); !errors.Is(err, orm.ErrHookFailed) {
+		t.Errorf("Expected the aborted transaction to refuse Commit, got %v", err)
+	}
+	if len(requests) != 0 || inserted != 0 {
+		t.Errorf("Expected nothing sent and no AfterInsert, got %d requests and %d hooks", len(requests), inserted)
+	}
+
+	tx, _ = db.BeginTransaction()
+	items := []orm.TableStruct{&hookItem{Name: "a", inserted: &inserted}, &hookItem{Name: "b", inserted: &inserted}}
+	if _, err := tx.InsertManyTableStructs(items); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if inserted != 0 {
+		t.Errorf("Expected AfterInsert to wait for Commit, got %d", inserted)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(requests) != 1 || inserted != 2 {
+		t.Errorf("Expected 1 request and 2 AfterInsert hooks, got %d and %d", len(requests), inserted)
+	}
+}
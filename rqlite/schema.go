@@ -0,0 +1,323 @@
+package rqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	orm "github.com/medatechnology/simpleorm"
+)
+
+// ListTables returns the names of the user tables, without SQLite's internal tables
+func (db *RQLiteDirectDB) ListTables() ([]string, error) {
+	return db.ListTablesContext(context.Background())
+}
+
+// ListTablesContext is the context-aware variant of ListTables
+func (db *RQLiteDirectDB) ListTablesContext(ctx context.Context) ([]string, error) {
+	query := "SELECT name FROM " + SCHEMA_TABLE + " WHERE type = 'table' AND name NOT LIKE '" + PREFIX_SQLITE_TABLE + "%' ORDER BY name"
+	resp, err := db.execQuery(ctx, []string{query})
+	if err != nil {
+		return nil, orm.WrapErrorWithQuery(err, "SELECT", SCHEMA_TABLE, query)
+	}
+
+	var tables []string
+	for _, result := range resp.Results {
+		for _, row := range result.Values {
+			if name, ok := row[0].(string); ok {
+				tables = append(tables, name)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// DescribeTable returns the columns and constraints of a table, read with the
+// PRAGMA table_info, index_list, index_info and foreign_key_list table-valued functions
+// in a single request. CHECK constraints are not exposed by any PRAGMA, they are read
+// from the CREATE TABLE statement stored in sqlite_master.
+//
+// Example:
+//
+//	info, err := db.DescribeTable("users")
+//	// info.Columns, info.PrimaryKey, info.Indexes, info.ForeignKeys, ...
+func (db *RQLiteDirectDB) DescribeTable(tableName string) (orm.TableInfo, error) {
+	return db.DescribeTableContext(context.Background(), tableName)
+}
+
+// DescribeTableContext is the context-aware variant of DescribeTable
+func (db *RQLiteDirectDB) DescribeTableContext(ctx context.Context, tableName string) (orm.TableInfo, error) {
+	queries, err := DescribeTableQueries(tableName)
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+	}
+	resp, err := db.execQueryParameterized(ctx, queries)
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapErrorWithQuery(err, "SELECT", tableName, queries[0].Query)
+	}
+	if len(resp.Results) != len(queries) {
+		return orm.TableInfo{}, orm.WrapSelectError(fmt.Errorf("expected %d results, got %d", len(queries), len(resp.Results)), tableName)
+	}
+
+	var results [][]orm.DBRecord
+	for _, result := range resp.Results {
+		records, err := queryResultToDBRecord(result, tableName)
+		if err != nil && !errors.Is(err, orm.ErrSQLNoRows) {
+			return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+		}
+		results = append(results, records)
+	}
+
+	info, err := TableInfoFromPragmaResults(tableName, results)
+	if err != nil {
+		return orm.TableInfo{}, orm.WrapSelectError(err, tableName)
+	}
+	return info, nil
+}
+
+// DescribeTableQueries returns the PRAGMA queries read by DescribeTable, in the order
+// TableInfoFromPragmaResults expects their results. The gorqlite backend sends them too.
+func DescribeTableQueries(tableName string) ([]orm.ParametereizedSQL, error) {
+	if err := orm.ValidateTableName(tableName); err != nil {
+		return nil, err
+	}
+	schema, table := orm.SplitTableName(tableName)
+	if schema == "" {
+		schema = "main"
+	}
+
+	// The schema is a validated identifier, the pragma arguments are bound
+	return []orm.ParametereizedSQL{
+		{Query: "SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?, ?) ORDER BY cid", Values: []interface{}{table, schema}},
+		{Query: "SELECT il.name AS index_name, il.\"unique\" AS is_unique, il.origin, ii.name AS column_name " +
+			"FROM pragma_index_list(?, ?) AS il JOIN pragma_index_info(il.name, ?) AS ii " +
+			"ORDER BY il.name, ii.seqno", Values: []interface{}{table, schema, schema}},
+		{Query: "SELECT id, \"table\", \"from\", \"to\", on_update, on_delete FROM pragma_foreign_key_list(?, ?) ORDER BY id, seq", Values: []interface{}{table, schema}},
+		{Query: "SELECT sql FROM " + orm.FormatIdentifier(orm.SQLiteDialect{}, schema) + "." + SCHEMA_TABLE + " WHERE type = 'table' AND name = ?", Values: []interface{}{table}},
+	}, nil
+}
+
+// TableInfoFromPragmaResults builds the TableInfo from the rows returned by each of the
+// DescribeTableQueries (an empty slice for a query without rows). It returns
+// orm.ErrTableNotFound when the table has no columns.
+func TableInfoFromPragmaResults(tableName string, results [][]orm.DBRecord) (orm.TableInfo, error) {
+	if len(results) != 4 {
+		return orm.TableInfo{}, fmt.Errorf("expected 4 results, got %d", len(results))
+	}
+	if len(results[0]) == 0 {
+		return orm.TableInfo{}, orm.ErrTableNotFound
+	}
+
+	info, err := tableInfoFromPragmas(tableName, results[0], results[1], results[2])
+	if err != nil {
+		return orm.TableInfo{}, err
+	}
+	if len(results[3]) > 0 {
+		createSQL, _ := results[3][0].String("sql")
+		info.Checks = parseCheckConstraints(createSQL)
+	}
+	return info, nil
+}
+
+// tableInfoFromPragmas builds a TableInfo from the rows of the table_info, index_list/index_info
+// and foreign_key_list pragmas
+func tableInfoFromPragmas(tableName string, columns, indexes, foreignKeys []orm.DBRecord) (orm.TableInfo, error) {
+	info := orm.TableInfo{Name: tableName}
+
+	// pk is the 1-based position of the column in the primary key, 0 if not part of it
+	var keyColumns []string
+	for _, row := range columns {
+		name, err := row.String("name")
+		if err != nil {
+			return orm.TableInfo{}, err
+		}
+		columnType, _ := row.String("type")
+		notNull, err := row.Bool("notnull")
+		if err != nil {
+			return orm.TableInfo{}, err
+		}
+		pk, err := row.Int64("pk")
+		if err != nil {
+			return orm.TableInfo{}, err
+		}
+
+		column := orm.ColumnInfo{Name: name, Type: columnType, Nullable: !notNull && pk == 0, PrimaryKey: pk > 0}
+		if !row.IsNull("dflt_value") {
+			defaultValue, _ := row.String("dflt_value")
+			column.Default = &defaultValue
+		}
+		info.Columns = append(info.Columns, column)
+
+		if pk > 0 {
+			for len(keyColumns) < int(pk) {
+				keyColumns = append(keyColumns, "")
+			}
+			keyColumns[pk-1] = name
+		}
+	}
+	info.PrimaryKey = keyColumns
+
+	// origin: c = CREATE INDEX, u = UNIQUE constraint, pk = PRIMARY KEY
+	for _, row := range indexes {
+		name, _ := row.String("index_name")
+		origin, _ := row.String("origin")
+		column, _ := row.String("column_name")
+		unique, _ := row.Bool("is_unique")
+
+		switch origin {
+		case "u":
+			n := len(info.UniqueConstraints)
+			if n == 0 || info.UniqueConstraints[n-1].Name != name {
+				info.UniqueConstraints = append(info.UniqueConstraints, orm.UniqueConstraint{Name: name})
+				n++
+			}
+			if column != "" {
+				info.UniqueConstraints[n-1].Columns = append(info.UniqueConstraints[n-1].Columns, column)
+			}
+		case "c":
+			n := len(info.Indexes)
+			if n == 0 || info.Indexes[n-1].Name != name {
+				info.Indexes = append(info.Indexes, orm.IndexInfo{Name: name, Unique: unique})
+				n++
+			}
+			if column != "" {
+				info.Indexes[n-1].Columns = append(info.Indexes[n-1].Columns, column)
+			}
+		}
+	}
+
+	// one row per column, rows of the same constraint share the id
+	lastID := int64(-1)
+	for _, row := range foreignKeys {
+		id, err := row.Int64("id")
+		if err != nil {
+			return orm.TableInfo{}, err
+		}
+		if id != lastID {
+			refTable, _ := row.String("table")
+			onUpdate, _ := row.String("on_update")
+			onDelete, _ := row.String("on_delete")
+			info.ForeignKeys = append(info.ForeignKeys, orm.ForeignKeyInfo{RefTable: refTable, OnUpdate: onUpdate, OnDelete: onDelete})
+			lastID = id
+		}
+		fk := &info.ForeignKeys[len(info.ForeignKeys)-1]
+		from, _ := row.String("from")
+		fk.Columns = append(fk.Columns, from)
+		if !row.IsNull("to") {
+			to, _ := row.String("to")
+			fk.RefColumns = append(fk.RefColumns, to)
+		}
+	}
+
+	return info, nil
+}
+
+// parseCheckConstraints extracts the CHECK constraints of a CREATE TABLE statement, with the
+// name given by a preceding CONSTRAINT clause. String literals, quoted identifiers and
+// comments are skipped, so a "check" inside them is not taken for a constraint.
+func parseCheckConstraints(createSQL string) []orm.CheckConstraint {
+	var checks []orm.CheckConstraint
+	var words []string // previous words, to find CONSTRAINT name before CHECK
+	for i := 0; i < len(createSQL); {
+		c := createSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := skipQuoted(createSQL, i)
+			words = append(words, createSQL[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(createSQL[i:], "--"):
+			end := strings.IndexByte(createSQL[i:], '\n')
+			if end < 0 {
+				return checks
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(createSQL[i:], "/*"):
+			end := strings.Index(createSQL[i+2:], "*/")
+			if end < 0 {
+				return checks
+			}
+			i += end + 4
+		case isWordByte(c):
+			start := i
+			for i < len(createSQL) && isWordByte(createSQL[i]) {
+				i++
+			}
+			word := createSQL[start:i]
+			if !strings.EqualFold(word, "CHECK") {
+				words = append(words, word)
+				continue
+			}
+
+			open := i
+			for open < len(createSQL) && (createSQL[open] == ' ' || createSQL[open] == '\t' || createSQL[open] == '\n' || createSQL[open] == '\r') {
+				open++
+			}
+			if open >= len(createSQL) || createSQL[open] != '(' {
+				continue
+			}
+			end := matchingParen(createSQL, open)
+			if end < 0 {
+				return checks
+			}
+			check := orm.CheckConstraint{Expression: strings.TrimSpace(createSQL[open+1 : end])}
+			if n := len(words); n >= 2 && strings.EqualFold(words[n-2], "CONSTRAINT") {
+				check.Name = strings.Trim(words[n-1], "\"`[]")
+			}
+			checks = append(checks, check)
+			words = nil
+			i = end + 1
+		default:
+			if c == ',' || c == '(' || c == ')' {
+				words = nil
+			}
+			i++
+		}
+	}
+	return checks
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open, -1 if missing
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); {
+		switch s[i] {
+		case '\'', '"', '`', '[':
+			i = skipQuoted(s, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// skipQuoted returns the index after the quoted string or identifier starting at i
+// (doubled quotes are escapes, [name] closes with ])
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	if quote == '[' {
+		quote = ']'
+	}
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == quote {
+			if j+1 < len(s) && s[j+1] == quote && quote != ']' {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// isWordByte reports whether c can be part of a bare SQL word
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,145 @@
+package rqlite
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	orm "github.com/medatechnology/simpleorm"
+)
+
+// TestParseCheckConstraints tests reading CHECK constraints from a CREATE TABLE statement
+func TestParseCheckConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []orm.CheckConstraint
+	}{
+		{
+			name: "column and table constraints",
+			sql: `CREATE TABLE products (id INTEGER PRIMARY KEY, price REAL CHECK (price > 0), ` +
+				`qty INTEGER, CONSTRAINT qty_range CHECK(qty BETWEEN 0 AND 100 AND (qty % 2) = 0))`,
+			want: []orm.CheckConstraint{
+				{Expression: "price > 0"},
+				{Name: "qty_range", Expression: "qty BETWEEN 0 AND 100 AND (qty % 2) = 0"},
+			},
+		},
+		{
+			name: "quoted names and literals",
+			sql: `CREATE TABLE "check" ("check" TEXT DEFAULT 'check (x)', status TEXT ` +
+				`CONSTRAINT "status check" CHECK (status IN ('a)', 'b')))`,
+			want: []orm.CheckConstraint{{Name: "status check", Expression: "status IN ('a)', 'b')"}},
+		},
+		{
+			name: "comments",
+			sql:  "CREATE TABLE t (a INT -- CHECK (a > 1)\n, b INT /* check (b) */ CHECK (b < 5))",
+			want: []orm.CheckConstraint{{Expression: "b < 5"}},
+		},
+		{
+			name: "no checks",
+			sql:  "CREATE TABLE t (checked INTEGER, check_count INTEGER)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCheckConstraints(tt.sql)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestDescribeTable tests building a TableInfo from the pragma results
+func TestDescribeTable(t *testing.T) {
+	results := []QueryResult{
+		{
+			Columns: []string{"name", "type", "notnull", "dflt_value", "pk"},
+			Types:   []string{"text", "text", "integer", "text", "integer"},
+			Values: [][]interface{}{
+				{"tenant_id", "INTEGER", 1, nil, 1},
+				{"id", "INTEGER", 1, nil, 2},
+				{"email", "VARCHAR(255)", 0, nil, 0},
+				{"status", "TEXT", 1, "'active'", 0},
+				{"team_id", "INTEGER", 0, nil, 0},
+			},
+		},
+		{
+			Columns: []string{"index_name", "is_unique", "origin", "column_name"},
+			Values: [][]interface{}{
+				{"idx_users_status", 0, "c", "status"},
+				{"sqlite_autoindex_users_1", 1, "pk", "tenant_id"},
+				{"sqlite_autoindex_users_1", 1, "pk", "id"},
+				{"sqlite_autoindex_users_2", 1, "u", "tenant_id"},
+				{"sqlite_autoindex_users_2", 1, "u", "email"},
+			},
+		},
+		{
+			Columns: []string{"id", "table", "from", "to", "on_update", "on_delete"},
+			Values: [][]interface{}{
+				{0, "teams", "team_id", nil, "NO ACTION", "SET NULL"},
+			},
+		},
+		{
+			Columns: []string{"sql"},
+			Values:  [][]interface{}{{"CREATE TABLE users (status TEXT NOT NULL DEFAULT 'active' CHECK (status <> ''))"}},
+		},
+	}
+
+	var bodies [][]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if len(bodies) == 2 {
+			json.NewEncoder(w).Encode(QueryResponse{Results: []QueryResult{{Columns: []string{"name"}}, {}, {}, {}}})
+			return
+		}
+		json.NewEncoder(w).Encode(QueryResponse{Results: results})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabase(RqliteDirectConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	info, err := db.DescribeTable("users")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bodies) != 1 || len(bodies[0]) != 4 {
+		t.Fatalf("Expected one request with 4 statements, got %v", bodies)
+	}
+
+	active := "'active'"
+	want := orm.TableInfo{
+		Name: "users",
+		Columns: []orm.ColumnInfo{
+			{Name: "tenant_id", Type: "INTEGER", PrimaryKey: true},
+			{Name: "id", Type: "INTEGER", PrimaryKey: true},
+			{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			{Name: "status", Type: "TEXT", Default: &active},
+			{Name: "team_id", Type: "INTEGER", Nullable: true},
+		},
+		PrimaryKey:        []string{"tenant_id", "id"},
+		Indexes:           []orm.IndexInfo{{Name: "idx_users_status", Columns: []string{"status"}}},
+		ForeignKeys:       []orm.ForeignKeyInfo{{Columns: []string{"team_id"}, RefTable: "teams", OnUpdate: "NO ACTION", OnDelete: "SET NULL"}},
+		UniqueConstraints: []orm.UniqueConstraint{{Name: "sqlite_autoindex_users_2", Columns: []string{"tenant_id", "email"}}},
+		Checks:            []orm.CheckConstraint{{Expression: "status <> ''"}},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+
+	if _, err := db.DescribeTable("missing"); !errors.Is(err, orm.ErrTableNotFound) {
+		t.Errorf("Expected ErrTableNotFound, got %v", err)
+	}
+	if _, err := db.DescribeTable("users; DROP TABLE users"); !errors.Is(err, orm.ErrInvalidTableName) {
+		t.Errorf("Expected ErrInvalidTableName, got %v", err)
+	}
+}
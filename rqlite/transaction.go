@@ -1,6 +1,7 @@
 package rqlite
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,16 +14,27 @@ import (
 // and sent atomically to the /db/request endpoint on Commit.
 type rqliteTransaction struct {
 	db              *RQLiteDirectDB
+	ctx             context.Context           // Context bound to the transaction, used on Commit
 	statements      []string                  // Buffered SQL statements
 	paramStatements []orm.ParametereizedSQL   // Buffered parameterized statements
 	committed       bool                      // Track if transaction is committed
 	rolledBack      bool                      // Track if transaction is rolled back
+	inserted        []orm.TableStruct         // Buffered structs whose AfterInsert hook runs on Commit
+	abortErr        error                     // Hook error that rolled the transaction back
 }
 
 // BeginTransaction starts a new transaction by creating a transaction buffer
 func (db *RQLiteDirectDB) BeginTransaction() (orm.Transaction, error) {
+	return db.BeginTransactionContext(context.Background())
+}
+
+// BeginTransactionContext starts a new buffered transaction bound to ctx.
+// Because nothing is sent to RQLite before Commit, ctx mostly governs the
+// final /db/request call; buffering operations fails once ctx is done.
+func (db *RQLiteDirectDB) BeginTransactionContext(ctx context.Context) (orm.TransactionContext, error) {
 	return &rqliteTransaction{
 		db:              db,
+		ctx:             ctx,
 		statements:      make([]string, 0),
 		paramStatements: make([]orm.ParametereizedSQL, 0),
 		committed:       false,
@@ -30,11 +42,15 @@ func (db *RQLiteDirectDB) BeginTransaction() (orm.Transaction, error) {
 	}, nil
 }
 
-// Commit sends all buffered operations to RQLite atomically via /db/request endpoint
+// Commit sends all buffered operations to RQLite atomically via /db/request endpoint,
+// then runs the AfterInsert hooks of the buffered structs
 func (tx *rqliteTransaction) Commit() error {
 	if tx.committed {
 		return fmt.Errorf("transaction already committed")
 	}
+	if tx.abortErr != nil {
+		return fmt.Errorf("transaction aborted: %w", tx.abortErr)
+	}
 	if tx.rolledBack {
 		return fmt.Errorf("transaction already rolled back")
 	}
@@ -58,13 +74,13 @@ func (tx *rqliteTransaction) Commit() error {
 	}
 
 	// Send all statements atomically via /db/request endpoint
-	err := tx.db.execRequestUnified(allStatements, tx.paramStatements)
+	err := tx.db.execRequestUnified(tx.ctx, allStatements, tx.paramStatements)
 	if err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	tx.committed = true
-	return nil
+	return orm.RunAfterInsertAll(tx.ctx, tx.inserted)
 }
 
 // Rollback discards all buffered operations without sending them to RQLite
@@ -79,6 +95,7 @@ func (tx *rqliteTransaction) Rollback() error {
 	// Clear all buffered statements
 	tx.statements = nil
 	tx.paramStatements = nil
+	tx.inserted = nil
 	tx.rolledBack = true
 
 	return nil
@@ -86,12 +103,20 @@ func (tx *rqliteTransaction) Rollback() error {
 
 // ExecOneSQL buffers a SQL statement for execution on commit
 func (tx *rqliteTransaction) ExecOneSQL(sqlStmt string) orm.BasicSQLResult {
+	return tx.ExecOneSQLContext(context.Background(), sqlStmt)
+}
+
+// ExecOneSQLContext is the context-aware variant of ExecOneSQL
+func (tx *rqliteTransaction) ExecOneSQLContext(ctx context.Context, sqlStmt string) orm.BasicSQLResult {
 	if tx.committed {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already committed")}
 	}
 	if tx.rolledBack {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already rolled back")}
 	}
+	if err := ctx.Err(); err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
 
 	tx.statements = append(tx.statements, sqlStmt)
 	return orm.BasicSQLResult{} // Success will be determined on Commit
@@ -99,12 +124,20 @@ func (tx *rqliteTransaction) ExecOneSQL(sqlStmt string) orm.BasicSQLResult {
 
 // ExecOneSQLParameterized buffers a parameterized SQL statement
 func (tx *rqliteTransaction) ExecOneSQLParameterized(paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
+	return tx.ExecOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// ExecOneSQLParameterizedContext is the context-aware variant of ExecOneSQLParameterized
+func (tx *rqliteTransaction) ExecOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) orm.BasicSQLResult {
 	if tx.committed {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already committed")}
 	}
 	if tx.rolledBack {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already rolled back")}
 	}
+	if err := ctx.Err(); err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
 
 	tx.paramStatements = append(tx.paramStatements, paramSQL)
 	return orm.BasicSQLResult{} // Success will be determined on Commit
@@ -112,12 +145,20 @@ func (tx *rqliteTransaction) ExecOneSQLParameterized(paramSQL orm.Parametereized
 
 // ExecManySQL buffers multiple SQL statements
 func (tx *rqliteTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, error) {
+	return tx.ExecManySQLContext(context.Background(), sqls)
+}
+
+// ExecManySQLContext is the context-aware variant of ExecManySQL
+func (tx *rqliteTransaction) ExecManySQLContext(ctx context.Context, sqls []string) ([]orm.BasicSQLResult, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
 	if tx.rolledBack {
 		return nil, fmt.Errorf("transaction already rolled back")
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	results := make([]orm.BasicSQLResult, 0, len(sqls))
 	for _, sql := range sqls {
@@ -130,12 +171,20 @@ func (tx *rqliteTransaction) ExecManySQL(sqls []string) ([]orm.BasicSQLResult, e
 
 // ExecManySQLParameterized buffers multiple parameterized SQL statements
 func (tx *rqliteTransaction) ExecManySQLParameterized(paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
+	return tx.ExecManySQLParameterizedContext(context.Background(), paramSQLs)
+}
+
+// ExecManySQLParameterizedContext is the context-aware variant of ExecManySQLParameterized
+func (tx *rqliteTransaction) ExecManySQLParameterizedContext(ctx context.Context, paramSQLs []orm.ParametereizedSQL) ([]orm.BasicSQLResult, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
 	if tx.rolledBack {
 		return nil, fmt.Errorf("transaction already rolled back")
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	results := make([]orm.BasicSQLResult, 0, len(paramSQLs))
 	for _, paramSQL := range paramSQLs {
@@ -150,6 +199,11 @@ func (tx *rqliteTransaction) ExecManySQLParameterized(paramSQLs []orm.Parametere
 // Note: This is a limitation of RQLite's buffered transaction model
 // For immediate results, don't use transactions for SELECT queries
 func (tx *rqliteTransaction) SelectOneSQL(sqlStmt string) (orm.DBRecords, error) {
+	return tx.SelectOneSQLContext(context.Background(), sqlStmt)
+}
+
+// SelectOneSQLContext is the context-aware variant of SelectOneSQL
+func (tx *rqliteTransaction) SelectOneSQLContext(ctx context.Context, sqlStmt string) (orm.DBRecords, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
@@ -160,12 +214,17 @@ func (tx *rqliteTransaction) SelectOneSQL(sqlStmt string) (orm.DBRecords, error)
 	// For SELECT within transactions in RQLite, we need to execute immediately
 	// because the buffered model doesn't support deferred reads
 	// This is a fundamental difference from PostgreSQL
-	return tx.db.SelectOneSQL(sqlStmt)
+	return tx.db.SelectOneSQLContext(ctx, sqlStmt)
 }
 
 // SelectOnlyOneSQL executes a SELECT query that must return exactly one row
 func (tx *rqliteTransaction) SelectOnlyOneSQL(sqlStmt string) (orm.DBRecord, error) {
-	records, err := tx.SelectOneSQL(sqlStmt)
+	return tx.SelectOnlyOneSQLContext(context.Background(), sqlStmt)
+}
+
+// SelectOnlyOneSQLContext is the context-aware variant of SelectOnlyOneSQL
+func (tx *rqliteTransaction) SelectOnlyOneSQLContext(ctx context.Context, sqlStmt string) (orm.DBRecord, error) {
+	records, err := tx.SelectOneSQLContext(ctx, sqlStmt)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -183,6 +242,11 @@ func (tx *rqliteTransaction) SelectOnlyOneSQL(sqlStmt string) (orm.DBRecord, err
 
 // SelectOneSQLParameterized executes a parameterized SELECT query
 func (tx *rqliteTransaction) SelectOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
+	return tx.SelectOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOneSQLParameterizedContext is the context-aware variant of SelectOneSQLParameterized
+func (tx *rqliteTransaction) SelectOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecords, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
@@ -191,12 +255,17 @@ func (tx *rqliteTransaction) SelectOneSQLParameterized(paramSQL orm.Parametereiz
 	}
 
 	// SELECT queries must be executed immediately in RQLite transactions
-	return tx.db.SelectOneSQLParameterized(paramSQL)
+	return tx.db.SelectOneSQLParameterizedContext(ctx, paramSQL)
 }
 
 // SelectOnlyOneSQLParameterized executes a parameterized SELECT that returns exactly one row
 func (tx *rqliteTransaction) SelectOnlyOneSQLParameterized(paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
-	records, err := tx.SelectOneSQLParameterized(paramSQL)
+	return tx.SelectOnlyOneSQLParameterizedContext(context.Background(), paramSQL)
+}
+
+// SelectOnlyOneSQLParameterizedContext is the context-aware variant of SelectOnlyOneSQLParameterized
+func (tx *rqliteTransaction) SelectOnlyOneSQLParameterizedContext(ctx context.Context, paramSQL orm.ParametereizedSQL) (orm.DBRecord, error) {
+	records, err := tx.SelectOneSQLParameterizedContext(ctx, paramSQL)
 	if err != nil {
 		return orm.DBRecord{}, err
 	}
@@ -214,12 +283,20 @@ func (tx *rqliteTransaction) SelectOnlyOneSQLParameterized(paramSQL orm.Paramete
 
 // InsertOneDBRecord buffers an insert operation
 func (tx *rqliteTransaction) InsertOneDBRecord(record orm.DBRecord) orm.BasicSQLResult {
+	return tx.InsertOneDBRecordContext(context.Background(), record)
+}
+
+// InsertOneDBRecordContext is the context-aware variant of InsertOneDBRecord
+func (tx *rqliteTransaction) InsertOneDBRecordContext(ctx context.Context, record orm.DBRecord) orm.BasicSQLResult {
 	if tx.committed {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already committed")}
 	}
 	if tx.rolledBack {
 		return orm.BasicSQLResult{Error: fmt.Errorf("transaction already rolled back")}
 	}
+	if err := ctx.Err(); err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
 
 	// Validate table name
 	if err := orm.ValidateTableName(record.TableName); err != nil {
@@ -254,6 +331,11 @@ func (tx *rqliteTransaction) InsertOneDBRecord(record orm.DBRecord) orm.BasicSQL
 
 // InsertManyDBRecords buffers multiple insert operations
 func (tx *rqliteTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyDBRecordsContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsContext is the context-aware variant of InsertManyDBRecords
+func (tx *rqliteTransaction) InsertManyDBRecordsContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
@@ -267,7 +349,7 @@ func (tx *rqliteTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]orm.
 
 	results := make([]orm.BasicSQLResult, 0, len(records))
 	for _, record := range records {
-		result := tx.InsertOneDBRecord(record)
+		result := tx.InsertOneDBRecordContext(ctx, record)
 		results = append(results, result)
 		if result.Error != nil {
 			return results, result.Error
@@ -279,6 +361,11 @@ func (tx *rqliteTransaction) InsertManyDBRecords(records []orm.DBRecord) ([]orm.
 
 // InsertManyDBRecordsSameTable buffers batch insert for same table
 func (tx *rqliteTransaction) InsertManyDBRecordsSameTable(records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyDBRecordsSameTableContext(context.Background(), records)
+}
+
+// InsertManyDBRecordsSameTableContext is the context-aware variant of InsertManyDBRecordsSameTable
+func (tx *rqliteTransaction) InsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord) ([]orm.BasicSQLResult, error) {
 	if tx.committed {
 		return nil, fmt.Errorf("transaction already committed")
 	}
@@ -301,32 +388,125 @@ func (tx *rqliteTransaction) InsertManyDBRecordsSameTable(records []orm.DBRecord
 
 	// For RQLite, we'll just buffer individual inserts
 	// Could be optimized with multi-row INSERT syntax
-	return tx.InsertManyDBRecords(records)
+	return tx.InsertManyDBRecordsContext(ctx, records)
 }
 
 // InsertOneTableStruct buffers insert for a TableStruct
 func (tx *rqliteTransaction) InsertOneTableStruct(obj orm.TableStruct) orm.BasicSQLResult {
-	record, err := orm.TableStructToDBRecord(obj)
+	return tx.InsertOneTableStructContext(context.Background(), obj)
+}
+
+// InsertOneTableStructContext is the context-aware variant of InsertOneTableStruct.
+// An error from the BeforeInsert or Validate hook of obj rolls the transaction back,
+// since nothing buffered so far can be partially committed. AfterInsert runs on Commit.
+func (tx *rqliteTransaction) InsertOneTableStructContext(ctx context.Context, obj orm.TableStruct) orm.BasicSQLResult {
+	record, err := tx.insertRecord(ctx, obj)
 	if err != nil {
 		return orm.BasicSQLResult{Error: err}
 	}
-	return tx.InsertOneDBRecord(record)
+	result := tx.InsertOneDBRecordContext(ctx, record)
+	if result.Error == nil {
+		tx.inserted = append(tx.inserted, obj)
+	}
+	return result
 }
 
 // InsertManyTableStructs buffers inserts for multiple TableStructs
 func (tx *rqliteTransaction) InsertManyTableStructs(objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
+	return tx.InsertManyTableStructsContext(context.Background(), objs)
+}
+
+// InsertManyTableStructsContext is the context-aware variant of InsertManyTableStructs
+func (tx *rqliteTransaction) InsertManyTableStructsContext(ctx context.Context, objs []orm.TableStruct) ([]orm.BasicSQLResult, error) {
 	if len(objs) == 0 {
 		return nil, nil
 	}
 
 	records := make([]orm.DBRecord, 0, len(objs))
 	for _, obj := range objs {
-		record, err := orm.TableStructToDBRecord(obj)
+		record, err := tx.insertRecord(ctx, obj)
 		if err != nil {
 			return nil, err
 		}
 		records = append(records, record)
 	}
 
-	return tx.InsertManyDBRecords(records)
+	results, err := tx.InsertManyDBRecordsContext(ctx, records)
+	if err == nil {
+		tx.inserted = append(tx.inserted, objs...)
+	}
+	return results, err
+}
+
+// insertRecord runs the before-insert hooks of obj and converts it, rolling the
+// transaction back when a hook fails
+func (tx *rqliteTransaction) insertRecord(ctx context.Context, obj orm.TableStruct) (orm.DBRecord, error) {
+	record, err := orm.TableStructToInsertRecord(ctx, obj)
+	if err != nil {
+		if !tx.committed {
+			tx.Rollback()
+			tx.abortErr = err
+		}
+		return record, orm.WrapInsertError(err, obj.TableName())
+	}
+	return record, nil
+}
+
+// UpdateWithCondition buffers an UPDATE built from set and where
+func (tx *rqliteTransaction) UpdateWithCondition(tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return tx.UpdateWithConditionContext(context.Background(), tableName, set, where, allRows)
+}
+
+// UpdateWithConditionContext is the context-aware variant of UpdateWithCondition
+func (tx *rqliteTransaction) UpdateWithConditionContext(ctx context.Context, tableName string, set map[string]interface{}, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToUpdateSQL(tableName, set, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// DeleteWithCondition buffers a DELETE built from where
+func (tx *rqliteTransaction) DeleteWithCondition(tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	return tx.DeleteWithConditionContext(context.Background(), tableName, where, allRows)
+}
+
+// DeleteWithConditionContext is the context-aware variant of DeleteWithCondition
+func (tx *rqliteTransaction) DeleteWithConditionContext(ctx context.Context, tableName string, where *orm.Condition, allRows bool) orm.BasicSQLResult {
+	query, values, err := orm.ToDeleteSQL(tableName, where, allRows)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(query, values))
+}
+
+// UpsertOneDBRecord buffers an upsert of a single record
+func (tx *rqliteTransaction) UpsertOneDBRecord(record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	return tx.UpsertOneDBRecordContext(context.Background(), record, opts)
+}
+
+// UpsertOneDBRecordContext is the context-aware variant of UpsertOneDBRecord
+func (tx *rqliteTransaction) UpsertOneDBRecordContext(ctx context.Context, record orm.DBRecord, opts orm.UpsertOptions) orm.BasicSQLResult {
+	sql, values, err := record.ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return orm.BasicSQLResult{Error: err}
+	}
+	return tx.ExecOneSQLParameterizedContext(ctx, orm.SQLAndValuesToParameterized(sql, values))
+}
+
+// UpsertManyDBRecordsSameTable buffers batched upserts for records of one table
+func (tx *rqliteTransaction) UpsertManyDBRecordsSameTable(records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	return tx.UpsertManyDBRecordsSameTableContext(context.Background(), records, opts)
+}
+
+// UpsertManyDBRecordsSameTableContext is the context-aware variant of UpsertManyDBRecordsSameTable
+func (tx *rqliteTransaction) UpsertManyDBRecordsSameTableContext(ctx context.Context, records []orm.DBRecord, opts orm.UpsertOptions) ([]orm.BasicSQLResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	paramSQLs, err := orm.DBRecords(records).ToUpsertSQLParameterized(opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ExecManySQLParameterizedContext(ctx, paramSQLs)
 }
@@ -0,0 +1,456 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed select helpers. They run the regular Database select methods and then
+// map each DBRecord into a struct using the `db` tags (see metadata.go), calling the
+// AfterSelect hook of each struct (see hooks.go).
+// The second type parameter lets TableName be declared on the pointer receiver,
+// so callers only spell the struct type:
+//
+//	users, err := orm.SelectInto[User](db, &orm.Condition{Field: "age", Operator: ">", Value: 18})
+//	user, err := orm.SelectOneInto[User](db, &orm.Condition{Field: "id", Operator: "=", Value: 1})
+
+// Layouts tried (in order) when a string has to become a time.Time
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05",
+}
+
+// SelectInto selects all rows matching the condition from T's table and
+// returns them as a slice of T.
+func SelectInto[T any, PT interface {
+	*T
+	TableStruct
+}](db Database, condition *Condition) ([]T, error) {
+	table := PT(new(T)).TableName()
+	records, err := db.SelectManyWithCondition(table, condition)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ScanRecords[T](records)
+	if err != nil {
+		return nil, err
+	}
+	if err := afterSelectAll(context.Background(), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SelectOneInto selects one row matching the condition from T's table
+func SelectOneInto[T any, PT interface {
+	*T
+	TableStruct
+}](db Database, condition *Condition) (T, error) {
+	var result T
+	table := PT(&result).TableName()
+	record, err := db.SelectOneWithCondition(table, condition)
+	if err != nil {
+		return result, err
+	}
+	if err = ScanRecord(record, &result); err != nil {
+		return result, err
+	}
+	err = RunAfterSelect(context.Background(), &result)
+	return result, err
+}
+
+// ScanRecords converts DBRecords into a slice of T
+func ScanRecords[T any](records []DBRecord) ([]T, error) {
+	result := make([]T, len(records))
+	for i := range records {
+		if err := ScanRecord(records[i], &result[i]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+// ScanRecord copies the record data into dest, which must be a pointer to a struct.
+// Columns without a matching field are ignored, fields without a column keep their value.
+func ScanRecord(record DBRecord, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	rv = rv.Elem()
+	info := getStructInfo(rv.Type())
+
+	for column, value := range record.Data {
+		field, ok := info.FieldByColumn(column)
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndexAlloc(rv, field.Index)
+		if err != nil {
+			return err
+		}
+		if err := assignValue(fv, value); err != nil {
+			return fmt.Errorf("column %s into field %s: %w", column, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndexAlloc is reflect.Value.FieldByIndex that allocates nil embedded pointers
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer of type %s", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// assignValue converts a value coming from a backend (float64 numbers from
+// rqlite JSON, json.Number, strings for timestamps, []byte, nil for NULL...)
+// into the destination field.
+func assignValue(dst reflect.Value, src interface{}) error {
+	// NULL resets the field to its zero value (nil for pointers)
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	// sql.Scanner (sql.NullString, custom types...) handles its own conversion
+	if dst.CanAddr() {
+		if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+			if n, isNumber := src.(json.Number); isNumber {
+				src = JSONNumberValue(n)
+			}
+			return scanner.Scan(src)
+		}
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if dst.Type() == timeType {
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, dst.Type())
+		}
+		dst.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := toUint64(src)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowUint(u) {
+			return fmt.Errorf("value %d overflows %s", u, dst.Type())
+		}
+		dst.SetUint(u)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Bool:
+		b, err := toBool(src)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+		return nil
+
+	case reflect.String:
+		dst.SetString(toString(src))
+		return nil
+	}
+
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	// []byte destination from a string column
+	if dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8 {
+		if s, ok := src.(string); ok {
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+	}
+
+	// JSON stored in a text column decodes into structs, maps and slices
+	switch dst.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		var raw []byte
+		switch v := src.(type) {
+		case string:
+			raw = []byte(v)
+		case []byte:
+			raw = v
+		}
+		if raw != nil {
+			return json.Unmarshal(raw, dst.Addr().Interface())
+		}
+	}
+
+	if sv.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(sv.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot convert %T to %s", src, dst.Type())
+}
+
+// JSONNumberValue turns a json.Number into int64 when possible, otherwise float64
+// (the string itself when it is neither). Backends decoding JSON with UseNumber use it
+// so integers keep their precision.
+func JSONNumberValue(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+func toInt64(src interface{}) (int64, error) {
+	switch v := src.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows int64", v)
+		}
+		return int64(v), nil
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		// rqlite returns every JSON number as float64
+		return floatToInt64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return floatToInt64(f)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	case []byte:
+		return strconv.ParseInt(strings.TrimSpace(string(v)), 10, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to integer", src)
+}
+
+func floatToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("value %v is not an integer", f)
+	}
+	// math.MaxInt64 rounds up to 1<<63 as a float64, which is already out of range
+	if f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, fmt.Errorf("value %v overflows int64", f)
+	}
+	return int64(f), nil
+}
+
+// toUint64 is toInt64 for unsigned destinations, it keeps the values above math.MaxInt64
+// (PostgreSQL returns NUMERIC(20) columns as text)
+func toUint64(src interface{}) (uint64, error) {
+	switch v := src.(type) {
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case float32:
+		return floatToUint64(float64(v))
+	case float64:
+		return floatToUint64(v)
+	case json.Number:
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return floatToUint64(f)
+	case string:
+		return strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+	case []byte:
+		return strconv.ParseUint(strings.TrimSpace(string(v)), 10, 64)
+	}
+	i, err := toInt64(src)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("value %d overflows uint64", i)
+	}
+	return uint64(i), nil
+}
+
+func floatToUint64(f float64) (uint64, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("value %v is not an integer", f)
+	}
+	// math.MaxUint64 rounds up to 1<<64 as a float64, which is already out of range
+	if f >= math.MaxUint64 || f < 0 {
+		return 0, fmt.Errorf("value %v overflows uint64", f)
+	}
+	return uint64(f), nil
+}
+
+func toFloat64(src interface{}) (float64, error) {
+	switch v := src.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case []byte:
+		return strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+	}
+	i, err := toInt64(src)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %T to float", src)
+	}
+	return float64(i), nil
+}
+
+func toBool(src interface{}) (bool, error) {
+	switch v := src.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	case []byte:
+		return strconv.ParseBool(strings.TrimSpace(string(v)))
+	}
+	// SQLite stores booleans as 0/1
+	i, err := toInt64(src)
+	if err != nil {
+		return false, fmt.Errorf("cannot convert %T to bool", src)
+	}
+	return i != 0, nil
+}
+
+func toString(src interface{}) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", src)
+}
+
+func toTime(src interface{}) (time.Time, error) {
+	switch v := src.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		return *v, nil
+	case string:
+		return parseTimeString(v)
+	case []byte:
+		return parseTimeString(string(v))
+	}
+	// Numbers are treated as unix seconds (SQLite unixepoch())
+	i, err := toInt64(src)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot convert %T to time.Time", src)
+	}
+	return time.Unix(i, 0).UTC(), nil
+}
+
+// parseTimeString parses the timestamp formats produced by SQLite and PostgreSQL
+func parseTimeString(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse time: %q", s)
+}
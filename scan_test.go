@@ -0,0 +1,206 @@
+package orm
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ScanAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type scanItem struct {
+	ID        int64             `db:"id,pk"`
+	Name      string            `db:"name"`
+	Price     float64           `db:"price"`
+	Active    bool              `db:"active"`
+	Tags      []string          `db:"tags"`
+	Meta      map[string]string `db:"meta"`
+	DeletedAt *time.Time        `db:"deleted_at"`
+	Note      sql.NullString    `db:"note"`
+	Internal  string            `db:"-"`
+	*ScanAudit
+}
+
+func (i *scanItem) TableName() string { return "items" }
+
+// TestAssignValue tests the conversion of backend values into struct fields
+func TestAssignValue(t *testing.T) {
+	name := "ana"
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    interface{} // also gives the destination type
+		wantErr bool
+	}{
+		{"float64 to int", float64(42), int(42), false},
+		{"json.Number to int64 keeps precision", json.Number("9007199254740993"), int64(9007199254740993), false},
+		{"string to int64", " 12 ", int64(12), false},
+		{"bool to int64", true, int64(1), false},
+		{"fractional float to int64", 1.5, int64(0), true},
+		{"float64 of 1<<63 overflows int64", math.Pow(2, 63), int64(0), true},
+		{"largest float64 below 1<<63", float64(1<<63 - 1024), int64(1<<63 - 1024), false},
+		{"float64 of -1<<63", math.Pow(2, 63) * -1, int64(math.MinInt64), false},
+		{"int64 overflows int8", int64(300), int8(0), true},
+		{"negative to uint", int64(-1), uint(0), true},
+		{"uint64 overflows int64", uint64(math.MaxUint64), int64(0), true},
+		{"numeric text above MaxInt64 to uint64", "18446744073709551615", uint64(math.MaxUint64), false},
+		{"bytes above MaxInt64 to uint64", []byte("9223372036854775808"), uint64(1 << 63), false},
+		{"json.Number above MaxInt64 to uint64", json.Number("18446744073709551615"), uint64(math.MaxUint64), false},
+		{"float64 above MaxInt64 to uint64", math.Pow(2, 63), uint64(1 << 63), false},
+		{"float64 of 1<<64 overflows uint64", math.Pow(2, 64), uint64(0), true},
+		{"negative text to uint64", "-1", uint64(0), true},
+		{"uint64 overflows uint32", uint64(1 << 32), uint32(0), true},
+		{"json.Number to float64", json.Number("2.5"), 2.5, false},
+		{"sqlite integer to bool", float64(1), true, false},
+		{"string to bool", "false", false, false},
+		{"bytes to string", []byte("abc"), "abc", false},
+		{"float64 to string", 1.5, "1.5", false},
+		{"sqlite datetime to time", "2024-01-02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"unix seconds to time", float64(1700000000), time.Unix(1700000000, 0).UTC(), false},
+		{"bad time", "yesterday", time.Time{}, true},
+		{"value to pointer", "ana", &name, false},
+		{"null to pointer", nil, (*string)(nil), false},
+		{"json.Number into a Scanner", json.Number("7"), sql.NullInt64{Int64: 7, Valid: true}, false},
+		{"json text to slice", `["a","b"]`, []string{"a", "b"}, false},
+		{"json text to map", `{"k":"v"}`, map[string]string{"k": "v"}, false},
+		{"string to bytes", "raw", []byte("raw"), false},
+		{"unconvertible", struct{}{}, int64(0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := reflect.New(reflect.TypeOf(tt.want)).Elem()
+			err := assignValue(dst, tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", dst.Interface())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst.Interface(), tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, dst.Interface())
+			}
+		})
+	}
+}
+
+// TestScanRecords tests mapping records into structs by their db tags
+func TestScanRecords(t *testing.T) {
+	records := []DBRecord{
+		{TableName: "items", Data: map[string]interface{}{
+			"id": float64(1), "name": "pen", "price": json.Number("1.25"), "active": int64(1),
+			"tags": `["blue"]`, "meta": []byte(`{"size":"s"}`), "deleted_at": nil,
+			"note": "fragile", "created_by": "ana", "unknown": "ignored",
+		}},
+		{TableName: "items", Data: map[string]interface{}{
+			"id": int64(2), "name": "ink", "deleted_at": "2024-01-02T03:04:05Z", "note": nil,
+		}},
+	}
+
+	items, err := ScanRecords[scanItem](records)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []scanItem{
+		{ID: 1, Name: "pen", Price: 1.25, Active: true, Tags: []string{"blue"}, Meta: map[string]string{"size": "s"},
+			Note: sql.NullString{String: "fragile", Valid: true}, ScanAudit: &ScanAudit{CreatedBy: "ana"}},
+		{ID: 2, Name: "ink", DeletedAt: &deletedAt},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("Expected %+v, got %+v", want, items)
+	}
+
+	records = append(records, DBRecord{Data: map[string]interface{}{"id": "not a number"}})
+	_, err = ScanRecords[scanItem](records)
+	if err == nil || !strings.Contains(err.Error(), "record 2: column id into field ID") {
+		t.Errorf("Expected the failing record and column in the error, got %v", err)
+	}
+
+	if err := ScanRecord(records[0], scanItem{}); err == nil {
+		t.Errorf("Expected an error for a non-pointer destination")
+	}
+}
+
+type ScanBase struct {
+	ID        int64  `db:"id"`
+	CreatedBy string `db:"created_by"`
+}
+
+// TestScanRecordShadowedField tests an outer field wins over the promoted field of the same column
+func TestScanRecordShadowedField(t *testing.T) {
+	type shadowItem struct {
+		ScanBase
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	var item shadowItem
+	err := ScanRecord(DBRecord{Data: map[string]interface{}{"id": int64(7), "created_by": "ana", "name": "pen"}}, &item)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := shadowItem{ScanBase: ScanBase{CreatedBy: "ana"}, ID: 7, Name: "pen"}
+	if item != want {
+		t.Errorf("Expected %+v, got %+v", want, item)
+	}
+
+	var columns []string
+	for _, field := range getStructInfo(reflect.TypeOf(item)).Fields {
+		columns = append(columns, field.Column+"="+field.Name)
+	}
+	if want := []string{"created_by=CreatedBy", "id=ID", "name=Name"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("Expected %v, got %v", want, columns)
+	}
+}
+
+// TestSelectInto tests the typed select helpers on top of the Database select methods
+func TestSelectInto(t *testing.T) {
+	condition := &Condition{Field: "active", Operator: "=", Value: true}
+	db := &stubDB{rows: []DBRecord{
+		{TableName: "items", Data: map[string]interface{}{"id": float64(1), "name": "pen"}},
+		{TableName: "items", Data: map[string]interface{}{"id": float64(2), "name": "ink"}},
+	}}
+
+	items, err := SelectInto[scanItem](db, condition)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Name != "pen" || items[1].ID != 2 {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+	if db.condition != condition {
+		t.Errorf("Expected the condition to be passed through, got %+v", db.condition)
+	}
+
+	item, err := SelectOneInto[scanItem](db, condition)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if item.ID != 1 || item.Name != "pen" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+
+	db.rows = nil
+	if _, err := SelectInto[scanItem](db, condition); !errors.Is(err, ErrSQLNoRows) {
+		t.Errorf("Expected %v, got %v", ErrSQLNoRows, err)
+	}
+	if _, err := SelectOneInto[scanItem](db, condition); !errors.Is(err, ErrSQLNoRows) {
+		t.Errorf("Expected %v, got %v", ErrSQLNoRows, err)
+	}
+
+	db.rows = []DBRecord{{Data: map[string]interface{}{"price": "free"}}}
+	if _, err := SelectInto[scanItem](db, nil); err == nil {
+		t.Errorf("Expected a conversion error")
+	}
+}
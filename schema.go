@@ -0,0 +1,90 @@
+package orm
+
+import (
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrTableNotFound medaerror.MedaError = medaerror.MedaError{Message: "table not found"}
+)
+
+// Schema introspection shared by all backends, see Database.DescribeTable and Database.ListTables.
+// Types are reported as the database knows them (SQLite: declared type such as INTEGER or
+// VARCHAR(255); PostgreSQL: format_type such as integer or character varying(255)), and
+// referential actions with their SQL spelling (NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT).
+
+// TableInfo describes a table: its columns in declaration order and its constraints
+type TableInfo struct {
+	Name              string             `json:"name"`
+	Columns           []ColumnInfo       `json:"columns"`
+	PrimaryKey        []string           `json:"primary_key,omitempty"`        // Primary key columns, in key order
+	Indexes           []IndexInfo        `json:"indexes,omitempty"`            // Indexes created with CREATE INDEX (not the ones backing constraints)
+	ForeignKeys       []ForeignKeyInfo   `json:"foreign_keys,omitempty"`       // FOREIGN KEY constraints
+	UniqueConstraints []UniqueConstraint `json:"unique_constraints,omitempty"` // UNIQUE constraints
+	Checks            []CheckConstraint  `json:"checks,omitempty"`             // CHECK constraints
+}
+
+// ColumnInfo describes a table column
+type ColumnInfo struct {
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Nullable   bool    `json:"nullable"`
+	Default    *string `json:"default,omitempty"` // Default expression as SQL text, nil without default
+	PrimaryKey bool    `json:"primary_key,omitempty"`
+}
+
+// IndexInfo describes an index
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"` // Indexed columns, expressions are left out
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// ForeignKeyInfo describes a FOREIGN KEY constraint
+type ForeignKeyInfo struct {
+	Name       string   `json:"name,omitempty"` // Empty on SQLite, which does not report constraint names
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns"` // Empty when the reference implies the primary key (SQLite)
+	OnUpdate   string   `json:"on_update,omitempty"`
+	OnDelete   string   `json:"on_delete,omitempty"`
+}
+
+// UniqueConstraint describes a UNIQUE constraint
+type UniqueConstraint struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// CheckConstraint describes a CHECK constraint
+type CheckConstraint struct {
+	Name       string `json:"name,omitempty"`
+	Expression string `json:"expression"` // Expression inside CHECK (...)
+}
+
+// Column returns the column with the given name (case-insensitive)
+func (t TableInfo) Column(name string) (ColumnInfo, bool) {
+	for _, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// SplitTableName splits a validated table name into its schema (empty if not qualified)
+// and table parts, removing the double quotes of quoted parts.
+//
+//	orm.SplitTableName(`public."Order Items"`) // "public", "Order Items"
+func SplitTableName(tableName string) (schema, table string) {
+	parts := splitIdentifier(tableName)
+	for i, part := range parts {
+		parts[i] = strings.Trim(part, `"`)
+	}
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1]
+}
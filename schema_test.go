@@ -0,0 +1,36 @@
+package orm
+
+import "testing"
+
+// TestSplitTableName checks schema and quote handling
+func TestSplitTableName(t *testing.T) {
+	tests := []struct {
+		name, wantSchema, wantTable string
+	}{
+		{"users", "", "users"},
+		{"public.users", "public", "users"},
+		{`"Order Items"`, "", "Order Items"},
+		{`app."Order.Items"`, "app", "Order.Items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, table := SplitTableName(tt.name)
+			if schema != tt.wantSchema || table != tt.wantTable {
+				t.Errorf("Expected %q, %q, got %q, %q", tt.wantSchema, tt.wantTable, schema, table)
+			}
+		})
+	}
+}
+
+// TestTableInfoColumn checks the case-insensitive column lookup
+func TestTableInfoColumn(t *testing.T) {
+	info := TableInfo{Columns: []ColumnInfo{{Name: "id", Type: "INTEGER"}, {Name: "Email", Type: "TEXT", Nullable: true}}}
+
+	if column, ok := info.Column("email"); !ok || column.Type != "TEXT" {
+		t.Errorf("Expected the Email column, got %+v, %v", column, ok)
+	}
+	if _, ok := info.Column("missing"); ok {
+		t.Error("Expected no column")
+	}
+}
@@ -0,0 +1,351 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrUnterminatedSQL medaerror.MedaError = medaerror.MedaError{Message: "unterminated string, quoted identifier, comment or dollar-quoted body in SQL script"}
+)
+
+// Statement is one statement of a SQL script
+type Statement struct {
+	SQL  string `json:"sql"`  // Statement text as written, without the terminating semicolon
+	Line int    `json:"line"` // 1-based line of the script where the statement starts
+}
+
+// ScriptError reports the statement of a script that failed in ExecScript
+type ScriptError struct {
+	Index     int       // 0-based position of the statement in the script
+	Statement Statement // The failing statement
+	Err       error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("statement %d on line %d failed: %v", e.Index+1, e.Statement.Line, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// SplitSQL splits a SQL script into statements on the semicolons that end them.
+// It tokenizes the script, so semicolons and -- inside string literals, quoted
+// identifiers and comments do not split. Neither do the semicolons in the
+// BEGIN ... END body of CREATE TRIGGER (SQLite) and of CREATE FUNCTION / PROCEDURE
+// ... BEGIN ATOMIC (PostgreSQL).
+//
+// The dialect selects the quoting rules. PostgreSQL adds dollar-quoted bodies ($$ ... $$,
+// $tag$ ... $tag$), E'...' strings with backslash escapes and nested block comments.
+// SQLite adds `identifiers` and [identifiers]. A nil dialect accepts both sets, except
+// the [ ] quoting, which would clash with PostgreSQL arrays.
+//
+// Statements keep their text, including comments inside them, and comment-only parts
+// are dropped. On an unterminated quote or comment, the statements are returned with
+// the rest of the script as the last one, together with ErrUnterminatedSQL.
+//
+// Usage:
+//
+//	statements, err := orm.SplitSQL(db.Dialect(), script)
+//	for _, s := range statements {
+//	    fmt.Printf("line %d: %s\n", s.Line, s.SQL)
+//	}
+func SplitSQL(d Dialect, script string) ([]Statement, error) {
+	s := &sqlScanner{src: script, line: 1}
+	if d != nil {
+		s.postgres = d.Name() == (PostgreSQLDialect{}).Name()
+		s.sqlite = !s.postgres
+	}
+	return s.split()
+}
+
+// ExecScript reads a SQL script and executes its statements one at a time, stopping at
+// the first failure, which is returned as a *ScriptError with the statement and its line.
+// Statements run before the failure stay applied. For an all-or-nothing run, pass the
+// statements from SplitSQL to ExecManySQL.
+//
+// Usage:
+//
+//	file, _ := os.Open("schema.sql")
+//	results, err := orm.ExecScript(db, file)
+//	var scriptErr *orm.ScriptError
+//	if errors.As(err, &scriptErr) {
+//	    log.Printf("line %d: %s", scriptErr.Statement.Line, scriptErr.Statement.SQL)
+//	}
+func ExecScript(db Database, r io.Reader) ([]BasicSQLResult, error) {
+	return ExecScriptContext(context.Background(), db, r)
+}
+
+// ExecScriptContext is the context-aware variant of ExecScript
+func ExecScriptContext(ctx context.Context, db Database, r io.Reader) ([]BasicSQLResult, error) {
+	script, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SQL script: %w", err)
+	}
+	statements, err := SplitSQL(db.Dialect(), string(script))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BasicSQLResult, 0, len(statements))
+	for i, statement := range statements {
+		var result BasicSQLResult
+		if dbc, ok := db.(DatabaseContext); ok {
+			result = dbc.ExecOneSQLContext(ctx, statement.SQL)
+		} else {
+			result = db.ExecOneSQL(statement.SQL)
+		}
+		results = append(results, result)
+		if result.Error != nil {
+			return results, &ScriptError{Index: i, Statement: statement, Err: result.Error}
+		}
+	}
+	return results, nil
+}
+
+// sqlScanner walks a SQL script, tracking the line and the BEGIN ... END depth
+type sqlScanner struct {
+	src      string
+	pos      int
+	line     int
+	postgres bool // dollar quotes, E'...' strings, nested comments
+	sqlite   bool // `` and [] identifiers
+
+	statements []Statement
+	start      int  // offset of the current statement, -1 before its first token
+	startLine  int  // line of the current statement
+	words      int  // number of keywords seen in the current statement
+	create     bool // the statement starts with CREATE
+	body       bool // CREATE TRIGGER, FUNCTION or PROCEDURE: BEGIN opens a body
+	depth      int  // BEGIN / CASE nesting inside the body
+}
+
+// split scans the whole script
+func (s *sqlScanner) split() ([]Statement, error) {
+	s.reset()
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '\n':
+			s.line++
+			s.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			s.pos++
+		case c == '-' && s.peek(1) == '-':
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		case c == '/' && s.peek(1) == '*':
+			start, line := s.pos, s.line
+			if err := s.blockComment(); err != nil {
+				if s.start < 0 {
+					s.start, s.startLine = start, line
+				}
+				return s.finish(err)
+			}
+		case c == ';':
+			if s.depth > 0 {
+				s.pos++
+				continue
+			}
+			s.emit(s.pos)
+			s.pos++
+			s.reset()
+		default:
+			s.begin()
+			if err := s.token(); err != nil {
+				return s.finish(err)
+			}
+		}
+	}
+	return s.finish(nil)
+}
+
+// token consumes a quoted part, a word or a single character of a statement
+func (s *sqlScanner) token() error {
+	c := s.src[s.pos]
+	switch {
+	case c == '\'':
+		return s.quoted('\'', false)
+	case c == '"':
+		return s.quoted('"', false)
+	case c == '`' && !s.postgres:
+		return s.quoted('`', false)
+	case c == '[' && s.sqlite:
+		return s.quoted(']', false)
+	case c == '$' && !s.sqlite:
+		if tag, ok := s.dollarTag(); ok {
+			return s.dollarQuoted(tag)
+		}
+		s.pos++
+	case isWordStart(c):
+		start := s.pos
+		for s.pos < len(s.src) && (isWordStart(s.src[s.pos]) || s.src[s.pos] >= '0' && s.src[s.pos] <= '9' || s.src[s.pos] == '$') {
+			s.pos++
+		}
+		word := strings.ToUpper(s.src[start:s.pos])
+		if word == "E" && !s.sqlite && s.peek(0) == '\'' {
+			return s.quoted('\'', true)
+		}
+		s.keyword(word)
+	default:
+		s.pos++
+	}
+	return nil
+}
+
+// keyword tracks the BEGIN ... END body of triggers and BEGIN ATOMIC functions.
+// CASE ... END expressions inside the body are nested so their END does not close it.
+func (s *sqlScanner) keyword(word string) {
+	s.words++
+	switch word {
+	case "CREATE":
+		s.create = s.create || s.words == 1
+	case "TRIGGER", "FUNCTION", "PROCEDURE":
+		s.body = s.body || s.create
+	case "BEGIN":
+		if s.body || s.depth > 0 {
+			s.depth++
+		}
+	case "CASE":
+		if s.depth > 0 {
+			s.depth++
+		}
+	case "END":
+		if s.depth > 0 {
+			s.depth--
+		}
+	}
+}
+
+// quoted consumes a literal or identifier up to the closing quote (doubled quotes
+// are escapes), with backslash escapes for PostgreSQL E'...' strings
+func (s *sqlScanner) quoted(closing byte, backslash bool) error {
+	line := s.line
+	s.pos++
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '\n':
+			s.line++
+		case c == '\\' && backslash:
+			s.pos++
+			if s.pos < len(s.src) && s.src[s.pos] == '\n' {
+				s.line++
+			}
+		case c == closing:
+			if s.peek(1) == closing && closing != ']' {
+				s.pos++
+			} else {
+				s.pos++
+				return nil
+			}
+		}
+		s.pos++
+	}
+	return fmt.Errorf("%w: quote %c opened on line %d", ErrUnterminatedSQL, closing, line)
+}
+
+// blockComment consumes /* ... */, nested on PostgreSQL
+func (s *sqlScanner) blockComment() error {
+	line := s.line
+	depth := 0
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '/' && s.peek(1) == '*':
+			if depth == 0 || s.postgres {
+				depth++
+			}
+			s.pos += 2
+			continue
+		case s.src[s.pos] == '*' && s.peek(1) == '/':
+			depth--
+			s.pos += 2
+			if depth == 0 {
+				return nil
+			}
+			continue
+		case s.src[s.pos] == '\n':
+			s.line++
+		}
+		s.pos++
+	}
+	return fmt.Errorf("%w: comment opened on line %d", ErrUnterminatedSQL, line)
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted body at the current position
+func (s *sqlScanner) dollarTag() (string, bool) {
+	if s.pos > 0 && (isWordStart(s.src[s.pos-1]) || s.src[s.pos-1] >= '0' && s.src[s.pos-1] <= '9') {
+		return "", false
+	}
+	end := s.pos + 1
+	for end < len(s.src) && (isWordStart(s.src[end]) || end > s.pos+1 && s.src[end] >= '0' && s.src[end] <= '9') {
+		end++
+	}
+	if end < len(s.src) && s.src[end] == '$' {
+		return s.src[s.pos : end+1], true
+	}
+	return "", false
+}
+
+// dollarQuoted consumes a $tag$ ... $tag$ body
+func (s *sqlScanner) dollarQuoted(tag string) error {
+	line := s.line
+	s.pos += len(tag)
+	end := strings.Index(s.src[s.pos:], tag)
+	if end < 0 {
+		s.line += strings.Count(s.src[s.pos:], "\n")
+		s.pos = len(s.src)
+		return fmt.Errorf("%w: %s opened on line %d", ErrUnterminatedSQL, tag, line)
+	}
+	s.line += strings.Count(s.src[s.pos:s.pos+end], "\n")
+	s.pos += end + len(tag)
+	return nil
+}
+
+// begin marks the start of a statement at its first token
+func (s *sqlScanner) begin() {
+	if s.start < 0 {
+		s.start = s.pos
+		s.startLine = s.line
+	}
+}
+
+// emit adds the current statement, ending before offset end
+func (s *sqlScanner) emit(end int) {
+	if s.start < 0 {
+		return
+	}
+	if sql := strings.TrimSpace(s.src[s.start:end]); sql != "" {
+		s.statements = append(s.statements, Statement{SQL: sql, Line: s.startLine})
+	}
+}
+
+// reset prepares for the next statement
+func (s *sqlScanner) reset() {
+	s.start, s.words, s.create, s.body, s.depth = -1, 0, false, false, 0
+}
+
+// finish adds the last statement, which needs no semicolon
+func (s *sqlScanner) finish(err error) ([]Statement, error) {
+	s.emit(len(s.src))
+	return s.statements, err
+}
+
+// peek returns the byte at offset from the current position, 0 past the end
+func (s *sqlScanner) peek(offset int) byte {
+	if s.pos+offset < len(s.src) {
+		return s.src[s.pos+offset]
+	}
+	return 0
+}
+
+// isWordStart reports whether c can start a keyword or bare identifier
+func isWordStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
@@ -0,0 +1,148 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSplitSQL checks statements are only split on the semicolons that end them
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		script  string
+		want    []Statement
+	}{
+		{
+			name:    "strings and comments",
+			dialect: SQLiteDialect{},
+			script: "-- create the table; really\n" +
+				"CREATE TABLE notes (body TEXT DEFAULT 'a;b -- c');\n" +
+				"/* insert; two */ INSERT INTO notes VALUES ('it''s; fine'); -- done\n" +
+				"SELECT \"semi;colon\" FROM notes",
+			want: []Statement{
+				{SQL: "CREATE TABLE notes (body TEXT DEFAULT 'a;b -- c')", Line: 2},
+				{SQL: "INSERT INTO notes VALUES ('it''s; fine')", Line: 3},
+				{SQL: "SELECT \"semi;colon\" FROM notes", Line: 4},
+			},
+		},
+		{
+			name:    "sqlite trigger with case",
+			dialect: SQLiteDialect{},
+			script: "CREATE TRIGGER audit AFTER UPDATE ON [order;s]\n" +
+				"BEGIN\n" +
+				"  INSERT INTO log VALUES (CASE WHEN new.total > 0 THEN 'up' ELSE 'down' END);\n" +
+				"  UPDATE stats SET n = n + 1;\n" +
+				"END;\n" +
+				"DROP TABLE old;",
+			want: []Statement{
+				{SQL: "CREATE TRIGGER audit AFTER UPDATE ON [order;s]\nBEGIN\n" +
+					"  INSERT INTO log VALUES (CASE WHEN new.total > 0 THEN 'up' ELSE 'down' END);\n" +
+					"  UPDATE stats SET n = n + 1;\nEND", Line: 1},
+				{SQL: "DROP TABLE old", Line: 6},
+			},
+		},
+		{
+			name:    "postgres dollar quotes",
+			dialect: PostgreSQLDialect{},
+			script: "CREATE FUNCTION touch() RETURNS trigger AS $$\n" +
+				"BEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n" +
+				"DO $body$ BEGIN PERFORM 1; END $body$;\n" +
+				"SELECT E'it\\'s; escaped', $1::int",
+			want: []Statement{
+				{SQL: "CREATE FUNCTION touch() RETURNS trigger AS $$\n" +
+					"BEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql", Line: 1},
+				{SQL: "DO $body$ BEGIN PERFORM 1; END $body$", Line: 7},
+				{SQL: "SELECT E'it\\'s; escaped', $1::int", Line: 8},
+			},
+		},
+		{
+			name:    "postgres begin atomic",
+			dialect: PostgreSQLDialect{},
+			script: "CREATE PROCEDURE reset() LANGUAGE SQL\nBEGIN ATOMIC\n  DELETE FROM a;\n  DELETE FROM b;\nEND;\n" +
+				"/* outer /* nested; */ still comment; */ VACUUM;",
+			want: []Statement{
+				{SQL: "CREATE PROCEDURE reset() LANGUAGE SQL\nBEGIN ATOMIC\n  DELETE FROM a;\n  DELETE FROM b;\nEND", Line: 1},
+				{SQL: "VACUUM", Line: 6},
+			},
+		},
+		{
+			name:    "transaction statements",
+			dialect: nil,
+			script:  "BEGIN;\nUPDATE a SET x = 1;\nCOMMIT;\n;\n",
+			want: []Statement{
+				{SQL: "BEGIN", Line: 1},
+				{SQL: "UPDATE a SET x = 1", Line: 2},
+				{SQL: "COMMIT", Line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitSQL(tt.dialect, tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestSplitSQLUnterminated checks unterminated constructs are reported
+func TestSplitSQLUnterminated(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		script  string
+	}{
+		{"string", SQLiteDialect{}, "SELECT 1;\nSELECT 'open;"},
+		{"identifier", SQLiteDialect{}, "SELECT 1;\nSELECT \"open;"},
+		{"comment", SQLiteDialect{}, "SELECT 1;\n/* open;"},
+		{"dollar quote", PostgreSQLDialect{}, "SELECT 1;\nDO $$ BEGIN;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitSQL(tt.dialect, tt.script)
+			if !errors.Is(err, ErrUnterminatedSQL) {
+				t.Fatalf("Expected %v, got %v", ErrUnterminatedSQL, err)
+			}
+			if len(got) != 2 || got[0].SQL != "SELECT 1" || got[1].Line != 2 {
+				t.Errorf("Expected the rest of the script as the last statement, got %q", got)
+			}
+		})
+	}
+}
+
+// TestExecScript checks statements run in order and the failing one is reported with its line
+func TestExecScript(t *testing.T) {
+	db := &stubDB{failOn: "missing"}
+	script := "CREATE TABLE a (x TEXT DEFAULT ';');\n\n" +
+		"INSERT INTO missing VALUES (1);\n" +
+		"DROP TABLE a;"
+
+	results, err := ExecScript(db, strings.NewReader(script))
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("Expected a *ScriptError, got %v", err)
+	}
+	if scriptErr.Index != 1 || scriptErr.Statement.Line != 3 || scriptErr.Statement.SQL != "INSERT INTO missing VALUES (1)" {
+		t.Errorf("Unexpected failing statement: %+v", scriptErr)
+	}
+	if len(results) != 2 || len(db.execs) != 2 {
+		t.Errorf("Expected execution to stop at the failure, got %q", db.execs)
+	}
+
+	db = &stubDB{}
+	if _, err := ExecScript(db, strings.NewReader(script)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(db.execs) != 3 {
+		t.Errorf("Expected 3 statements executed, got %q", db.execs)
+	}
+}
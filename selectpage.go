@@ -0,0 +1,200 @@
+package orm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// selectAliasRegex matches the trailing " AS alias" of a select entry
+var selectAliasRegex = regexp.MustCompile(`(?i)\s+AS\s+\S+$`)
+
+// PageOptions tunes SelectPage and SelectPageComplex
+type PageOptions struct {
+	// Consistent runs the page select and the count as one unit, so Total matches the
+	// rows even under concurrent writes: a single transactional rqlite request, or a
+	// read-only REPEATABLE READ transaction on PostgreSQL. Without it they are two
+	// independent statements.
+	Consistent bool `json:"consistent,omitempty"`
+}
+
+// PageQuery holds the two statements of an offset page: the rows of the page and
+// the count of every matching row. Backends run them and call Page with the results.
+type PageQuery struct {
+	Select ParametereizedSQL
+	Count  ParametereizedSQL
+	Limit  int
+	Offset int
+}
+
+// Page builds the Page from the selected rows and the single row of the count statement
+func (q PageQuery) Page(records []DBRecord, count []DBRecord) (Page, error) {
+	if len(count) != 1 {
+		return Page{}, fmt.Errorf("count query returned %d rows, expected 1", len(count))
+	}
+	total, err := toInt64(count[0].Data["total"])
+	if err != nil {
+		return Page{}, fmt.Errorf("invalid count result: %w", err)
+	}
+	return Page{
+		Records: records,
+		Total:   &total,
+		Limit:   q.Limit,
+		Offset:  q.Offset,
+		HasMore: int64(q.Offset+len(records)) < total,
+		HasPrev: q.Offset > 0,
+	}, nil
+}
+
+// ToCountString builds SELECT COUNT(*) AS total for the rows matching the condition.
+// OrderBy, Limit and Offset are ignored, GroupBy counts the groups.
+//
+//	query, values, err := condition.ToCountString("users")
+//	// SELECT COUNT(*) AS total FROM users WHERE age > ?
+func (c *Condition) ToCountString(tableName string) (string, []interface{}, error) {
+	return c.ToCountStringDialect(defaultDialect, tableName)
+}
+
+// ToCountStringDialect is ToCountString rendered for the given dialect
+func (c *Condition) ToCountStringDialect(d Dialect, tableName string) (string, []interface{}, error) {
+	if err := ValidateTableName(tableName); err != nil {
+		return "", nil, err
+	}
+
+	b := newSQLBuilder(d)
+	b.allowTable(tableName, "")
+	from := "FROM " + b.ident(tableName)
+	if c == nil {
+		return "SELECT COUNT(*) AS total " + from, b.args, nil
+	}
+	whereClause, err := c.writeWhere(b)
+	if err != nil {
+		return "", nil, err
+	}
+	if whereClause != "" {
+		from += " WHERE " + whereClause
+	}
+	groupFields, err := b.writeColumns(c.GroupBy)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(groupFields) > 0 {
+		return "SELECT COUNT(*) AS total FROM (SELECT 1 " + from + " GROUP BY " + strings.Join(groupFields, ", ") + ") AS _count", b.args, nil
+	}
+	return "SELECT COUNT(*) AS total " + from, b.args, nil
+}
+
+// ToPageQueryDialect builds the statements of SelectPage for the condition.
+// Limit defaults to DEFAULT_PAGINATION_LIMIT, a nil condition pages the whole table.
+func (c *Condition) ToPageQueryDialect(d Dialect, tableName string) (PageQuery, error) {
+	var paged Condition
+	if c != nil {
+		paged = *c
+	}
+	if paged.Limit < 1 {
+		paged.Limit = DEFAULT_PAGINATION_LIMIT
+	}
+	selectSQL, selectValues, err := paged.ToSelectStringDialect(d, tableName)
+	if err != nil {
+		return PageQuery{}, err
+	}
+	countSQL, countValues, err := paged.ToCountStringDialect(d, tableName)
+	if err != nil {
+		return PageQuery{}, err
+	}
+	return PageQuery{
+		Select: SQLAndValuesToParameterized(selectSQL, selectValues),
+		Count:  SQLAndValuesToParameterized(countSQL, countValues),
+		Limit:  paged.Limit,
+		Offset: paged.Offset,
+	}, nil
+}
+
+// ToCountSQL builds SELECT COUNT(*) AS total for the rows the query returns without
+// its ORDER BY, LIMIT and OFFSET. The FROM, JOINs and WHERE are reused as they are;
+// grouped, DISTINCT, compound and aggregate queries are counted through a derived table.
+func (cq *ComplexQuery) ToCountSQL() (string, []interface{}, error) {
+	return cq.ToCountSQLDialect(defaultDialect)
+}
+
+// ToCountSQLDialect is ToCountSQL rendered for the given dialect
+func (cq *ComplexQuery) ToCountSQLDialect(d Dialect) (string, []interface{}, error) {
+	inner := *cq
+	inner.OrderBy, inner.OrderTerms, inner.OrderByExprs = nil, nil, nil
+	inner.Limit, inner.Offset = 0, 0
+	if cq.Compound != nil {
+		compound := *cq.Compound
+		compound.OrderBy, compound.OrderTerms = nil, nil
+		compound.Limit, compound.Offset = 0, 0
+		inner.Compound = &compound
+	}
+
+	grouped := cq.Compound != nil || cq.Distinct || len(cq.GroupBy) > 0 || len(cq.GroupByColumns) > 0 ||
+		cq.Having != "" || cq.HavingCondition != nil || cq.HavingExpr != nil || cq.selectsAggregate()
+	if !grouped {
+		// one result row per joined row: count them directly, the select list does not matter
+		inner.Select = []string{"COUNT(*) AS total"}
+		inner.SelectTerms, inner.SelectExprs, inner.WindowTerms, inner.Windows = nil, nil, nil, nil
+		return inner.ToSQLDialect(d)
+	}
+
+	b := newSQLBuilder(d)
+	sql, err := inner.writeSQL(b, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return "SELECT COUNT(*) AS total FROM (" + sql + ") AS _count", b.args, nil
+}
+
+// selectsAggregate reports whether the select list has an aggregate such as COUNT(*) or
+// SUM(orders.total) AS total: without GROUP BY such a query returns a single row, not
+// one row per joined row
+func (cq *ComplexQuery) selectsAggregate() bool {
+	entries := make([]string, 0, len(cq.Select)+len(cq.SelectTerms)+len(cq.SelectExprs))
+	entries = append(entries, cq.Select...)
+	for _, term := range cq.SelectTerms {
+		entries = append(entries, term.Expr)
+	}
+	for _, expr := range cq.SelectExprs {
+		if expr != nil {
+			entries = append(entries, expr.SQL)
+		}
+	}
+	for _, entry := range entries {
+		agg, ok := parseAggregate(selectAliasRegex.ReplaceAllString(strings.TrimSpace(entry), ""))
+		if ok && allowedAggregates[agg.Function] {
+			return true
+		}
+	}
+	return false
+}
+
+// ToPageQueryDialect builds the statements of SelectPageComplex for the query.
+// Limit (Compound.Limit for a compound query) defaults to DEFAULT_PAGINATION_LIMIT.
+func (cq *ComplexQuery) ToPageQueryDialect(d Dialect) (PageQuery, error) {
+	paged := *cq
+	limit, offset := &paged.Limit, paged.Offset
+	if cq.Compound != nil {
+		compound := *cq.Compound
+		paged.Compound = &compound
+		limit, offset = &compound.Limit, compound.Offset
+	}
+	if *limit < 1 {
+		*limit = DEFAULT_PAGINATION_LIMIT
+	}
+
+	selectSQL, selectValues, err := paged.ToSQLDialect(d)
+	if err != nil {
+		return PageQuery{}, err
+	}
+	countSQL, countValues, err := paged.ToCountSQLDialect(d)
+	if err != nil {
+		return PageQuery{}, err
+	}
+	return PageQuery{
+		Select: SQLAndValuesToParameterized(selectSQL, selectValues),
+		Count:  SQLAndValuesToParameterized(countSQL, countValues),
+		Limit:  *limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package orm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestConditionToCountString checks the count statement derived from a condition
+func TestConditionToCountString(t *testing.T) {
+	tests := []struct {
+		name       string
+		condition  *Condition
+		want       string
+		wantValues []interface{}
+	}{
+		{
+			name:      "nil condition",
+			condition: nil,
+			want:      "SELECT COUNT(*) AS total FROM users",
+		},
+		{
+			name:       "ignores order and paging",
+			condition:  &Condition{Field: "age", Operator: ">", Value: 18, OrderBy: []string{"name"}, Limit: 10, Offset: 20},
+			want:       "SELECT COUNT(*) AS total FROM users WHERE age > $1",
+			wantValues: []interface{}{18},
+		},
+		{
+			name:       "grouped",
+			condition:  &Condition{Field: "age", Operator: ">", Value: 18, GroupBy: []string{"country"}},
+			want:       "SELECT COUNT(*) AS total FROM (SELECT 1 FROM users WHERE age > $1 GROUP BY country) AS _count",
+			wantValues: []interface{}{18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, values, err := tt.condition.ToCountStringDialect(PostgreSQLDialect{}, "users")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if len(values) > 0 || len(tt.wantValues) > 0 {
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+				}
+			}
+		})
+	}
+}
+
+// TestComplexQueryToCountSQL checks plain queries are counted directly and grouped ones through a derived table
+func TestComplexQueryToCountSQL(t *testing.T) {
+	join := []Join{{Type: LeftJoin, Table: "orders", Condition: "users.id = orders.user_id"}}
+	where := &Condition{Field: "users.status", Operator: "=", Value: "active"}
+
+	tests := []struct {
+		name       string
+		query      *ComplexQuery
+		want       string
+		wantValues []interface{}
+	}{
+		{
+			name: "plain",
+			query: &ComplexQuery{
+				Select:      []string{"users.id", "orders.total"},
+				SelectExprs: []*Expression{Expr("orders.total * ? AS gross", 1.21)},
+				From:        "users",
+				Joins:       join,
+				Where:       where,
+				OrderBy:     []string{"users.id"},
+				Limit:       10,
+				Offset:      10,
+			},
+			want:       "SELECT COUNT(*) AS total FROM users LEFT JOIN orders ON users.id = orders.user_id WHERE users.status = $1",
+			wantValues: []interface{}{"active"},
+		},
+		{
+			name: "grouped",
+			query: &ComplexQuery{
+				Select:          []string{"users.id", "COUNT(orders.id) AS order_count"},
+				From:            "users",
+				Joins:           join,
+				Where:           where,
+				GroupBy:         []string{"users.id"},
+				HavingCondition: &Condition{Field: "COUNT(orders.id)", Operator: ">", Value: 2},
+				OrderTerms:      []OrderTerm{{Field: "users.id"}},
+				Limit:           10,
+			},
+			want: "SELECT COUNT(*) AS total FROM (SELECT users.id, COUNT(orders.id) AS order_count FROM users " +
+				"LEFT JOIN orders ON users.id = orders.user_id WHERE users.status = $1 GROUP BY users.id HAVING COUNT(orders.id) > $2) AS _count",
+			wantValues: []interface{}{"active", 2},
+		},
+		{
+			name: "aggregate without group by",
+			query: &ComplexQuery{
+				SelectTerms: []SelectTerm{{Expr: "COUNT(*)", Alias: "n"}, {Expr: "SUM(orders.total)"}},
+				From:        "users",
+				Joins:       join,
+				Where:       where,
+			},
+			want: "SELECT COUNT(*) AS total FROM (SELECT COUNT(*) AS n, SUM(orders.total) FROM users " +
+				"LEFT JOIN orders ON users.id = orders.user_id WHERE users.status = $1) AS _count",
+			wantValues: []interface{}{"active"},
+		},
+		{
+			name: "aggregate expression",
+			query: &ComplexQuery{
+				SelectExprs: []*Expression{Expr("max(orders.total) AS top")},
+				From:        "orders",
+			},
+			want: "SELECT COUNT(*) AS total FROM (SELECT max(orders.total) AS top FROM orders) AS _count",
+		},
+		{
+			name: "compound",
+			query: &ComplexQuery{Compound: &CompoundQuery{
+				Base:       &ComplexQuery{Select: []string{"email"}, From: "customers"},
+				Parts:      []CompoundPart{{Operator: Union, Query: &ComplexQuery{Select: []string{"email"}, From: "subscribers"}}},
+				OrderTerms: []OrderTerm{{Field: "email"}},
+				Limit:      5,
+			}},
+			want: "SELECT COUNT(*) AS total FROM (SELECT email FROM customers UNION SELECT email FROM subscribers) AS _count",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, values, err := tt.query.ToCountSQLDialect(PostgreSQLDialect{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if len(values) > 0 || len(tt.wantValues) > 0 {
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+				}
+			}
+		})
+	}
+}
+
+// TestPageQuery checks the default limit and the page built from the count
+func TestPageQuery(t *testing.T) {
+	pq, err := (&Condition{Field: "age", Operator: ">", Value: 18, Offset: 40}).ToPageQueryDialect(SQLiteDialect{}, "users")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pq.Limit != DEFAULT_PAGINATION_LIMIT || pq.Offset != 40 {
+		t.Errorf("Expected limit %d offset 40, got %d %d", DEFAULT_PAGINATION_LIMIT, pq.Limit, pq.Offset)
+	}
+	if want := "SELECT COUNT(*) AS total FROM users WHERE age > ?"; pq.Count.Query != want {
+		t.Errorf("Expected %q, got %q", want, pq.Count.Query)
+	}
+
+	tests := []struct {
+		name     string
+		total    interface{}
+		rows     int
+		wantMore bool
+	}{
+		{"more rows", int64(100), 50, true},
+		{"last page", json.Number("90"), 50, false},
+		{"float from json", float64(91), 50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := pq.Page(make([]DBRecord, tt.rows), []DBRecord{{Data: map[string]interface{}{"total": tt.total}}})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if page.HasMore != tt.wantMore || !page.HasPrev || page.Limit != pq.Limit || page.Offset != 40 {
+				t.Errorf("Unexpected page %+v", page)
+			}
+		})
+	}
+
+	if _, err := pq.Page(nil, nil); err == nil {
+		t.Error("Expected an error without a count row")
+	}
+
+	// A zero total is kept in JSON, so "no rows" is not mistaken for "not counted"
+	page, err := pq.Page(nil, []DBRecord{{Data: map[string]interface{}{"total": int64(0)}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(page)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total, ok := decoded["total"]; !ok || total != float64(0) {
+		t.Errorf("Expected \"total\":0 in %s", data)
+	}
+}
@@ -0,0 +1,207 @@
+package orm
+
+import (
+	"errors"
+	"iter"
+	"strings"
+)
+
+// errNotStubbed is returned by the stubDB methods a test did not expect to be called
+var errNotStubbed = errors.New("stubDB: method not stubbed")
+
+// stubDB is the shared in-package test double for Database. The select methods return
+// the canned rows (ErrSQLNoRows when there are none), DescribeTable the canned tables,
+// and the write methods record what they were given. Every other method returns
+// errNotStubbed, so an unexpected call fails the test instead of panicking.
+type stubDB struct {
+	dialect Dialect              // SQLiteDialect when nil
+	rows    []DBRecord           // returned by the select methods
+	tables  map[string]TableInfo // returned by DescribeTable, ErrTableNotFound otherwise
+	failOn  string               // an executed statement containing it fails
+
+	// Recorded calls
+	condition *Condition               // last condition given to a select, update or delete
+	query     *ComplexQuery            // last ComplexQuery given to SelectManyComplex
+	execs     [][]string               // statements of each ExecOneSQL / ExecManySQL call
+	updates   []map[string]interface{} // set of each UpdateWithCondition call
+	deletes   int                      // number of DeleteWithCondition calls
+}
+
+func (s *stubDB) Dialect() Dialect {
+	if s.dialect == nil {
+		return SQLiteDialect{}
+	}
+	return s.dialect
+}
+
+func (s *stubDB) GetSchema(bool, bool) []SchemaStruct { return nil }
+
+func (s *stubDB) ListTables() ([]string, error) {
+	if s.tables == nil {
+		return nil, errNotStubbed
+	}
+	var names []string
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (s *stubDB) DescribeTable(name string) (TableInfo, error) {
+	info, ok := s.tables[name]
+	if !ok {
+		return TableInfo{}, ErrTableNotFound
+	}
+	return info, nil
+}
+
+func (s *stubDB) Status() (NodeStatusStruct, error) { return NodeStatusStruct{}, errNotStubbed }
+
+func (s *stubDB) SelectOne(string) (DBRecord, error) { return DBRecord{}, errNotStubbed }
+
+func (s *stubDB) SelectMany(string) (DBRecords, error) { return nil, errNotStubbed }
+
+func (s *stubDB) SelectOneWithCondition(table string, condition *Condition) (DBRecord, error) {
+	s.condition = condition
+	if len(s.rows) == 0 {
+		return DBRecord{}, ErrSQLNoRows
+	}
+	return s.rows[0], nil
+}
+
+func (s *stubDB) SelectManyWithCondition(table string, condition *Condition) ([]DBRecord, error) {
+	s.condition = condition
+	if len(s.rows) == 0 {
+		return nil, ErrSQLNoRows
+	}
+	return s.rows, nil
+}
+
+func (s *stubDB) SelectManyComplex(query *ComplexQuery) ([]DBRecord, error) {
+	s.query = query
+	if len(s.rows) == 0 {
+		return nil, ErrSQLNoRows
+	}
+	return s.rows, nil
+}
+
+func (s *stubDB) SelectOneComplex(*ComplexQuery) (DBRecord, error) { return DBRecord{}, errNotStubbed }
+
+func (s *stubDB) SelectPage(string, *Condition, PageOptions) (Page, error) {
+	return Page{}, errNotStubbed
+}
+
+func (s *stubDB) SelectPageComplex(*ComplexQuery, PageOptions) (Page, error) {
+	return Page{}, errNotStubbed
+}
+
+func (s *stubDB) StreamWithCondition(string, *Condition) iter.Seq2[DBRecord, error] {
+	return stubStream()
+}
+
+func (s *stubDB) StreamComplex(*ComplexQuery) iter.Seq2[DBRecord, error] { return stubStream() }
+
+func (s *stubDB) StreamSQLParameterized(ParametereizedSQL) iter.Seq2[DBRecord, error] {
+	return stubStream()
+}
+
+func (s *stubDB) SelectOneSQL(string) (DBRecords, error) { return nil, errNotStubbed }
+
+func (s *stubDB) SelectManySQL([]string) ([]DBRecords, error) { return nil, errNotStubbed }
+
+func (s *stubDB) SelectOnlyOneSQL(string) (DBRecord, error) { return DBRecord{}, errNotStubbed }
+
+func (s *stubDB) SelectOneSQLParameterized(ParametereizedSQL) (DBRecords, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) SelectManySQLParameterized([]ParametereizedSQL) ([]DBRecords, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) SelectOnlyOneSQLParameterized(ParametereizedSQL) (DBRecord, error) {
+	return DBRecord{}, errNotStubbed
+}
+
+func (s *stubDB) ExecOneSQL(sql string) BasicSQLResult {
+	s.execs = append(s.execs, []string{sql})
+	if s.failOn != "" && strings.Contains(sql, s.failOn) {
+		return BasicSQLResult{Error: errors.New("no such table: " + s.failOn)}
+	}
+	return BasicSQLResult{RowsAffected: 1}
+}
+
+func (s *stubDB) ExecOneSQLParameterized(ParametereizedSQL) BasicSQLResult {
+	return BasicSQLResult{Error: errNotStubbed}
+}
+
+func (s *stubDB) ExecManySQL(sqls []string) ([]BasicSQLResult, error) {
+	s.execs = append(s.execs, sqls)
+	for _, sql := range sqls {
+		if s.failOn != "" && strings.Contains(sql, s.failOn) {
+			return nil, errors.New("no such table: " + s.failOn)
+		}
+	}
+	return make([]BasicSQLResult, len(sqls)), nil
+}
+
+func (s *stubDB) ExecManySQLParameterized([]ParametereizedSQL) ([]BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) InsertOneDBRecord(DBRecord, bool) BasicSQLResult {
+	return BasicSQLResult{Error: errNotStubbed}
+}
+
+func (s *stubDB) InsertManyDBRecords([]DBRecord, bool) ([]BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) InsertManyDBRecordsSameTable([]DBRecord, bool) ([]BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) InsertOneTableStruct(TableStruct, bool) BasicSQLResult {
+	return BasicSQLResult{Error: errNotStubbed}
+}
+
+func (s *stubDB) InsertManyTableStructs([]TableStruct, bool) ([]BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) UpsertOneDBRecord(DBRecord, UpsertOptions) BasicSQLResult {
+	return BasicSQLResult{Error: errNotStubbed}
+}
+
+func (s *stubDB) UpsertManyDBRecordsSameTable([]DBRecord, UpsertOptions) ([]BasicSQLResult, error) {
+	return nil, errNotStubbed
+}
+
+func (s *stubDB) IsConnected() bool { return false }
+
+func (s *stubDB) Leader() (string, error) { return "", errNotStubbed }
+
+func (s *stubDB) Peers() ([]string, error) { return nil, errNotStubbed }
+
+func (s *stubDB) UpdateWithCondition(table string, set map[string]interface{}, where *Condition, allRows bool) BasicSQLResult {
+	s.condition = where
+	s.updates = append(s.updates, set)
+	return BasicSQLResult{RowsAffected: 1}
+}
+
+func (s *stubDB) DeleteWithCondition(table string, where *Condition, allRows bool) BasicSQLResult {
+	s.condition = where
+	s.deletes++
+	return BasicSQLResult{RowsAffected: 1}
+}
+
+func (s *stubDB) BeginTransaction() (Transaction, error) { return nil, errNotStubbed }
+
+// stubStream yields errNotStubbed once
+func stubStream() iter.Seq2[DBRecord, error] {
+	return func(yield func(DBRecord, error) bool) {
+		yield(DBRecord{}, errNotStubbed)
+	}
+}
+
+var _ Database = (*stubDB)(nil)
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"fmt"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrMissingSubquery          medaerror.MedaError = medaerror.MedaError{Message: "EXISTS / NOT EXISTS condition requires a Subquery"}
+	ErrInvalidSubqueryCondition medaerror.MedaError = medaerror.MedaError{Message: "invalid subquery condition: use EXISTS without Field, or a Field with IN, NOT IN or a comparison operator"}
+)
+
+// subqueryOperators can compare a field with a subquery: IN for a column of values,
+// the comparisons for a scalar subquery (one row, one column)
+var subqueryOperators = map[string]bool{
+	"IN": true, "NOT IN": true,
+	"=": true, "!=": true, "<>": true, ">": true, "<": true, ">=": true, "<=": true,
+}
+
+// Exists returns a condition testing that the subquery returns at least one row.
+//
+//	// users having at least one paid order
+//	where := orm.Exists(&orm.ComplexQuery{
+//	    Select: []string{"1"},
+//	    From:   "orders",
+//	    Where:  &orm.Condition{Field: "orders.status", Operator: "=", Value: "paid"},
+//	})
+//	// EXISTS (SELECT 1 FROM orders WHERE orders.status = ?)
+func Exists(query *ComplexQuery) Condition {
+	return Condition{Operator: "EXISTS", Subquery: query}
+}
+
+// NotExists returns a condition testing that the subquery returns no rows
+func NotExists(query *ComplexQuery) Condition {
+	return Condition{Operator: "NOT EXISTS", Subquery: query}
+}
+
+// writeSubqueryComparison renders EXISTS (...) or field OP (...).
+// The subquery binds its values into the same builder, so placeholders stay in
+// statement order, and it may reference the tables of the enclosing query (correlation).
+func (c *Condition) writeSubqueryComparison(b *sqlBuilder, op string) (string, error) {
+	if c.Subquery == nil {
+		return "", ErrMissingSubquery
+	}
+
+	switch {
+	case op == "EXISTS" || op == "NOT EXISTS":
+		if c.Field != "" {
+			return "", ErrInvalidSubqueryCondition
+		}
+		sub, err := c.Subquery.writeSQL(b, true)
+		if err != nil {
+			return "", fmt.Errorf("failed to build subquery: %w", err)
+		}
+		return fmt.Sprintf("%s (%s)", op, sub), nil
+	case c.Field != "" && subqueryOperators[op]:
+		field := b.column(c.Field)
+		sub, err := c.Subquery.writeSQL(b, true)
+		if err != nil {
+			return "", fmt.Errorf("failed to build subquery: %w", err)
+		}
+		return fmt.Sprintf("%s %s (%s)", field, op, sub), nil
+	}
+	return "", ErrInvalidSubqueryCondition
+}
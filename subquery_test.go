@@ -0,0 +1,154 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSubqueryConditions checks EXISTS, IN and scalar comparisons against subqueries
+func TestSubqueryConditions(t *testing.T) {
+	paidOrders := &ComplexQuery{
+		Select: []string{"user_id"},
+		From:   "orders",
+		Where:  &Condition{Field: "status", Operator: "=", Value: "paid"},
+	}
+
+	tests := []struct {
+		name       string
+		condition  Condition
+		want       string
+		wantValues []interface{}
+		wantErr    error
+	}{
+		{
+			name:       "IN subquery",
+			condition:  Condition{Field: "id", Operator: "IN", Subquery: paidOrders},
+			want:       "id IN (SELECT user_id FROM orders WHERE status = $1)",
+			wantValues: []interface{}{"paid"},
+		},
+		{
+			name:       "NOT IN subquery ignores Value",
+			condition:  Condition{Field: "id", Operator: "NOT IN", Value: []int{1, 2}, Subquery: paidOrders},
+			want:       "id NOT IN (SELECT user_id FROM orders WHERE status = $1)",
+			wantValues: []interface{}{"paid"},
+		},
+		{
+			name: "scalar comparison",
+			condition: Condition{Field: "price", Operator: ">", Subquery: &ComplexQuery{
+				Select: []string{"AVG(price)"},
+				From:   "products",
+			}},
+			want: "price > (SELECT AVG(price) FROM products)",
+		},
+		{
+			name:       "EXISTS",
+			condition:  Exists(paidOrders),
+			want:       "EXISTS (SELECT user_id FROM orders WHERE status = $1)",
+			wantValues: []interface{}{"paid"},
+		},
+		{
+			name:       "NOT EXISTS",
+			condition:  NotExists(paidOrders),
+			want:       "NOT EXISTS (SELECT user_id FROM orders WHERE status = $1)",
+			wantValues: []interface{}{"paid"},
+		},
+		{
+			name:      "EXISTS without subquery",
+			condition: Condition{Operator: "EXISTS"},
+			wantErr:   ErrMissingSubquery,
+		},
+		{
+			name:      "EXISTS with field",
+			condition: Condition{Field: "id", Operator: "EXISTS", Subquery: paidOrders},
+			wantErr:   ErrInvalidSubqueryCondition,
+		},
+		{
+			name:      "BETWEEN subquery",
+			condition: Condition{Field: "id", Operator: "BETWEEN", Subquery: paidOrders},
+			wantErr:   ErrInvalidSubqueryCondition,
+		},
+		{
+			name:      "invalid subquery",
+			condition: Exists(&ComplexQuery{From: "orders; DROP TABLE users"}),
+			wantErr:   ErrInvalidTableName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, values, err := tt.condition.ToWhereStringDialect(PostgreSQLDialect{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if len(values) != 0 || len(tt.wantValues) != 0 {
+				if !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+				}
+			}
+		})
+	}
+}
+
+// TestSubqueryArgumentOrder checks values from CTEs, WHERE and subqueries are bound in statement order
+func TestSubqueryArgumentOrder(t *testing.T) {
+	query := &ComplexQuery{
+		CTEs: []CommonTableExpression{{
+			Name:  "recent",
+			Query: &ComplexQuery{From: "orders", Where: &Condition{Field: "created_at", Operator: ">", Value: "2024-01-01"}},
+		}},
+		From:      "users",
+		FromAlias: "u",
+		Where: &Condition{Logic: "AND", Nested: []Condition{
+			{Field: "u.status", Operator: "=", Value: "active"},
+			Exists(&ComplexQuery{
+				Select: []string{"1"},
+				From:   "recent",
+				Where: &Condition{Logic: "AND", Nested: []Condition{
+					{Field: "recent.total", Operator: ">", Value: 100},
+					{Field: "u.country", Operator: "=", Value: "NL"}, // correlated with the outer alias
+				}},
+			}),
+			{Field: "u.age", Operator: "BETWEEN", Value: []int{18, 65}},
+		}},
+		Limit: 10,
+	}
+
+	want := "WITH recent AS (SELECT * FROM orders WHERE created_at > $1) SELECT * FROM users AS u WHERE " +
+		"(u.status = $2) AND (EXISTS (SELECT 1 FROM recent WHERE (recent.total > $3) AND (u.country = $4))) AND (u.age BETWEEN $5 AND $6) LIMIT 10"
+	wantValues := []interface{}{"2024-01-01", "active", 100, "NL", 18, 65}
+
+	got, values, err := query.ToSQLDialect(PostgreSQLDialect{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("Expected values %v, got %v", wantValues, values)
+	}
+}
+
+// TestSubqueryScope checks a subquery cannot leak its tables to the outer query
+func TestSubqueryScope(t *testing.T) {
+	query := &ComplexQuery{
+		From: "users",
+		Where: &Condition{Logic: "AND", Nested: []Condition{
+			Exists(&ComplexQuery{From: "orders"}),
+			{Field: "orders.total", Operator: ">", Value: 1},
+		}},
+	}
+	if _, _, err := query.ToSQL(); !errors.Is(err, ErrUnknownQualifier) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownQualifier, err)
+	}
+}
@@ -0,0 +1,221 @@
+package orm
+
+import (
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrInvalidOrderTerm  medaerror.MedaError = medaerror.MedaError{Message: "invalid order by term: expected a column optionally prefixed with - (descending) or + (ascending)"}
+	ErrInvalidNullsOrder medaerror.MedaError = medaerror.MedaError{Message: "invalid NULLS ordering: must be FIRST, LAST or empty"}
+	ErrInvalidSelectTerm medaerror.MedaError = medaerror.MedaError{Message: "invalid select term: expected *, table.*, a column or an aggregate of a column"}
+)
+
+// NullsOrder places NULL values before or after the others in an OrderTerm
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""      // database default (PostgreSQL: last for ASC, SQLite: first for ASC)
+	NullsFirst   NullsOrder = "FIRST" // NULLS FIRST
+	NullsLast    NullsOrder = "LAST"  // NULLS LAST
+)
+
+// OrderTerm is a structured ORDER BY entry. Field is checked like a condition field,
+// so it is safe to fill from API input (see ParseOrderBy). The ComplexQuery.OrderBy /
+// Condition.OrderBy strings are validated the same way, see writeOrderBy.
+// In a ComplexQuery, Field may also be a whitelisted aggregate such as COUNT(orders.id).
+//
+//	orm.OrderTerm{Field: "created_at", Desc: true, Nulls: orm.NullsLast}
+//	// created_at DESC NULLS LAST
+type OrderTerm struct {
+	Field string     `json:"field"`
+	Desc  bool       `json:"desc,omitempty"`
+	Nulls NullsOrder `json:"nulls,omitempty"`
+}
+
+// SelectTerm is a validated SELECT entry: *, table.*, a column or a whitelisted
+// aggregate of a column, with an optional alias.
+//
+//	orm.SelectTerm{Expr: "COUNT(orders.id)", Alias: "order_count"}
+//	// COUNT(orders.id) AS order_count
+type SelectTerm struct {
+	Expr  string `json:"expr"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// ParseOrderBy parses a comma separated sort specification as commonly used in
+// query strings: a leading - sorts descending, an optional + ascending.
+// Every field is validated like ValidateFieldName, so the result can be used directly.
+// If allowed is not empty, fields must also be one of the allowed names (case-insensitive).
+//
+// Usage:
+//
+//	terms, err := orm.ParseOrderBy(r.URL.Query().Get("sort")) // "-created_at,name"
+//	// []OrderTerm{{Field: "created_at", Desc: true}, {Field: "name"}}
+//
+//	terms, err = orm.ParseOrderBy("-price", "price", "name") // restrict to known columns
+func ParseOrderBy(spec string, allowed ...string) ([]OrderTerm, error) {
+	var allowedSet map[string]bool
+	if len(allowed) > 0 {
+		allowedSet = make(map[string]bool, len(allowed))
+		for _, name := range allowed {
+			allowedSet[identifierKey(name)] = true
+		}
+	}
+
+	var terms []OrderTerm
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		term := OrderTerm{Field: part}
+		switch part[0] {
+		case '-':
+			term = OrderTerm{Field: strings.TrimSpace(part[1:]), Desc: true}
+		case '+':
+			term = OrderTerm{Field: strings.TrimSpace(part[1:])}
+		}
+		if term.Field == "" || ValidateFieldName(term.Field) != nil {
+			return nil, ErrInvalidOrderTerm
+		}
+		if allowedSet != nil && !allowedSet[identifierKey(term.Field)] {
+			return nil, ErrInvalidOrderTerm
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// writeOrderTerm renders one OrderTerm
+func (b *sqlBuilder) writeOrderTerm(term OrderTerm) (string, error) {
+	if term.Field == "" {
+		return "", ErrInvalidOrderTerm
+	}
+	if err := b.checkField(term.Field); err != nil {
+		return "", err
+	}
+	sql := b.column(term.Field)
+	if term.Desc {
+		sql += " DESC"
+	} else {
+		sql += " ASC"
+	}
+	switch NullsOrder(strings.ToUpper(string(term.Nulls))) {
+	case NullsDefault:
+	case NullsFirst:
+		sql += " NULLS FIRST"
+	case NullsLast:
+		sql += " NULLS LAST"
+	default:
+		return "", ErrInvalidNullsOrder
+	}
+	return sql, nil
+}
+
+// writeOrderTerms renders a list of OrderTerm
+func (b *sqlBuilder) writeOrderTerms(terms []OrderTerm) ([]string, error) {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		sql, err := b.writeOrderTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, sql)
+	}
+	return parts, nil
+}
+
+// splitOrderSuffix splits a raw ORDER BY entry such as "created_at DESC NULLS LAST" into
+// its field and the normalized direction/NULLS suffix (" DESC NULLS LAST")
+func splitOrderSuffix(entry string) (string, string) {
+	words := strings.Fields(entry)
+	n := len(words)
+	suffix := ""
+	if n >= 2 && strings.EqualFold(words[n-2], "NULLS") {
+		switch nulls := NullsOrder(strings.ToUpper(words[n-1])); nulls {
+		case NullsFirst, NullsLast:
+			suffix = " NULLS " + string(nulls)
+			n -= 2
+		}
+	}
+	if n >= 1 {
+		switch dir := strings.ToUpper(words[n-1]); dir {
+		case "ASC", "DESC":
+			suffix = " " + dir + suffix
+			n--
+		}
+	}
+	return strings.Join(words[:n], " "), suffix
+}
+
+// writeOrderBy validates and renders the raw ORDER BY strings of Condition.OrderBy and
+// ComplexQuery.OrderBy: each entry must be a column (or an aggregate where the builder
+// allows them) optionally followed by ASC/DESC and NULLS FIRST/LAST. Unlike an OrderTerm,
+// no direction is added when the entry has none.
+func (b *sqlBuilder) writeOrderBy(entries []string) ([]string, error) {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		field, suffix := splitOrderSuffix(entry)
+		if field == "" {
+			return nil, ErrInvalidOrderTerm
+		}
+		if err := b.checkField(field); err != nil {
+			return nil, err
+		}
+		parts = append(parts, b.column(field)+suffix)
+	}
+	return parts, nil
+}
+
+// writeSelectTerm renders one SelectTerm
+func (b *sqlBuilder) writeSelectTerm(term SelectTerm) (string, error) {
+	expr := strings.TrimSpace(term.Expr)
+	var sql string
+	switch {
+	case expr == "*":
+		sql = "*"
+	case strings.HasSuffix(expr, ".*"):
+		table := strings.TrimSuffix(expr, ".*")
+		if ValidateTableName(table) != nil {
+			return "", ErrInvalidSelectTerm
+		}
+		if err := b.checkTableRef(table); err != nil {
+			return "", err
+		}
+		sql = b.ident(table) + ".*"
+	default:
+		if expr == "" {
+			return "", ErrInvalidSelectTerm
+		}
+		if err := b.checkField(expr); err != nil {
+			return "", err
+		}
+		sql = b.column(expr)
+	}
+
+	if term.Alias != "" {
+		if err := ValidateAlias(term.Alias); err != nil {
+			return "", err
+		}
+		sql += " AS " + b.ident(term.Alias)
+	}
+	return sql, nil
+}
+
+// writeColumns validates and renders a list of plain column names
+// (GROUP BY, both the raw GroupBy strings and GroupByColumns)
+func (b *sqlBuilder) writeColumns(columns []string) ([]string, error) {
+	parts := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col == "" {
+			return nil, ErrInvalidFieldName
+		}
+		if err := b.checkField(col); err != nil {
+			return nil, err
+		}
+		parts = append(parts, b.column(col))
+	}
+	return parts, nil
+}
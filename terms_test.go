@@ -0,0 +1,257 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParseOrderBy checks sort specifications are parsed and validated
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		allowed []string
+		want    []OrderTerm
+		wantErr error
+	}{
+		{name: "empty", spec: "", want: nil},
+		{name: "single", spec: "name", want: []OrderTerm{{Field: "name"}}},
+		{
+			name: "mixed directions",
+			spec: "-created_at, +name,users.id",
+			want: []OrderTerm{{Field: "created_at", Desc: true}, {Field: "name"}, {Field: "users.id"}},
+		},
+		{name: "empty parts skipped", spec: ",-price,,", want: []OrderTerm{{Field: "price", Desc: true}}},
+		{name: "allowed", spec: "-Price", allowed: []string{"price", "name"}, want: []OrderTerm{{Field: "Price", Desc: true}}},
+		{name: "not allowed", spec: "-password", allowed: []string{"price", "name"}, wantErr: ErrInvalidOrderTerm},
+		{name: "sign only", spec: "-", wantErr: ErrInvalidOrderTerm},
+		{name: "injection", spec: "name; DROP TABLE users", wantErr: ErrInvalidOrderTerm},
+		{name: "expression", spec: "(CASE WHEN 1=1 THEN name END)", wantErr: ErrInvalidOrderTerm},
+		{name: "direction keyword", spec: "name DESC", wantErr: ErrInvalidOrderTerm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOrderBy(tt.spec, tt.allowed...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestConditionOrderTerms checks OrderTerms are rendered after the raw OrderBy of a condition select
+func TestConditionOrderTerms(t *testing.T) {
+	condition := Condition{
+		Field:      "status",
+		Operator:   "=",
+		Value:      "active",
+		OrderBy:    []string{"id"},
+		OrderTerms: []OrderTerm{{Field: "created_at", Desc: true, Nulls: NullsLast}, {Field: "user", Nulls: "first"}},
+	}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{SQLiteDialect{}, `SELECT * FROM users WHERE status = ?  ORDER BY id, created_at DESC NULLS LAST, "user" ASC NULLS FIRST `},
+		{PostgreSQLDialect{}, `SELECT * FROM users WHERE status = $1  ORDER BY id, created_at DESC NULLS LAST, "user" ASC NULLS FIRST `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			got, _, err := condition.ToSelectStringDialect(tt.dialect, "users")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestComplexQueryTerms checks SelectTerms, GroupByColumns and OrderTerms in a complex query
+func TestComplexQueryTerms(t *testing.T) {
+	query := &ComplexQuery{
+		SelectTerms: []SelectTerm{
+			{Expr: "u.*"},
+			{Expr: "COUNT(DISTINCT o.id)", Alias: "order_count"},
+			{Expr: "o.user_id", Alias: "Owner"},
+		},
+		From:           "users",
+		FromAlias:      "u",
+		Joins:          []Join{{Type: LeftJoin, Table: "orders", Alias: "o", Condition: "u.id = o.user_id"}},
+		Where:          &Condition{Field: "u.status", Operator: "=", Value: "active"},
+		GroupByColumns: []string{"u.id", "o.user_id"},
+		OrderTerms:     []OrderTerm{{Field: "count(o.id)", Desc: true}, {Field: "u.name"}},
+	}
+
+	want := `SELECT u.*, COUNT(DISTINCT o.id) AS order_count, o.user_id AS "Owner" FROM users AS u ` +
+		`LEFT JOIN orders AS o ON u.id = o.user_id WHERE u.status = $1 GROUP BY u.id, o.user_id ` +
+		`ORDER BY COUNT(o.id) DESC, u.name ASC`
+
+	got, values, err := query.ToSQLDialect(PostgreSQLDialect{QuoteMixedCase: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"active"}) {
+		t.Errorf("Expected values [active], got %v", values)
+	}
+}
+
+// TestComplexQueryTermErrors checks invalid terms are rejected instead of rendered
+func TestComplexQueryTermErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   ComplexQuery
+		wantErr error
+	}{
+		{
+			name:    "select injection",
+			query:   ComplexQuery{From: "users", SelectTerms: []SelectTerm{{Expr: "id FROM secrets --"}}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name:    "select unknown function",
+			query:   ComplexQuery{From: "users", SelectTerms: []SelectTerm{{Expr: "pg_sleep(10)"}}},
+			wantErr: ErrInvalidAggregate,
+		},
+		{
+			name:    "select empty",
+			query:   ComplexQuery{From: "users", SelectTerms: []SelectTerm{{Expr: " "}}},
+			wantErr: ErrInvalidSelectTerm,
+		},
+		{
+			name:    "select unknown table star",
+			query:   ComplexQuery{From: "users", SelectTerms: []SelectTerm{{Expr: "orders.*"}}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "select invalid alias",
+			query:   ComplexQuery{From: "users", SelectTerms: []SelectTerm{{Expr: "id", Alias: "x; DROP"}}},
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "group by aggregate",
+			query:   ComplexQuery{From: "users", GroupByColumns: []string{"COUNT(id)"}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name:    "group by unknown qualifier",
+			query:   ComplexQuery{From: "users", GroupByColumns: []string{"orders.id"}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "order by injection",
+			query:   ComplexQuery{From: "users", OrderTerms: []OrderTerm{{Field: "id; DROP TABLE users"}}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name:    "order by invalid nulls",
+			query:   ComplexQuery{From: "users", OrderTerms: []OrderTerm{{Field: "id", Nulls: "MIDDLE"}}},
+			wantErr: ErrInvalidNullsOrder,
+		},
+		{
+			name:    "order by empty",
+			query:   ComplexQuery{From: "users", OrderTerms: []OrderTerm{{}}},
+			wantErr: ErrInvalidOrderTerm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.query.ToSQL(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestRawOrderByGroupBy checks the OrderBy and GroupBy strings are validated before they are rendered
+func TestRawOrderByGroupBy(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *Condition // rendered with ToSelectString("users"), or ToCountString when count is set
+		count     bool
+		query     *ComplexQuery // rendered with ToSQL when set
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "condition direction and nulls",
+			condition: &Condition{OrderBy: []string{"created_at desc nulls last", "name", "user ASC"}, GroupBy: []string{"users.country"}},
+			want:      `SELECT * FROM users GROUP BY users.country ORDER BY created_at DESC NULLS LAST, name, "user" ASC `,
+		},
+		{
+			name:      "condition order by injection",
+			condition: &Condition{OrderBy: []string{"name; DROP TABLE users"}},
+			wantErr:   ErrInvalidFieldName,
+		},
+		{
+			name:      "condition order by expression",
+			condition: &Condition{OrderBy: []string{"(CASE WHEN 1=1 THEN name END) DESC"}},
+			wantErr:   ErrInvalidFieldName,
+		},
+		{
+			name:      "condition order by direction only",
+			condition: &Condition{OrderBy: []string{"DESC"}},
+			wantErr:   ErrInvalidOrderTerm,
+		},
+		{
+			name:      "condition group by injection",
+			condition: &Condition{GroupBy: []string{"country) UNION SELECT password FROM admins --"}},
+			wantErr:   ErrInvalidFieldName,
+		},
+		{
+			name:      "count group by injection",
+			condition: &Condition{GroupBy: []string{"1; DELETE FROM users"}},
+			count:     true,
+			wantErr:   ErrInvalidFieldName,
+		},
+		{
+			name: "complex query aggregate order",
+			query: &ComplexQuery{From: "users", Joins: []Join{{Type: LeftJoin, Table: "orders", Condition: "users.id = orders.user_id"}},
+				GroupBy: []string{"users.id"}, OrderBy: []string{"COUNT(orders.id) DESC", "order_count"}},
+			want: "SELECT * FROM users LEFT JOIN orders ON users.id = orders.user_id GROUP BY users.id ORDER BY COUNT(orders.id) DESC, order_count",
+		},
+		{
+			name:    "complex query unknown qualifier",
+			query:   &ComplexQuery{From: "users", OrderBy: []string{"admins.password"}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "complex query group by aggregate",
+			query:   &ComplexQuery{From: "users", GroupBy: []string{"COUNT(id)"}},
+			wantErr: ErrInvalidFieldName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sql string
+			var err error
+			switch {
+			case tt.query != nil:
+				sql, _, err = tt.query.ToSQL()
+			case tt.count:
+				sql, _, err = tt.condition.ToCountString("users")
+			default:
+				sql, _, err = tt.condition.ToSelectString("users")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if sql != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, sql)
+			}
+		})
+	}
+}
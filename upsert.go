@@ -0,0 +1,246 @@
+package orm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+// UpsertPolicy decides what happens when an inserted row hits a conflict
+type UpsertPolicy string
+
+const (
+	UpsertUpdateAll     UpsertPolicy = "UPDATE ALL"     // update every non-conflict column with the new value (default)
+	UpsertUpdateColumns UpsertPolicy = "UPDATE COLUMNS" // update only UpsertOptions.UpdateColumns
+	UpsertDoNothing     UpsertPolicy = "DO NOTHING"     // keep the existing row untouched
+)
+
+var (
+	ErrUpsertNoConflictColumns medaerror.MedaError = medaerror.MedaError{Message: "upsert with DO UPDATE requires at least one conflict column"}
+	ErrUpsertNoUpdateColumns   medaerror.MedaError = medaerror.MedaError{Message: "upsert has no columns to update"}
+	ErrUpsertInvalidPolicy     medaerror.MedaError = medaerror.MedaError{Message: "invalid upsert policy"}
+)
+
+// UpsertOptions describes the ON CONFLICT part of an upsert.
+// Both SQLite (>= 3.24, used by RQLite) and PostgreSQL accept the generated syntax:
+//
+//	INSERT INTO users (email, name) VALUES (?, ?)
+//	ON CONFLICT (email) DO UPDATE SET name = excluded.name WHERE users.locked = ?
+//
+// Sample usage:
+//
+//	opts := orm.UpsertOptions{
+//	    ConflictColumns: []string{"email"},
+//	    Policy:          orm.UpsertUpdateColumns,
+//	    UpdateColumns:   []string{"name"},
+//	}
+type UpsertOptions struct {
+	ConflictColumns []string     `json:"conflict_columns,omitempty"` // Unique/PK columns that define a conflict
+	Policy          UpsertPolicy `json:"policy,omitempty"`           // Empty means UpsertUpdateAll
+	UpdateColumns   []string     `json:"update_columns,omitempty"`   // Columns updated with UpsertUpdateColumns
+	Where           *Condition   `json:"where,omitempty"`            // Optional filter on the DO UPDATE part, unqualified columns refer to the existing row
+}
+
+// conflictClause renders the ON CONFLICT clause for the given insert columns,
+// binding the values of the optional WHERE filter into the builder.
+// Unqualified filter columns are qualified with the target table: inside DO UPDATE
+// both the existing row and excluded are in scope, PostgreSQL rejects a bare column
+// as ambiguous.
+func (o UpsertOptions) conflictClause(b *sqlBuilder, tableName string, columns []string) (string, error) {
+	// Conflict and update columns name columns of the target table, a qualified name
+	// would render as ON CONFLICT (t.c) or SET t.c = excluded.t.c
+	for _, col := range o.ConflictColumns {
+		if err := ValidateAlias(col); err != nil {
+			return "", fmt.Errorf("%w: conflict column %q", err, col)
+		}
+	}
+
+	conflictColumns := make([]string, 0, len(o.ConflictColumns))
+	for _, col := range o.ConflictColumns {
+		conflictColumns = append(conflictColumns, b.ident(col))
+	}
+
+	policy := o.Policy
+	if policy == "" {
+		policy = UpsertUpdateAll
+	}
+
+	var updateColumns []string
+	switch policy {
+	case UpsertDoNothing:
+		return b.dialect.UpsertClause(conflictColumns, nil), nil
+	case UpsertUpdateAll:
+		isConflict := make(map[string]bool, len(o.ConflictColumns))
+		for _, col := range o.ConflictColumns {
+			isConflict[strings.ToLower(col)] = true
+		}
+		for _, col := range columns {
+			if !isConflict[strings.ToLower(col)] {
+				updateColumns = append(updateColumns, b.ident(col))
+			}
+		}
+	case UpsertUpdateColumns:
+		for _, col := range o.UpdateColumns {
+			if err := ValidateAlias(col); err != nil {
+				return "", fmt.Errorf("%w: update column %q", err, col)
+			}
+			updateColumns = append(updateColumns, b.ident(col))
+		}
+	default:
+		return "", ErrUpsertInvalidPolicy
+	}
+
+	if len(o.ConflictColumns) == 0 {
+		return "", ErrUpsertNoConflictColumns
+	}
+	if len(updateColumns) == 0 {
+		return "", ErrUpsertNoUpdateColumns
+	}
+
+	clause := b.dialect.UpsertClause(conflictColumns, updateColumns)
+
+	if o.Where != nil {
+		parts := splitIdentifier(tableName)
+		target := parts[len(parts)-1]
+		where := qualifyCondition(*o.Where, target)
+
+		outerQualifiers := b.qualifiers
+		b.qualifiers = nil
+		b.allowTable(tableName, "")
+		b.allowQualifier("excluded")
+		whereClause, err := where.writeWhere(b)
+		b.qualifiers = outerQualifiers
+		if err != nil {
+			return "", err
+		}
+		if whereClause != "" {
+			return clause + " WHERE " + whereClause, nil
+		}
+	}
+	return clause, nil
+}
+
+// qualifyCondition returns a copy of the condition with every unqualified field prefixed
+// by table. Subqueries keep their own scope and are left untouched.
+func qualifyCondition(c Condition, table string) Condition {
+	if c.Field != "" && len(splitIdentifier(c.Field)) == 1 {
+		c.Field = table + "." + c.Field
+	}
+	if len(c.Nested) > 0 {
+		nested := make([]Condition, len(c.Nested))
+		for i, n := range c.Nested {
+			nested[i] = qualifyCondition(n, table)
+		}
+		c.Nested = nested
+	}
+	return c
+}
+
+// ToUpsertSQLParameterized converts a single DBRecord to a parameterized upsert statement.
+// Usage:
+//
+//	sql, values, err := record.ToUpsertSQLParameterized(opts)
+func (d *DBRecord) ToUpsertSQLParameterized(opts UpsertOptions) (string, []interface{}, error) {
+	return d.ToUpsertSQLParameterizedDialect(defaultDialect, opts)
+}
+
+// ToUpsertSQLParameterizedDialect is ToUpsertSQLParameterized rendered for the given dialect
+func (d *DBRecord) ToUpsertSQLParameterizedDialect(dialect Dialect, opts UpsertOptions) (string, []interface{}, error) {
+	statements, err := DBRecords{*d}.ToUpsertSQLParameterizedDialect(dialect, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return statements[0].Query, statements[0].Values, nil
+}
+
+// ToUpsertSQLParameterized converts multiple DBRecords (same table, same columns) to
+// parameterized upsert statements. Like ToInsertSQLParameterized it batches rows
+// according to MAX_MULTIPLE_INSERTS; every batch carries the same ON CONFLICT clause.
+// Columns are sorted so the statements are deterministic.
+// NOTE: PostgreSQL rejects a batch that contains the same conflict key twice
+// ("cannot affect row a second time"), de-duplicate the records first.
+// Usage:
+//
+//	statements, err := records.ToUpsertSQLParameterized(orm.UpsertOptions{ConflictColumns: []string{"id"}})
+func (records DBRecords) ToUpsertSQLParameterized(opts UpsertOptions) ([]ParametereizedSQL, error) {
+	return records.ToUpsertSQLParameterizedDialect(defaultDialect, opts)
+}
+
+// ToUpsertSQLParameterizedDialect is ToUpsertSQLParameterized rendered for the given dialect.
+// Placeholders are numbered per statement, the ON CONFLICT filter values come last.
+func (records DBRecords) ToUpsertSQLParameterizedDialect(d Dialect, opts UpsertOptions) ([]ParametereizedSQL, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	// Security: Nil check to prevent panic
+	if len(records[0].Data) == 0 {
+		return nil, fmt.Errorf("no fields to upsert")
+	}
+
+	tableName := records[0].TableName
+	if err := ValidateTableName(tableName); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(records[0].Data))
+	for key := range records[0].Data {
+		if err := ValidateFieldName(key); err != nil {
+			return nil, err
+		}
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+
+	// validate the options once up front, every batch renders its own clause
+	if _, err := opts.conflictClause(newSQLBuilder(d), tableName, columns); err != nil {
+		return nil, err
+	}
+
+	numFields := len(columns)
+	quotedColumns := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quotedColumns = append(quotedColumns, FormatIdentifier(d, col))
+	}
+	columnsSQL := "(" + strings.Join(quotedColumns, ", ") + ")"
+
+	batchSize := MAX_MULTIPLE_INSERTS
+	if batchSize < 1 {
+		batchSize = DEFAULT_MAX_MULTIPLE_INSERTS
+	}
+	paramStatements := make([]ParametereizedSQL, 0, (len(records)+batchSize-1)/batchSize)
+
+	for i := 0; i < len(records); i += batchSize {
+		end := i + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		currentBatch := records[i:end]
+
+		b := newSQLBuilder(d)
+		b.args = make([]interface{}, 0, len(currentBatch)*numFields)
+		placeholderGroups := make([]string, 0, len(currentBatch))
+		for _, record := range currentBatch {
+			if record.TableName != tableName {
+				return nil, fmt.Errorf("all records must be from the same table, got '%s' and '%s'", tableName, record.TableName)
+			}
+			placeholders := make([]string, 0, numFields)
+			for _, col := range columns {
+				placeholders = append(placeholders, b.bind(record.Data[col]))
+			}
+			placeholderGroups = append(placeholderGroups, "("+strings.Join(placeholders, ", ")+")")
+		}
+		conflictSQL, err := opts.conflictClause(b, tableName, columns)
+		if err != nil {
+			return nil, err
+		}
+
+		paramStatements = append(paramStatements, ParametereizedSQL{
+			Query: fmt.Sprintf("INSERT INTO %s %s VALUES %s %s",
+				FormatIdentifier(d, tableName), columnsSQL, strings.Join(placeholderGroups, ", "), conflictSQL),
+			Values: b.args,
+		})
+	}
+	return paramStatements, nil
+}
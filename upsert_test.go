@@ -0,0 +1,185 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestToUpsertSQL tests the ON CONFLICT rendering of the upsert policies
+func TestToUpsertSQL(t *testing.T) {
+	record := DBRecord{TableName: "users", Data: map[string]interface{}{"email": "a@b.c", "name": "ana", "age": 30}}
+
+	tests := []struct {
+		name       string
+		dialect    Dialect
+		record     DBRecord
+		opts       UpsertOptions
+		wantSQL    string
+		wantValues []interface{}
+		wantErr    error
+	}{
+		{
+			name:       "update all non-conflict columns by default",
+			record:     record,
+			opts:       UpsertOptions{ConflictColumns: []string{"email"}},
+			wantSQL:    "INSERT INTO users (age, email, name) VALUES (?, ?, ?) ON CONFLICT (email) DO UPDATE SET age = excluded.age, name = excluded.name",
+			wantValues: []interface{}{30, "a@b.c", "ana"},
+		},
+		{
+			name:       "update selected columns",
+			record:     record,
+			opts:       UpsertOptions{ConflictColumns: []string{"email"}, Policy: UpsertUpdateColumns, UpdateColumns: []string{"name"}},
+			wantSQL:    "INSERT INTO users (age, email, name) VALUES (?, ?, ?) ON CONFLICT (email) DO UPDATE SET name = excluded.name",
+			wantValues: []interface{}{30, "a@b.c", "ana"},
+		},
+		{
+			name:       "do nothing without conflict target",
+			record:     record,
+			opts:       UpsertOptions{Policy: UpsertDoNothing},
+			wantSQL:    "INSERT INTO users (age, email, name) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			wantValues: []interface{}{30, "a@b.c", "ana"},
+		},
+		{
+			name:    "where filter is qualified with the target table",
+			dialect: PostgreSQLDialect{},
+			record:  record,
+			opts: UpsertOptions{ConflictColumns: []string{"email"}, Policy: UpsertUpdateColumns, UpdateColumns: []string{"name"},
+				Where: &Condition{Logic: "AND", Nested: []Condition{
+					{Field: "locked", Operator: "=", Value: false},
+					{Field: "excluded.age", Operator: ">", Value: 18},
+					{Field: "users.age", Operator: "<", Value: 99},
+				}}},
+			wantSQL: "INSERT INTO users (age, email, name) VALUES ($1, $2, $3) ON CONFLICT (email) DO UPDATE SET name = excluded.name" +
+				" WHERE (users.locked = $4) AND (excluded.age > $5) AND (users.age < $6)",
+			wantValues: []interface{}{30, "a@b.c", "ana", false, 18, 99},
+		},
+		{
+			name:    "where filter uses the bare name of a schema-qualified table",
+			dialect: PostgreSQLDialect{},
+			record:  DBRecord{TableName: "app.users", Data: map[string]interface{}{"id": 1, "name": "ana"}},
+			opts: UpsertOptions{ConflictColumns: []string{"id"},
+				Where: &Condition{Field: "locked", Operator: "=", Value: false}},
+			wantSQL:    "INSERT INTO app.users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE users.locked = $3",
+			wantValues: []interface{}{1, "ana", false},
+		},
+		{
+			name:   "where filter with a foreign qualifier",
+			record: record,
+			opts: UpsertOptions{ConflictColumns: []string{"email"},
+				Where: &Condition{Field: "orders.locked", Operator: "=", Value: false}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "update without conflict columns",
+			record:  record,
+			opts:    UpsertOptions{},
+			wantErr: ErrUpsertNoConflictColumns,
+		},
+		{
+			name:    "nothing left to update",
+			record:  DBRecord{TableName: "users", Data: map[string]interface{}{"email": "a@b.c"}},
+			opts:    UpsertOptions{ConflictColumns: []string{"email"}},
+			wantErr: ErrUpsertNoUpdateColumns,
+		},
+		{
+			name:    "invalid policy",
+			record:  record,
+			opts:    UpsertOptions{ConflictColumns: []string{"email"}, Policy: "REPLACE"},
+			wantErr: ErrUpsertInvalidPolicy,
+		},
+		{
+			name:    "invalid conflict column",
+			record:  record,
+			opts:    UpsertOptions{ConflictColumns: []string{"email) DO NOTHING; --"}},
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "qualified conflict column",
+			record:  record,
+			opts:    UpsertOptions{ConflictColumns: []string{"users.email"}},
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "qualified update column",
+			record:  record,
+			opts:    UpsertOptions{ConflictColumns: []string{"email"}, Policy: UpsertUpdateColumns, UpdateColumns: []string{"users.name"}},
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "empty update column",
+			record:  record,
+			opts:    UpsertOptions{ConflictColumns: []string{"email"}, Policy: UpsertUpdateColumns, UpdateColumns: []string{""}},
+			wantErr: ErrInvalidAlias,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.dialect
+			if d == nil {
+				d = defaultDialect
+			}
+			sql, values, err := tt.record.ToUpsertSQLParameterizedDialect(d, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Expected %q, got %q", tt.wantSQL, sql)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Expected %v, got %v", tt.wantValues, values)
+			}
+		})
+	}
+}
+
+// TestToUpsertSQLBatches tests that every batch carries the ON CONFLICT clause and its own filter values
+func TestToUpsertSQLBatches(t *testing.T) {
+	saved := MAX_MULTIPLE_INSERTS
+	MAX_MULTIPLE_INSERTS = 2
+	defer func() { MAX_MULTIPLE_INSERTS = saved }()
+
+	var records DBRecords
+	for i := 1; i <= 3; i++ {
+		records = append(records, DBRecord{TableName: "users", Data: map[string]interface{}{"id": i, "name": "u"}})
+	}
+	opts := UpsertOptions{ConflictColumns: []string{"id"}, Where: &Condition{Field: "locked", Operator: "=", Value: false}}
+
+	statements, err := records.ToUpsertSQLParameterizedDialect(PostgreSQLDialect{}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []ParametereizedSQL{
+		{
+			Query:  "INSERT INTO users (id, name) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE users.locked = $5",
+			Values: []interface{}{1, "u", 2, "u", false},
+		},
+		{
+			Query:  "INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE users.locked = $3",
+			Values: []interface{}{3, "u", false},
+		},
+	}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("Expected %+v, got %+v", want, statements)
+	}
+	if opts.Where.Field != "locked" {
+		t.Errorf("Expected the caller's condition to be left untouched, got %q", opts.Where.Field)
+	}
+
+	statements, err = DBRecords{}.ToUpsertSQLParameterized(opts)
+	if err != nil || statements != nil {
+		t.Errorf("Expected no statements for no records, got %v, %v", statements, err)
+	}
+
+	records[2].TableName = "accounts"
+	if _, err := records.ToUpsertSQLParameterized(opts); err == nil {
+		t.Errorf("Expected an error for records of different tables")
+	}
+}
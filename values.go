@@ -0,0 +1,132 @@
+package orm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrColumnNotFound medaerror.MedaError = medaerror.MedaError{Message: "column not found in record"}
+	ErrNullValue      medaerror.MedaError = medaerror.MedaError{Message: "column value is NULL"}
+	ErrTypeConversion medaerror.MedaError = medaerror.MedaError{Message: "cannot convert column value to the requested type"}
+)
+
+// Typed accessors. They use the same conversions as ScanRecord, so rqlite's float64
+// numbers, SQLite 0/1 booleans and timestamp strings come back as the expected Go type.
+// A missing column returns ErrColumnNotFound and a NULL value ErrNullValue (use IsNull
+// to tell NULL apart), any other failure wraps ErrTypeConversion.
+//
+//	id, err := record.Int64("id")
+//	createdAt, err := record.Time("created_at")
+//	if !record.IsNull("deleted_at") { ... }
+
+// Field returns the value of column with its type
+func (d *DBRecord) Field(column string) DataStruct {
+	value := d.Data[column]
+	return DataStruct{
+		TypeDef: d.Types[column],
+		Value:   value,
+		Empty:   value == nil,
+	}
+}
+
+// IsNull reports whether column is NULL or missing from the record
+func (d *DBRecord) IsNull(column string) bool {
+	return d.Data[column] == nil
+}
+
+// Int64 returns column as an integer
+func (d *DBRecord) Int64(column string) (int64, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return 0, err
+	}
+	i, err := toInt64(value)
+	if err != nil {
+		return 0, conversionError(column, err)
+	}
+	return i, nil
+}
+
+// Float64 returns column as a float
+func (d *DBRecord) Float64(column string) (float64, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return 0, err
+	}
+	f, err := toFloat64(value)
+	if err != nil {
+		return 0, conversionError(column, err)
+	}
+	return f, nil
+}
+
+// String returns column as a string, numbers and times are formatted
+func (d *DBRecord) String(column string) (string, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return "", err
+	}
+	return toString(value), nil
+}
+
+// Bool returns column as a bool, accepting SQLite's 0/1 and strings like "true"
+func (d *DBRecord) Bool(column string) (bool, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return false, err
+	}
+	b, err := toBool(value)
+	if err != nil {
+		return false, conversionError(column, err)
+	}
+	return b, nil
+}
+
+// Time returns column as a time.Time, parsing the timestamp formats of SQLite and
+// PostgreSQL; numbers are read as unix seconds
+func (d *DBRecord) Time(column string) (time.Time, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := toTime(value)
+	if err != nil {
+		return time.Time{}, conversionError(column, err)
+	}
+	return t, nil
+}
+
+// Bytes returns column as a byte slice, from a []byte or a string value
+func (d *DBRecord) Bytes(column string) ([]byte, error) {
+	value, err := d.value(column)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, conversionError(column, fmt.Errorf("cannot convert %T to []byte", value))
+}
+
+// value returns the non-NULL value of column
+func (d *DBRecord) value(column string) (interface{}, error) {
+	value, ok := d.Data[column]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, column)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNullValue, column)
+	}
+	return value, nil
+}
+
+// conversionError wraps a conversion failure of column
+func conversionError(column string, err error) error {
+	return fmt.Errorf("%w: column %s: %w", ErrTypeConversion, column, err)
+}
@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDBRecordAccessors checks the typed accessors on values as returned by both backends
+func TestDBRecordAccessors(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	record := DBRecord{
+		TableName: "users",
+		Data: map[string]interface{}{
+			"id":         float64(42), // rqlite JSON number
+			"big":        json.Number("9007199254740993"),
+			"price":      "19.90",
+			"active":     int64(1), // SQLite boolean
+			"name":       "alice",
+			"created_at": "2024-05-01 10:30:00",
+			"updated_at": created,
+			"avatar":     []byte{0x89, 0x50},
+			"deleted_at": nil,
+		},
+		Types: map[string]string{"id": "integer", "name": "text"},
+	}
+
+	if id, err := record.Int64("id"); err != nil || id != 42 {
+		t.Errorf("Expected 42, got %d (%v)", id, err)
+	}
+	if big, err := record.Int64("big"); err != nil || big != 9007199254740993 {
+		t.Errorf("Expected 9007199254740993, got %d (%v)", big, err)
+	}
+	if price, err := record.Float64("price"); err != nil || price != 19.9 {
+		t.Errorf("Expected 19.9, got %v (%v)", price, err)
+	}
+	if active, err := record.Bool("active"); err != nil || !active {
+		t.Errorf("Expected true, got %v (%v)", active, err)
+	}
+	if id, err := record.String("id"); err != nil || id != "42" {
+		t.Errorf("Expected %q, got %q (%v)", "42", id, err)
+	}
+	for _, column := range []string{"created_at", "updated_at"} {
+		if got, err := record.Time(column); err != nil || !got.Equal(created) {
+			t.Errorf("Expected %v for %s, got %v (%v)", created, column, got, err)
+		}
+	}
+	if avatar, err := record.Bytes("avatar"); err != nil || string(avatar) != "\x89P" {
+		t.Errorf("Expected avatar bytes, got %v (%v)", avatar, err)
+	}
+	if name, err := record.Bytes("name"); err != nil || string(name) != "alice" {
+		t.Errorf("Expected %q, got %q (%v)", "alice", name, err)
+	}
+
+	field := record.Field("id")
+	if field.TypeDef != "integer" || field.Value != float64(42) || field.Empty {
+		t.Errorf("Unexpected field: %+v", field)
+	}
+	if field := record.Field("deleted_at"); !field.Empty || field.TypeDef != "" {
+		t.Errorf("Expected an empty field, got %+v", field)
+	}
+	if !record.IsNull("deleted_at") || !record.IsNull("missing") || record.IsNull("id") {
+		t.Error("Unexpected IsNull result")
+	}
+}
+
+// TestDBRecordAccessorErrors checks missing, NULL and unconvertible values
+func TestDBRecordAccessorErrors(t *testing.T) {
+	record := DBRecord{Data: map[string]interface{}{
+		"name":       "alice",
+		"ratio":      1.5,
+		"deleted_at": nil,
+	}}
+
+	tests := []struct {
+		name    string
+		get     func() error
+		wantErr error
+	}{
+		{"missing column", func() error { _, err := record.Int64("missing"); return err }, ErrColumnNotFound},
+		{"NULL string", func() error { _, err := record.String("deleted_at"); return err }, ErrNullValue},
+		{"NULL time", func() error { _, err := record.Time("deleted_at"); return err }, ErrNullValue},
+		{"text as integer", func() error { _, err := record.Int64("name"); return err }, ErrTypeConversion},
+		{"fraction as integer", func() error { _, err := record.Int64("ratio"); return err }, ErrTypeConversion},
+		{"text as bool", func() error { _, err := record.Bool("name"); return err }, ErrTypeConversion},
+		{"text as time", func() error { _, err := record.Time("name"); return err }, ErrTypeConversion},
+		{"number as bytes", func() error { _, err := record.Bytes("ratio"); return err }, ErrTypeConversion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.get(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
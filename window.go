@@ -0,0 +1,301 @@
+package orm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/medatechnology/goutil/medaerror"
+)
+
+var (
+	ErrInvalidWindowFunction medaerror.MedaError = medaerror.MedaError{Message: "invalid window function: unknown function or wrong column/argument count"}
+	ErrInvalidWindowSpec     medaerror.MedaError = medaerror.MedaError{Message: "invalid window specification: use either Over or Window, and no PartitionBy when extending a named window"}
+	ErrInvalidWindowFrame    medaerror.MedaError = medaerror.MedaError{Message: "invalid window frame: mode must be ROWS, RANGE or GROUPS with valid start and end bounds"}
+	ErrUnknownWindow         medaerror.MedaError = medaerror.MedaError{Message: "unknown window: name must be defined earlier in ComplexQuery.Windows"}
+	ErrDuplicateWindow       medaerror.MedaError = medaerror.MedaError{Message: "duplicate window name in ComplexQuery.Windows"}
+)
+
+// windowFunction describes the arguments a window function accepts
+type windowFunction struct {
+	column  int // 0: no column, 1: column required
+	minArgs int // bound arguments after the column
+	maxArgs int
+}
+
+// allowedWindowFunctions are the window functions available in both SQLite (3.28+) and PostgreSQL,
+// plus the aggregates that can be used with OVER
+var allowedWindowFunctions = map[string]windowFunction{
+	"ROW_NUMBER":   {},
+	"RANK":         {},
+	"DENSE_RANK":   {},
+	"PERCENT_RANK": {},
+	"CUME_DIST":    {},
+	"NTILE":        {minArgs: 1, maxArgs: 1},
+	"LAG":          {column: 1, maxArgs: 2},
+	"LEAD":         {column: 1, maxArgs: 2},
+	"FIRST_VALUE":  {column: 1},
+	"LAST_VALUE":   {column: 1},
+	"NTH_VALUE":    {column: 1, minArgs: 1, maxArgs: 1},
+	"COUNT":        {column: 1},
+	"SUM":          {column: 1},
+	"AVG":          {column: 1},
+	"MIN":          {column: 1},
+	"MAX":          {column: 1},
+}
+
+// FrameMode is the unit of a window frame
+type FrameMode string
+
+const (
+	FrameRows   FrameMode = "ROWS"
+	FrameRange  FrameMode = "RANGE"
+	FrameGroups FrameMode = "GROUPS"
+)
+
+// FrameBoundType is the kind of a window frame bound
+type FrameBoundType string
+
+const (
+	UnboundedPreceding FrameBoundType = "UNBOUNDED PRECEDING"
+	Preceding          FrameBoundType = "PRECEDING" // Offset PRECEDING
+	CurrentRow         FrameBoundType = "CURRENT ROW"
+	Following          FrameBoundType = "FOLLOWING" // Offset FOLLOWING
+	UnboundedFollowing FrameBoundType = "UNBOUNDED FOLLOWING"
+)
+
+// FrameBound is the start or end of a window frame
+type FrameBound struct {
+	Type   FrameBoundType `json:"type"`
+	Offset int            `json:"offset,omitempty"` // Only for Preceding and Following
+}
+
+// WindowFrame restricts the rows of the partition seen by the function.
+// Without End it renders the short form (ROWS UNBOUNDED PRECEDING).
+//
+//	orm.WindowFrame{Mode: orm.FrameRows, Start: orm.FrameBound{Type: orm.Preceding, Offset: 6}, End: &orm.FrameBound{Type: orm.CurrentRow}}
+//	// ROWS BETWEEN 6 PRECEDING AND CURRENT ROW
+type WindowFrame struct {
+	Mode  FrameMode   `json:"mode"`
+	Start FrameBound  `json:"start"`
+	End   *FrameBound `json:"end,omitempty"`
+}
+
+// WindowSpec is the content of an OVER (...) or WINDOW name AS (...) clause.
+// Base extends a named window: it may add ORDER BY and a frame, but not PARTITION BY.
+type WindowSpec struct {
+	Base        string       `json:"base,omitempty"`         // Named window to extend
+	PartitionBy []string     `json:"partition_by,omitempty"` // Validated columns
+	OrderBy     []OrderTerm  `json:"order_by,omitempty"`     // Validated ORDER BY entries
+	Frame       *WindowFrame `json:"frame,omitempty"`        // Optional frame
+}
+
+// NamedWindow is an entry of the WINDOW clause, referenced by WindowTerm.Window or WindowSpec.Base
+type NamedWindow struct {
+	Name string     `json:"name"`
+	Spec WindowSpec `json:"spec"`
+}
+
+// WindowTerm is a window function in the SELECT list. Function is checked against
+// a whitelist, Column like a condition field and Args are bound as parameters
+// (NTILE buckets, LAG/LEAD offset and default, NTH_VALUE position).
+// The window is either inline (Over), named (Window) or empty, which renders OVER ().
+//
+// Example usage:
+//
+//	orm.WindowTerm{
+//	    Function: "SUM",
+//	    Column:   "amount",
+//	    Over: &orm.WindowSpec{
+//	        PartitionBy: []string{"account_id"},
+//	        OrderBy:     []orm.OrderTerm{{Field: "created_at"}},
+//	        Frame:       &orm.WindowFrame{Mode: orm.FrameRows, Start: orm.FrameBound{Type: orm.UnboundedPreceding}},
+//	    },
+//	    Alias: "running_total",
+//	}
+//	// SUM(amount) OVER (PARTITION BY account_id ORDER BY created_at ASC ROWS UNBOUNDED PRECEDING) AS running_total
+type WindowTerm struct {
+	Function string        `json:"function"`         // ROW_NUMBER, RANK, LAG, SUM, ...
+	Column   string        `json:"column,omitempty"` // Column argument, * for COUNT(*)
+	Args     []interface{} `json:"args,omitempty"`   // Further arguments, bound as parameters
+	Over     *WindowSpec   `json:"over,omitempty"`   // Inline window
+	Window   string        `json:"window,omitempty"` // Named window from ComplexQuery.Windows
+	Alias    string        `json:"alias,omitempty"`  // Optional alias
+}
+
+// windowKeys validates the WINDOW clause names, each base must be defined before it is extended
+func windowKeys(windows []NamedWindow) (map[string]bool, error) {
+	keys := make(map[string]bool, len(windows))
+	for _, w := range windows {
+		if err := ValidateAlias(w.Name); err != nil {
+			return nil, err
+		}
+		if w.Spec.Base != "" && !keys[identifierKey(w.Spec.Base)] {
+			return nil, ErrUnknownWindow
+		}
+		key := identifierKey(w.Name)
+		if keys[key] {
+			return nil, ErrDuplicateWindow
+		}
+		keys[key] = true
+	}
+	return keys, nil
+}
+
+// writeWindows renders the WINDOW clause, empty when there are no named windows
+func (b *sqlBuilder) writeWindows(windows []NamedWindow, keys map[string]bool) (string, error) {
+	if len(windows) == 0 {
+		return "", nil
+	}
+	parts := make([]string, 0, len(windows))
+	for _, w := range windows {
+		spec, err := b.writeWindowSpec(w.Spec, keys)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, b.ident(w.Name)+" AS ("+spec+")")
+	}
+	return "WINDOW " + strings.Join(parts, ", "), nil
+}
+
+// writeWindowTerm renders FUNC(args) OVER (...) [AS alias]
+func (b *sqlBuilder) writeWindowTerm(term WindowTerm, keys map[string]bool) (string, error) {
+	function := strings.ToUpper(strings.TrimSpace(term.Function))
+	fn, ok := allowedWindowFunctions[function]
+	if !ok || len(term.Args) < fn.minArgs || len(term.Args) > fn.maxArgs {
+		return "", ErrInvalidWindowFunction
+	}
+	if (fn.column == 1) != (term.Column != "") || term.Column == "*" && function != "COUNT" {
+		return "", ErrInvalidWindowFunction
+	}
+
+	var args []string
+	switch {
+	case term.Column == "*":
+		args = append(args, "*")
+	case term.Column != "":
+		if err := b.checkField(term.Column); err != nil {
+			return "", err
+		}
+		args = append(args, b.column(term.Column))
+	}
+	for _, arg := range term.Args {
+		args = append(args, b.bind(arg))
+	}
+	sql := function + "(" + strings.Join(args, ", ") + ") OVER "
+
+	switch {
+	case term.Over != nil && term.Window != "":
+		return "", ErrInvalidWindowSpec
+	case term.Window != "":
+		if ValidateAlias(term.Window) != nil || !keys[identifierKey(term.Window)] {
+			return "", ErrUnknownWindow
+		}
+		sql += b.ident(term.Window)
+	case term.Over != nil:
+		spec, err := b.writeWindowSpec(*term.Over, keys)
+		if err != nil {
+			return "", err
+		}
+		sql += "(" + spec + ")"
+	default:
+		sql += "()"
+	}
+
+	if term.Alias != "" {
+		if err := ValidateAlias(term.Alias); err != nil {
+			return "", err
+		}
+		sql += " AS " + b.ident(term.Alias)
+	}
+	return sql, nil
+}
+
+// writeWindowSpec renders the content of a window definition
+func (b *sqlBuilder) writeWindowSpec(spec WindowSpec, keys map[string]bool) (string, error) {
+	var parts []string
+	if spec.Base != "" {
+		if len(spec.PartitionBy) > 0 {
+			return "", ErrInvalidWindowSpec
+		}
+		if ValidateAlias(spec.Base) != nil || !keys[identifierKey(spec.Base)] {
+			return "", ErrUnknownWindow
+		}
+		parts = append(parts, b.ident(spec.Base))
+	}
+	if len(spec.PartitionBy) > 0 {
+		columns, err := b.writeColumns(spec.PartitionBy)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "PARTITION BY "+strings.Join(columns, ", "))
+	}
+	if len(spec.OrderBy) > 0 {
+		terms, err := b.writeOrderTerms(spec.OrderBy)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(terms, ", "))
+	}
+	if spec.Frame != nil {
+		frame, err := spec.Frame.toSQL()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, frame)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// toSQL renders the frame clause. Bounds must not go backwards
+// (e.g. CURRENT ROW to UNBOUNDED PRECEDING), which both databases reject.
+func (f *WindowFrame) toSQL() (string, error) {
+	mode := FrameMode(strings.ToUpper(string(f.Mode)))
+	switch mode {
+	case FrameRows, FrameRange, FrameGroups:
+	default:
+		return "", ErrInvalidWindowFrame
+	}
+
+	start, startRank, err := f.Start.toSQL()
+	if err != nil {
+		return "", err
+	}
+	if f.End == nil {
+		// the short form ends at CURRENT ROW, so it cannot start after it
+		if startRank > 2 {
+			return "", ErrInvalidWindowFrame
+		}
+		return string(mode) + " " + start, nil
+	}
+	end, endRank, err := f.End.toSQL()
+	if err != nil {
+		return "", err
+	}
+	if startRank == 4 || endRank == 0 || endRank < startRank {
+		return "", ErrInvalidWindowFrame
+	}
+	return string(mode) + " BETWEEN " + start + " AND " + end, nil
+}
+
+// toSQL renders the bound and its rank from UNBOUNDED PRECEDING (0) to UNBOUNDED FOLLOWING (4)
+func (fb FrameBound) toSQL() (string, int, error) {
+	switch FrameBoundType(strings.ToUpper(string(fb.Type))) {
+	case UnboundedPreceding:
+		return string(UnboundedPreceding), 0, nil
+	case Preceding:
+		if fb.Offset < 0 {
+			return "", 0, ErrInvalidWindowFrame
+		}
+		return strconv.Itoa(fb.Offset) + " " + string(Preceding), 1, nil
+	case CurrentRow:
+		return string(CurrentRow), 2, nil
+	case Following:
+		if fb.Offset < 0 {
+			return "", 0, ErrInvalidWindowFrame
+		}
+		return strconv.Itoa(fb.Offset) + " " + string(Following), 3, nil
+	case UnboundedFollowing:
+		return string(UnboundedFollowing), 4, nil
+	}
+	return "", 0, ErrInvalidWindowFrame
+}
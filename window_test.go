@@ -0,0 +1,231 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestWindowFunctions checks window functions, named windows and frames for both dialects
+func TestWindowFunctions(t *testing.T) {
+	query := &ComplexQuery{
+		Select: []string{"id"},
+		WindowTerms: []WindowTerm{
+			{Function: "row_number", Window: "by_account", Alias: "rn"},
+			{Function: "LAG", Column: "amount", Args: []interface{}{1, 0}, Window: "by_account", Alias: "previous_amount"},
+			{
+				Function: "SUM",
+				Column:   "amount",
+				Over: &WindowSpec{
+					Base: "by_account",
+					Frame: &WindowFrame{
+						Mode:  FrameRows,
+						Start: FrameBound{Type: Preceding, Offset: 6},
+						End:   &FrameBound{Type: CurrentRow},
+					},
+				},
+				Alias: "weekly_total",
+			},
+			{Function: "COUNT", Column: "*", Alias: "total_rows"},
+		},
+		From:  "payments",
+		Where: &Condition{Field: "status", Operator: "=", Value: "paid"},
+		Windows: []NamedWindow{{
+			Name: "by_account",
+			Spec: WindowSpec{
+				PartitionBy: []string{"payments.account_id"},
+				OrderBy:     []OrderTerm{{Field: "created_at"}},
+			},
+		}},
+		OrderTerms: []OrderTerm{{Field: "rn", Desc: true}},
+	}
+
+	tests := []struct {
+		dialect    Dialect
+		want       string
+		wantValues []interface{}
+	}{
+		{
+			dialect: SQLiteDialect{},
+			want: "SELECT id, ROW_NUMBER() OVER by_account AS rn, LAG(amount, ?, ?) OVER by_account AS previous_amount, " +
+				"SUM(amount) OVER (by_account ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS weekly_total, COUNT(*) OVER () AS total_rows " +
+				"FROM payments WHERE status = ? WINDOW by_account AS (PARTITION BY payments.account_id ORDER BY created_at ASC) ORDER BY rn DESC",
+			wantValues: []interface{}{1, 0, "paid"},
+		},
+		{
+			dialect: PostgreSQLDialect{},
+			want: "SELECT id, ROW_NUMBER() OVER by_account AS rn, LAG(amount, $1, $2) OVER by_account AS previous_amount, " +
+				"SUM(amount) OVER (by_account ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS weekly_total, COUNT(*) OVER () AS total_rows " +
+				"FROM payments WHERE status = $3 WINDOW by_account AS (PARTITION BY payments.account_id ORDER BY created_at ASC) ORDER BY rn DESC",
+			wantValues: []interface{}{1, 0, "paid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			got, values, err := query.ToSQLDialect(tt.dialect)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Expected values %v, got %v", tt.wantValues, values)
+			}
+		})
+	}
+}
+
+// TestWindowOverAggregate checks ranking over an aggregate in a grouped query
+func TestWindowOverAggregate(t *testing.T) {
+	query := &ComplexQuery{
+		Select:  []string{"users.id"},
+		From:    "users",
+		Joins:   []Join{{Type: LeftJoin, Table: "orders", Condition: "users.id = orders.user_id"}},
+		GroupBy: []string{"users.id"},
+		WindowTerms: []WindowTerm{{
+			Function: "RANK",
+			Over: &WindowSpec{
+				OrderBy: []OrderTerm{{Field: "SUM(orders.total)", Desc: true, Nulls: NullsLast}},
+				Frame:   &WindowFrame{Mode: FrameRange, Start: FrameBound{Type: UnboundedPreceding}},
+			},
+			Alias: "revenue_rank",
+		}},
+	}
+
+	want := "SELECT users.id, RANK() OVER (ORDER BY SUM(orders.total) DESC NULLS LAST RANGE UNBOUNDED PRECEDING) AS revenue_rank " +
+		"FROM users LEFT JOIN orders ON users.id = orders.user_id GROUP BY users.id"
+
+	got, _, err := query.ToSQL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+// TestWindowErrors checks invalid window functions and specifications are rejected
+func TestWindowErrors(t *testing.T) {
+	frame := func(start FrameBound, end *FrameBound) []WindowTerm {
+		return []WindowTerm{{Function: "SUM", Column: "amount", Over: &WindowSpec{Frame: &WindowFrame{Mode: FrameRows, Start: start, End: end}}}}
+	}
+
+	tests := []struct {
+		name    string
+		query   ComplexQuery
+		wantErr error
+	}{
+		{
+			name:    "unknown function",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "pg_sleep", Args: []interface{}{10}}}},
+			wantErr: ErrInvalidWindowFunction,
+		},
+		{
+			name:    "missing column",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "SUM"}}},
+			wantErr: ErrInvalidWindowFunction,
+		},
+		{
+			name:    "unexpected column",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "ROW_NUMBER", Column: "id"}}},
+			wantErr: ErrInvalidWindowFunction,
+		},
+		{
+			name:    "too many arguments",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "LAG", Column: "id", Args: []interface{}{1, 2, 3}}}},
+			wantErr: ErrInvalidWindowFunction,
+		},
+		{
+			name:    "star outside COUNT",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "SUM", Column: "*"}}},
+			wantErr: ErrInvalidWindowFunction,
+		},
+		{
+			name:    "column injection",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "SUM", Column: "amount) FROM secrets --"}}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name: "partition injection",
+			query: ComplexQuery{From: "payments", WindowTerms: []WindowTerm{
+				{Function: "RANK", Over: &WindowSpec{PartitionBy: []string{"account_id; DROP TABLE payments"}}},
+			}},
+			wantErr: ErrInvalidFieldName,
+		},
+		{
+			name: "partition unknown qualifier",
+			query: ComplexQuery{From: "payments", WindowTerms: []WindowTerm{
+				{Function: "RANK", Over: &WindowSpec{PartitionBy: []string{"users.id"}}},
+			}},
+			wantErr: ErrUnknownQualifier,
+		},
+		{
+			name:    "unknown named window",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "RANK", Window: "w"}}},
+			wantErr: ErrUnknownWindow,
+		},
+		{
+			name: "both inline and named window",
+			query: ComplexQuery{
+				From:        "payments",
+				Windows:     []NamedWindow{{Name: "w"}},
+				WindowTerms: []WindowTerm{{Function: "RANK", Window: "w", Over: &WindowSpec{}}},
+			},
+			wantErr: ErrInvalidWindowSpec,
+		},
+		{
+			name: "partition when extending a window",
+			query: ComplexQuery{
+				From:        "payments",
+				Windows:     []NamedWindow{{Name: "w"}},
+				WindowTerms: []WindowTerm{{Function: "RANK", Over: &WindowSpec{Base: "w", PartitionBy: []string{"id"}}}},
+			},
+			wantErr: ErrInvalidWindowSpec,
+		},
+		{
+			name:    "base defined later",
+			query:   ComplexQuery{From: "payments", Windows: []NamedWindow{{Name: "a", Spec: WindowSpec{Base: "b"}}, {Name: "b"}}},
+			wantErr: ErrUnknownWindow,
+		},
+		{
+			name:    "duplicate window",
+			query:   ComplexQuery{From: "payments", Windows: []NamedWindow{{Name: "w"}, {Name: "W"}}},
+			wantErr: ErrDuplicateWindow,
+		},
+		{
+			name:    "invalid window name",
+			query:   ComplexQuery{From: "payments", Windows: []NamedWindow{{Name: "w AS (); --"}}},
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "invalid frame mode",
+			query:   ComplexQuery{From: "payments", WindowTerms: []WindowTerm{{Function: "RANK", Over: &WindowSpec{Frame: &WindowFrame{Mode: "ROWS 1; --"}}}}},
+			wantErr: ErrInvalidWindowFrame,
+		},
+		{
+			name:    "negative offset",
+			query:   ComplexQuery{From: "payments", WindowTerms: frame(FrameBound{Type: Preceding, Offset: -1}, nil)},
+			wantErr: ErrInvalidWindowFrame,
+		},
+		{
+			name:    "frame going backwards",
+			query:   ComplexQuery{From: "payments", WindowTerms: frame(FrameBound{Type: CurrentRow}, &FrameBound{Type: Preceding, Offset: 1})},
+			wantErr: ErrInvalidWindowFrame,
+		},
+		{
+			name:    "short frame after current row",
+			query:   ComplexQuery{From: "payments", WindowTerms: frame(FrameBound{Type: Following, Offset: 1}, nil)},
+			wantErr: ErrInvalidWindowFrame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.query.ToSQL(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}